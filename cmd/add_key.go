@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,90 +11,206 @@ import (
 
 // addKeyCmd represents the add-key command
 var addKeyCmd = &cobra.Command{
-	Use:   "add-key [name] url",
+	Use:   "add-key [name] url | name --recv keyid",
 	Short: "Add a repository key to the system",
-	Long: `Add a repository key to the system package manager.
+	Long: `Add a repository key to the system package manager. The key ID, fingerprint,
+user ID and expiry of the downloaded key are shown first, and the key is only
+installed once confirmed (or with -y), so compare them with the ones the vendor
+publishes.
+
+With --recv the key is fetched by its fingerprint or key ID from a keyserver
+over HKPS instead of from a URL, keyserver.ubuntu.com unless --keyserver is
+given. The keyserver must return exactly the requested key.
+
+Scripts can pin the key they expect with --sha256, the digest of the
+downloaded file, or --fingerprint, the fingerprint every key in the file must
+have. A key that does not match is refused; one that matches is installed
+without asking.
 
 For apt-based systems (Debian/Ubuntu):
   pkgs add-key name url
   Saves the key to /etc/apt/keyrings/name.asc
 
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  pkgs add-key name url
+  Saves the key to /etc/pki/rpm-gpg/RPM-GPG-KEY-name and imports it with
+  'rpm --import'. Repositories can refer to it with
+  gpgkey=file:///etc/pki/rpm-gpg/RPM-GPG-KEY-name.
+
 For Alpine Linux:
   pkgs add-key [name] url
   Adds the key to /etc/apk/keys/
-  If name is not provided, uses the name from Content-Disposition header.`,
+  If name is not provided, uses the name from Content-Disposition header.
+
+For Arch Linux:
+  pkgs add-key name url
+  Adds the key to the pacman keyring with 'pacman-key --add' and signs it
+  locally with 'pacman-key --lsign-key' so that packages signed with it are
+  trusted`,
 	Example: `  # Add a key for apt-based systems
   pkgs add-key nodesource https://deb.nodesource.com/gpgkey/nodesource.gpg.key
+  pkgs add-key nodesource https://deb.nodesource.com/gpgkey/nodesource.gpg.key --fingerprint 6F71F525282841EEDAF851B42F59B5F99B1BE0B4
+
+  # Fetch a key from a keyserver
+  pkgs add-key nodesource --recv 6F71F525282841EEDAF851B42F59B5F99B1BE0B4
+  pkgs add-key example --keyserver keys.openpgp.org --recv 0xDEADBEEFDEADBEEF
+
+  # Add a key for dnf/yum-based systems
+  pkgs add-key docker-ce https://download.docker.com/linux/fedora/gpg
 
   # Add a key for Alpine Linux
   pkgs add-key alpine-key https://alpine-keys.example.com/key.rsa.pub
-  pkgs add-key https://alpine-keys.example.com/key.rsa.pub`,
-	Run: func(cmd *cobra.Command, args []string) {
+  pkgs add-key https://alpine-keys.example.com/key.rsa.pub
+
+  # Add a key for Arch Linux
+  pkgs add-key chaotic-aur 'https://keyserver.ubuntu.com/pks/lookup?op=get&search=0x3056513887B78AEB'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
+		var pin keyPin
+		pin.SHA256, _ = cmd.Flags().GetString("sha256")
+		pin.Fingerprint, _ = cmd.Flags().GetString("fingerprint")
+
 		// Check arguments
-		if len(args) != 2 {
-			fmt.Println("Error: Repository name and URL are required.")
-			fmt.Println("Usage: pkgs add-key name url")
-			return
+		var name, url string
+		if recv, _ := cmd.Flags().GetString("recv"); recv != "" {
+			if len(args) != 1 {
+				return fmt.Errorf("a name for the key is required (usage: pkgs add-key name --recv keyid)")
+			}
+			if pm.Type == "alpine" {
+				return unsupportedError("apk keys are not OpenPGP keys and cannot be fetched from a keyserver")
+			}
+			server, _ := cmd.Flags().GetString("keyserver")
+			var err error
+			if url, err = keyserverURL(server, recv); err != nil {
+				return err
+			}
+			pin.KeyID = normalizeFingerprint(recv)
+			// A full fingerprint pins the key as --fingerprint does
+			if len(pin.KeyID) == 40 && pin.Fingerprint == "" {
+				pin.Fingerprint = pin.KeyID
+			}
+			name = args[0]
+		} else {
+			if len(args) != 2 {
+				return fmt.Errorf("repository name and URL are required (usage: pkgs add-key name url)")
+			}
+			name, url = args[0], args[1]
 		}
-		name := args[0]
-		url := args[1]
 
 		// Add key based on package manager
 		switch pm.Type {
 		case "debian":
-			if err := addKeyApt(name, url); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+			return addKeyApt(name, url, pin)
 		case "redhat":
-			fmt.Println("For dnf/yum-based systems, keys are typically added with the repository.")
-			fmt.Println("Use 'pkgs add-repo' with the appropriate GPG key URL.")
+			return addKeyRpm(name, url, pin)
 		case "alpine":
-			if err := addKeyAlpine(name, url); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+			return addKeyAlpine(name, url, pin)
 		case "arch":
-			fmt.Println("For Arch Linux, keys are typically added with 'pacman-key --recv-keys' and 'pacman-key --lsign-key'.")
-			fmt.Println("Please refer to the Arch Linux documentation for adding keys.")
+			return addKeyPacman(url, pin)
 		case "macos":
 			fmt.Println("For Homebrew, keys are managed automatically when adding taps.")
 			fmt.Println("Use 'brew tap' to add a repository.")
-		default:
-			fmt.Println("Adding keys is not supported for this package manager.")
 		}
+
+		// The remaining backends only print guidance for doing this manually
+		return unsupportedError("adding keys is not supported for package manager '%s'", pm.Name)
 	},
 }
 
 // addKeyApt adds a repository key for apt-based systems
-func addKeyApt(name, url string) error {
+func addKeyApt(name, url string, pin keyPin) error {
 	// Create keyrings directory if it doesn't exist
 	keyringDir := "/etc/apt/keyrings"
-	if err := os.MkdirAll(keyringDir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %v", keyringDir, err)
+	if err := ensureDirExists(keyringDir); err != nil {
+		return err
 	}
 
-	// Download the key
-	keyPath := filepath.Join(keyringDir, name+".asc")
-	if err := downloadFile(url, keyPath); err != nil {
-		return fmt.Errorf("failed to download key: %v", err)
+	data, _, err := trustKey(url, parseOpenPGPKeys, pin)
+	if err != nil {
+		return err
+	}
+	keyPath := aptKeyPath(name)
+	if err := writeFileContent(keyPath, string(data), 0644); err != nil {
+		return err
 	}
 
 	fmt.Printf("Successfully added key to %s\n", keyPath)
 	return nil
 }
 
+// aptKeyPath returns where addKeyApt saves the key called name
+func aptKeyPath(name string) string {
+	return filepath.Join("/etc/apt/keyrings", name+".asc")
+}
+
+// addKeyRpm saves a repository key to /etc/pki/rpm-gpg and imports it into
+// the rpm database
+func addKeyRpm(name, url string, pin keyPin) error {
+	data, _, err := trustKey(url, parseOpenPGPKeys, pin)
+	if err != nil {
+		return err
+	}
+	keyPath := rpmKeyPath(name)
+	if err := ensureDirExists(filepath.Dir(keyPath)); err != nil {
+		return err
+	}
+	if err := writeFileContent(keyPath, string(data), 0644); err != nil {
+		return err
+	}
+
+	if err := executeNative("rpm", "--import", keyPath); err != nil {
+		return fmt.Errorf("failed to import key %s: %v", keyPath, err)
+	}
+	fmt.Printf("Successfully imported key %s\n", keyPath)
+	fmt.Printf("Repositories can use it with gpgkey=file://%s\n", keyPath)
+	return nil
+}
+
+// rpmKeyPath returns where addKeyRpm saves the key called name
+func rpmKeyPath(name string) string {
+	return filepath.Join("/etc/pki/rpm-gpg", "RPM-GPG-KEY-"+name)
+}
+
+// addKeyPacman adds a key to the pacman keyring and signs it locally, once
+// the user has confirmed it
+func addKeyPacman(url string, pin keyPin) error {
+	data, keys, err := trustKey(url, parseOpenPGPKeys, pin)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp("", "pkgs-key-*.asc")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Close()
+	if err := writeFileContent(tmp.Name(), string(data), 0600); err != nil {
+		return err
+	}
+
+	if err := executeNative("pacman-key", "--add", tmp.Name()); err != nil {
+		return fmt.Errorf("failed to add key: %v", err)
+	}
+	for _, k := range keys {
+		if err := executeNative("pacman-key", "--lsign-key", k.Fingerprint); err != nil {
+			return fmt.Errorf("failed to sign key %s: %v", k.Fingerprint, err)
+		}
+	}
+	fmt.Printf("Successfully added %d key(s) to the pacman keyring\n", len(keys))
+	return nil
+}
+
 // addKeyAlpine adds a repository key for Alpine Linux
-func addKeyAlpine(name, url string) error {
+func addKeyAlpine(name, url string, pin keyPin) error {
 	// Download the key
 	keyPath := "/etc/apk/keys/"
 	if name == "" {
 		// Try to get the filename from the URL or Content-Disposition header
-		resp, err := http.Head(url)
+		resp, err := httpClient.Head(url)
 		if err != nil {
 			return fmt.Errorf("failed to get key information: %v", err)
 		}
@@ -117,9 +232,13 @@ func addKeyAlpine(name, url string) error {
 		}
 	}
 
+	data, _, err := trustKey(url, parseRSAPublicKey, pin)
+	if err != nil {
+		return err
+	}
 	keyPath = filepath.Join(keyPath, name)
-	if err := downloadFile(url, keyPath); err != nil {
-		return fmt.Errorf("failed to download key: %v", err)
+	if err := writeFileContent(keyPath, string(data), 0644); err != nil {
+		return err
 	}
 
 	fmt.Printf("Successfully added key to %s\n", keyPath)
@@ -128,4 +247,9 @@ func addKeyAlpine(name, url string) error {
 
 func init() {
 	rootCmd.AddCommand(addKeyCmd)
+
+	addKeyCmd.Flags().String("sha256", "", "Only install the key if the downloaded file has this SHA-256 digest")
+	addKeyCmd.Flags().String("fingerprint", "", "Only install the key if it has this fingerprint")
+	addKeyCmd.Flags().String("recv", "", "Fetch the key with this fingerprint or key ID from a keyserver")
+	addKeyCmd.Flags().String("keyserver", "keyserver.ubuntu.com", "Keyserver to fetch keys from with --recv")
 }
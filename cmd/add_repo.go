@@ -16,39 +16,133 @@ var addRepoCmd = &cobra.Command{
 	Long: `Add a repository to the system package manager.
 
 For apt-based systems (Debian/Ubuntu):
-  pkgs add-repo name url
-  Creates a file in /etc/apt/sources.list.d/name.list
+  pkgs add-repo name "deb [options] uri suite [components]"
+  Creates /etc/apt/sources.list.d/name.sources in the deb822 format on
+  Debian 12, Ubuntu 24.04 and later, and the one-line name.list otherwise.
+  Use --format to choose the format explicitly.
+
+  pkgs add-repo ppa:user/name
+  Adds an Ubuntu PPA: the signing key is looked up on Launchpad and saved to
+  /etc/apt/keyrings/, add-apt-repository is not needed
 
 For dnf/yum-based systems (Fedora/RHEL/CentOS):
   pkgs add-repo [name] url
   Creates a file in /etc/yum.repos.d/ directory
   If URL ends with .repo, name is optional and the filename will be used.
 
+  pkgs add-repo copr:owner/project
+  Enables a Fedora COPR project with 'dnf copr enable', or by downloading its
+  .repo file if the dnf copr plugin is not installed
+
 For Alpine Linux:
   pkgs add-repo name url
-  Adds the repository to /etc/apk/repositories`,
+  Adds the repository to /etc/apk/repositories
+
+For Arch Linux:
+  pkgs add-repo name url
+  Adds a [name] section with 'Server = url' to /etc/pacman.conf, or with
+  'Include = path' if a mirror list file is given instead of a URL
+
+With --key the repository's signing key is downloaded as well, so no separate
+'pkgs add-key' is needed: on apt systems it is saved to
+/etc/apt/keyrings/name.asc and referenced with signed-by, on dnf/yum systems it
+is saved to /etc/pki/rpm-gpg/, imported with 'rpm --import' and set as gpgkey
+of the repository, and on Alpine it is added to /etc/apk/keys/. As with
+add-key, the key's fingerprint is shown and must be confirmed. With --update
+the package lists are refreshed afterwards.
+
+Repositories without a key are refused: apt entries need --key or a
+signed-by option and must not set trusted=yes, dnf/yum repositories given by
+URL need --key, and downloaded .repo files must not set gpgcheck=0. Use
+--insecure to add such a repository anyway.
+
+With --preset a well-known repository is added without looking up its URL
+and key: the right ones for the distribution, release and architecture are
+filled in, and the key is added as with --key. Available presets:
+
+` + presetHelp(),
 	Example: `  # Add a repository for apt-based systems
   pkgs add-repo nodesource "deb [signed-by=/etc/apt/keyrings/nodesource.asc] https://deb.nodesource.com/node_20.x nodistro main"
 
+  # Add a PPA on Ubuntu
+  pkgs add-repo ppa:deadsnakes/ppa --update
+
+  # Write a one-line .list file instead of a deb822 .sources file
+  pkgs add-repo --format list nodesource "deb [signed-by=/etc/apt/keyrings/nodesource.asc] https://deb.nodesource.com/node_20.x nodistro main"
+
+  # Add the key, the repository and refresh the package lists in one step
+  pkgs add-repo nodesource "deb https://deb.nodesource.com/node_20.x nodistro main" --key https://deb.nodesource.com/gpgkey/nodesource.gpg.key --update
+
   # Add a repository for dnf/yum-based systems (using a .repo file)
   pkgs add-repo https://download.docker.com/linux/fedora/docker-ce.repo
   
   # Add a repository for dnf/yum-based systems (using a URL)
-  pkgs add-repo myrepo https://packages.example.com/rhel/8/x86_64/
+  pkgs add-repo myrepo https://packages.example.com/rhel/8/x86_64/ --key https://packages.example.com/RPM-GPG-KEY
+
+  # Add a COPR project for Fedora
+  pkgs add-repo copr:atim/starship
 
   # Add a repository for Alpine Linux
-  pkgs add-repo edge-testing https://dl-cdn.alpinelinux.org/alpine/edge/testing`,
-	Run: func(cmd *cobra.Command, args []string) {
+  pkgs add-repo edge-testing https://dl-cdn.alpinelinux.org/alpine/edge/testing
+
+  # Add a repository for Arch Linux
+  pkgs add-repo chaotic-aur /etc/pacman.d/chaotic-mirrorlist
+
+  # Add a well-known repository together with its key on any distribution
+  pkgs add-repo --preset docker --update
+  pkgs add-repo --preset nodesource@22`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
+		keyURL, _ := cmd.Flags().GetString("key")
+		update, _ := cmd.Flags().GetBool("update")
+
 		// Check arguments based on package manager type
 		var name, url string
 
-		if pm.Type == "redhat" && len(args) == 1 && strings.HasSuffix(args[0], ".repo") {
+		if preset, _ := cmd.Flags().GetString("preset"); preset != "" {
+			if len(args) > 1 {
+				return fmt.Errorf("invalid arguments (usage: pkgs add-repo --preset preset [name])")
+			}
+			presetName, presetURL, presetKey, err := resolvePreset(pm, preset)
+			if err != nil {
+				return err
+			}
+			name, url = presetName, presetURL
+			if len(args) == 1 {
+				name = args[0]
+			}
+			// A key given on the command line takes precedence
+			if keyURL == "" {
+				keyURL = presetKey
+			}
+		} else if user, ppa, ok := parsePPA(firstArg(args)); ok && len(args) == 1 {
+			if pm.Type != "debian" {
+				return unsupportedError("PPAs are only supported for apt-based systems, not for package manager '%s'", pm.Name)
+			}
+			if keyURL != "" {
+				return fmt.Errorf("the signing key of a PPA is looked up on Launchpad, --key cannot be used")
+			}
+			deb822, err := useDeb822(cmd)
+			if err != nil {
+				return err
+			}
+			if err := addPPA(user, ppa, deb822); err != nil || !update {
+				return err
+			}
+			return ExecuteCommand(pm, "update", nil)
+		} else if owner, project, ok := parseCopr(firstArg(args)); ok && len(args) == 1 {
+			if pm.Type != "redhat" {
+				return unsupportedError("COPR repositories are only supported for dnf/yum-based systems, not for package manager '%s'", pm.Name)
+			}
+			if err := addCopr(pm, owner, project); err != nil || !update {
+				return err
+			}
+			return ExecuteCommand(pm, "update", nil)
+		} else if pm.Type == "redhat" && len(args) == 1 && strings.HasSuffix(args[0], ".repo") {
 			// For Red Hat systems with a .repo URL, name is optional
 			url = args[0]
 			// Extract name from the URL (filename without .repo extension)
@@ -59,44 +153,131 @@ For Alpine Linux:
 			name = args[0]
 			url = args[1]
 		} else {
-			fmt.Println("Error: Invalid arguments.")
 			if pm.Type == "redhat" {
-				fmt.Println("Usage: pkgs add-repo [name] url")
-				fmt.Println("       For .repo files, name is optional.")
-			} else {
-				fmt.Println("Usage: pkgs add-repo name url")
+				return fmt.Errorf("invalid arguments (usage: pkgs add-repo [name] url; for .repo files, name is optional)")
+			}
+			return fmt.Errorf("invalid arguments (usage: pkgs add-repo name url)")
+		}
+
+		insecure, _ := cmd.Flags().GetBool("insecure")
+		if !insecure {
+			if err := requireSigned(pm, url, keyURL); err != nil {
+				return err
+			}
+		}
+
+		var deb822 bool
+		if pm.Type == "debian" {
+			var err error
+			if deb822, err = useDeb822(cmd); err != nil {
+				return err
+			}
+		}
+		if keyURL != "" {
+			switch pm.Type {
+			case "arch":
+				return fmt.Errorf("pacman keys are not tied to a repository, add them with 'pkgs add-key'")
+			case "macos":
+				return fmt.Errorf("Homebrew taps have no signing keys, --key cannot be used")
 			}
-			return
+		}
+		if err := confirmPlan(addRepoPlan(pm, name, url, keyURL, deb822, update)...); err != nil {
+			return err
 		}
 
 		// Add repository based on package manager
+		var err error
 		switch pm.Type {
 		case "debian":
-			if err := addRepoApt(name, url); err != nil {
-				fmt.Printf("Error: %v\n", err)
+			if keyURL != "" {
+				if url, err = withSignedBy(url, aptKeyPath(name)); err != nil {
+					return err
+				}
+				if err = addKeyApt(name, keyURL, keyPin{}); err != nil {
+					return err
+				}
 			}
+			err = addRepoApt(name, url, deb822)
 		case "redhat":
-			if err := addRepoDnfYum(name, url); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+			err = addRepoDnfYum(name, url, keyURL, insecure)
 		case "alpine":
-			if err := addRepoAlpine(name, url); err != nil {
-				fmt.Printf("Error: %v\n", err)
+			if keyURL != "" {
+				if err = addKeyAlpine("", keyURL, keyPin{}); err != nil {
+					return err
+				}
 			}
+			err = addRepoAlpine(name, url)
 		case "arch":
-			fmt.Println("For Arch Linux, you need to manually edit /etc/pacman.conf to add repositories.")
+			err = addRepoPacman(name, url)
 		case "macos":
-			if err := addRepoHomebrew(url); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+			err = addRepoHomebrew(url)
 		default:
-			fmt.Println("Adding repositories is not supported for this package manager.")
+			return unsupportedError("adding repositories is not supported for package manager '%s'", pm.Name)
+		}
+		if err != nil || !update {
+			return err
 		}
+
+		// New repositories are only used once the package lists are refreshed
+		return ExecuteCommand(pm, "update", nil)
 	},
 }
 
-// addRepoApt adds a repository for apt-based systems
-func addRepoApt(name, repoLine string) error {
+// addRepoPlan lists the changes add-repo makes for a repository
+func addRepoPlan(pm *PackageManager, name, url, keyURL string, deb822, update bool) []planStep {
+	var steps []planStep
+	if keyURL != "" {
+		steps = append(steps, planStep{"add key", keyURL})
+	}
+	config := getRepoConfig(pm.Type)
+	switch pm.Type {
+	case "debian":
+		path := filepath.Join(config.baseDir, name+config.fileExtension)
+		if deb822 {
+			path = filepath.Join(config.baseDir, name+".sources")
+		}
+		steps = append(steps, planStep{"write", path})
+	case "redhat":
+		steps = append(steps, planStep{"write", filepath.Join(config.baseDir, name+config.fileExtension)})
+	case "alpine":
+		steps = append(steps, planStep{"write", filepath.Join(config.baseDir, "repositories")})
+	case "arch":
+		steps = append(steps, planStep{"write", pacmanConf})
+	case "macos":
+		steps = append(steps, runStep("brew", "tap", url))
+	default:
+		return nil
+	}
+	if update {
+		steps = append(steps, nativeStep(pm, "update"))
+	}
+	return steps
+}
+
+// withSignedBy adds a signed-by option for keyPath to a one-line apt entry
+func withSignedBy(repoLine, keyPath string) (string, error) {
+	src, ok := parseAptLine(repoLine)
+	if !ok {
+		return "", fmt.Errorf("invalid repository line %q (expected e.g. \"deb https://example.com/apt stable main\")", repoLine)
+	}
+	if current, found := src.Options["signed-by"]; found {
+		if current == keyPath {
+			return repoLine, nil
+		}
+		return "", fmt.Errorf("the repository line is already signed by %s, remove signed-by or --key", current)
+	}
+
+	typ, rest, _ := strings.Cut(strings.TrimSpace(repoLine), " ")
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "[") {
+		return fmt.Sprintf("%s [signed-by=%s %s", typ, keyPath, strings.TrimPrefix(rest, "[")), nil
+	}
+	return fmt.Sprintf("%s [signed-by=%s] %s", typ, keyPath, rest), nil
+}
+
+// addRepoApt adds a repository for apt-based systems, written as a deb822
+// .sources file or as a one-line .list file
+func addRepoApt(name, repoLine string, deb822 bool) error {
 	config := getRepoConfig("debian")
 
 	// Create sources.list.d directory if it doesn't exist
@@ -104,34 +285,64 @@ func addRepoApt(name, repoLine string) error {
 		return err
 	}
 
-	// Check if the repository file already exists
 	repoPath := filepath.Join(config.baseDir, name+config.fileExtension)
-	if fileExists(repoPath) {
-		// File exists, check if it contains the same repository line
-		content, err := readFileContent(repoPath)
+	content := repoLine + "\n"
+	if deb822 {
+		src, ok := parseAptLine(repoLine)
+		if !ok {
+			return fmt.Errorf("invalid repository line %q (expected e.g. \"deb [signed-by=/etc/apt/keyrings/name.asc] https://example.com/apt stable main\")", repoLine)
+		}
+		repoPath = filepath.Join(config.baseDir, name+".sources")
+		content = formatDeb822(src)
+	}
+
+	// Check if the repository file already exists
+	for _, existing := range []string{filepath.Join(config.baseDir, name+".list"), filepath.Join(config.baseDir, name+".sources")} {
+		if !fileExists(existing) {
+			continue
+		}
+		// File exists, check if it contains the same repository
+		current, err := readFileContent(existing)
 		if err != nil {
 			return err
 		}
 
-		if strings.Contains(content, repoLine) {
-			fmt.Printf("Repository already exists in %s\n", repoPath)
+		if strings.Contains(current, repoLine) || current == content {
+			fmt.Printf("Repository already exists in %s\n", existing)
 			return nil
 		}
 
 		// Ask for confirmation before overwriting
-		if !askForConfirmation(fmt.Sprintf("Repository file %s already exists. Do you want to overwrite it?", repoPath)) {
-			return fmt.Errorf("operation cancelled by user")
+		if !askForConfirmation(fmt.Sprintf("Repository file %s already exists. Do you want to overwrite it?", existing)) {
+			return errCancelled
+		}
+		// The other format would define the repository twice
+		if existing != repoPath {
+			if err := removeFile(existing); err != nil {
+				return fmt.Errorf("failed to remove %s: %v", existing, err)
+			}
 		}
 	}
 
-	// Write the repository line to the file
-	return writeFileContent(repoPath, repoLine+"\n", 0644)
+	// Write the repository to the file
+	if err := writeFileContent(repoPath, content, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Repository added to %s\n", repoPath)
+	return nil
 }
 
-// addRepoDnfYum adds a repository for dnf/yum-based systems
-func addRepoDnfYum(name, url string) error {
+// addRepoDnfYum adds a repository for dnf/yum-based systems. If keyURL is
+// set, the key is imported and the repository is checked against it.
+func addRepoDnfYum(name, url, keyURL string, insecure bool) error {
 	config := getRepoConfig("redhat")
 
+	if keyURL != "" {
+		if err := addKeyRpm(name, keyURL, keyPin{}); err != nil {
+			return err
+		}
+	}
+
 	// Create yum.repos.d directory if it doesn't exist
 	if err := ensureDirExists(config.baseDir); err != nil {
 		return err
@@ -150,8 +361,7 @@ func addRepoDnfYum(name, url string) error {
 		defer os.Remove(tempFile.Name())
 		tempFile.Close()
 
-		// Use curl to download the file
-		if err := runCommand("curl", "-fsSL", "-o", tempFile.Name(), url); err != nil {
+		if err := downloadFile(url, tempFile.Name()); err != nil {
 			return fmt.Errorf("failed to download repository file: %v", err)
 		}
 
@@ -161,13 +371,19 @@ func addRepoDnfYum(name, url string) error {
 			return err
 		}
 
+		if !insecure {
+			if section := unsignedRepoSection(repoContent); section != "" {
+				return fmt.Errorf("repository %s in %s sets gpgcheck=0, use --insecure to add it anyway", section, url)
+			}
+		}
+
 		// Destination path
 		destPath := filepath.Join(config.baseDir, name+config.fileExtension)
 
 		// Check if file already exists
 		if fileExists(destPath) {
 			if !askForConfirmation(fmt.Sprintf("Repository file %s already exists. Do you want to overwrite it?", destPath)) {
-				return fmt.Errorf("operation cancelled by user")
+				return errCancelled
 			}
 		}
 
@@ -182,12 +398,16 @@ func addRepoDnfYum(name, url string) error {
 
 	// Create a .repo file for a URL repository
 	repoContent := fmt.Sprintf("[%s]\nname=%s\nbaseurl=%s\nenabled=1\ngpgcheck=0\n", name, name, url)
+	if keyURL != "" {
+		// Refer to the confirmed copy of the key rather than the URL
+		repoContent = fmt.Sprintf("[%s]\nname=%s\nbaseurl=%s\nenabled=1\ngpgcheck=1\ngpgkey=file://%s\n", name, name, url, rpmKeyPath(name))
+	}
 	repoPath := filepath.Join(config.baseDir, name+config.fileExtension)
 
 	// Check if file already exists
 	if fileExists(repoPath) {
 		if !askForConfirmation(fmt.Sprintf("Repository file %s already exists. Do you want to overwrite it?", repoPath)) {
-			return fmt.Errorf("operation cancelled by user")
+			return errCancelled
 		}
 	}
 
@@ -240,9 +460,86 @@ func addRepoAlpine(name, url string) error {
 func addRepoHomebrew(url string) error {
 	// Run brew tap command
 	fmt.Printf("Adding Homebrew tap %s...\n", url)
-	return runCommand("brew", "tap", url)
+	return executeNative("brew", "tap", url)
+}
+
+// requireSigned refuses repositories whose packages would not be verified:
+// apt entries need a signed-by option or trusted=yes must not be set, and
+// generated dnf/yum repositories need a key. Downloaded .repo files are
+// checked by addRepoDnfYum.
+func requireSigned(pm *PackageManager, url, keyURL string) error {
+	switch pm.Type {
+	case "debian":
+		src, ok := parseAptLine(url)
+		if !ok {
+			return nil
+		}
+		if src.Options["trusted"] == "yes" {
+			return fmt.Errorf("the repository sets trusted=yes, which disables signature checks; use --insecure to add it anyway")
+		}
+		if _, found := src.Options["signed-by"]; !found && keyURL == "" {
+			return fmt.Errorf("the repository has no signing key, add one with --key or signed-by, or use --insecure to add it unsigned")
+		}
+	case "redhat":
+		if !strings.HasSuffix(url, ".repo") && keyURL == "" {
+			return fmt.Errorf("the repository has no signing key, add one with --key, or use --insecure to add it with gpgcheck=0")
+		}
+	}
+	return nil
+}
+
+// unsignedRepoSection returns the first section of a .repo file that turns
+// off gpgcheck, or an empty string if there is none
+func unsignedRepoSection(content string) string {
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) != "gpgcheck" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "0", "false", "no", "off":
+			return section
+		}
+	}
+	return ""
+}
+
+// firstArg returns the first argument, or an empty string if there is none
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// useDeb822 decides from the --format flag whether apt sources are written in
+// the deb822 format
+func useDeb822(cmd *cobra.Command) (bool, error) {
+	format, _ := cmd.Flags().GetString("format")
+	switch format {
+	case "":
+		return prefersDeb822(), nil
+	case "deb822", "sources":
+		return true, nil
+	case "list", "one-line":
+		return false, nil
+	default:
+		return false, fmt.Errorf("unsupported format '%s' (use deb822 or list)", format)
+	}
 }
 
 func init() {
 	rootCmd.AddCommand(addRepoCmd)
+
+	addRepoCmd.Flags().String("preset", "", "Add a well-known repository by name (docker, nodesource, postgresql, hashicorp, grafana)")
+	addRepoCmd.Flags().String("key", "", "URL of the key the repository is signed with, added together with the repository")
+	addRepoCmd.Flags().Bool("insecure", false, "Add the repository even though its packages are not verified with a key")
+	addRepoCmd.Flags().Bool("update", false, "Refresh the package lists after adding the repository")
+	addRepoCmd.Flags().String("format", "", "Format of apt source files: deb822 or list (default depends on the release)")
 }
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// manifestComparison lists the differences between a manifest and the system
+type manifestComparison struct {
+	MissingKeys     []ManifestSource
+	MissingRepos    []ManifestSource
+	MissingPackages []string
+	Drifted         []versionDrift
+	Extra           []string
+	NotHeld         []string
+}
+
+// versionDrift is a package installed with another version than the manifest records
+type versionDrift struct {
+	Name      string
+	Wanted    string
+	Installed string
+}
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply manifest",
+	Short: "Bring the system in line with a package manifest",
+	Long: `Reconcile the system with a manifest written by 'pkgs freeze' or by hand:
+missing keys and repositories are added, missing packages are installed and
+packages listed under "holds" are held. With --prune explicitly installed
+packages that are not in the manifest are removed.
+
+The planned actions are shown before anything changes; use --dry-run to only
+show them, or 'pkgs diff' for a detailed comparison. A manifest looks like:
+
+  backend: apt
+  keys:
+    - name: nodesource
+      url: https://deb.nodesource.com/gpgkey/nodesource.gpg.key
+  repos:
+    - name: nodesource
+      url: deb [signed-by=/etc/apt/keyrings/nodesource.asc] https://deb.nodesource.com/node_20.x nodistro main
+  packages:
+    - name: nodejs
+    - name: nginx
+  holds:
+    - nodejs
+
+Package versions in the manifest are reported when they differ, but are not
+enforced. Keys can only be added on apt and Alpine systems.`,
+	Example: `  pkgs apply packages.yaml --dry-run
+  pkgs apply packages.yaml
+  pkgs -y apply --prune packages.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		manifest, err := readManifest(args[0])
+		if err != nil {
+			return err
+		}
+		if err := checkManifestBackend(pm, manifest); err != nil {
+			return err
+		}
+
+		diff, err := compareManifest(pm, manifest)
+		if err != nil {
+			return err
+		}
+		prune, _ := cmd.Flags().GetBool("prune")
+		if !prune {
+			diff.Extra = nil
+		}
+
+		for _, d := range diff.Drifted {
+			fmt.Printf("Note: %s is installed in version %s, the manifest records %s\n", d.Name, d.Installed, d.Wanted)
+		}
+
+		rows := diff.actionRows()
+		if len(rows) == 0 {
+			fmt.Println("The system already matches the manifest.")
+			return nil
+		}
+		printTable([]string{"ACTION", "TARGET", "DETAIL"}, rows)
+		fmt.Println()
+
+		if IsDryRun() {
+			return nil
+		}
+		if !askForConfirmation("Do you want to continue?") {
+			return errCancelled
+		}
+		// The confirmation above covers the native prompts of every step
+		yesFlag = true
+
+		fmt.Printf("Using package manager: %s\n", pm.Name)
+		return applyManifest(pm, diff)
+	},
+}
+
+// backendTypes maps package manager names to their type
+var backendTypes = map[string]string{
+	"apt":     "debian",
+	"apt-get": "debian",
+	"dnf":     "redhat",
+	"yum":     "redhat",
+	"apk":     "alpine",
+	"pacman":  "arch",
+	"brew":    "macos",
+}
+
+// checkManifestBackend rejects manifests written for another package format,
+// whose package names would not match
+func checkManifestBackend(pm *PackageManager, manifest Manifest) error {
+	if manifest.Backend == "" {
+		return nil
+	}
+	if backendTypes[manifest.Backend] != pm.Type {
+		return fmt.Errorf("the manifest was written for %s and cannot be applied with %s", manifest.Backend, pm.Name)
+	}
+	return nil
+}
+
+// compareManifest compares a manifest with the live system
+func compareManifest(pm *PackageManager, manifest Manifest) (manifestComparison, error) {
+	var diff manifestComparison
+
+	for _, key := range manifest.Keys {
+		if !keyInstalled(pm, key) {
+			diff.MissingKeys = append(diff.MissingKeys, key)
+		}
+	}
+
+	if len(manifest.Repos) > 0 {
+		entries, err := listRepos(pm)
+		if err != nil && entries == nil {
+			return diff, err
+		}
+		for _, repo := range manifest.Repos {
+			if !repoConfigured(entries, repo) {
+				diff.MissingRepos = append(diff.MissingRepos, repo)
+			}
+		}
+	}
+
+	installed, err := listInstalled(pm)
+	if err != nil {
+		return diff, err
+	}
+	versions := map[string]string{}
+	for _, p := range installed {
+		versions[p.Name] = p.Version
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return diff, err
+	}
+	wanted := map[string]bool{}
+	for _, p := range manifest.Packages {
+		// Portable names stand for the native ones configured for this system
+		for _, name := range config.nativeNames(pm, p.Name) {
+			wanted[name] = true
+			version, ok := versions[name]
+			switch {
+			case !ok:
+				diff.MissingPackages = append(diff.MissingPackages, name)
+			case p.Version != "" && p.Version != version:
+				diff.Drifted = append(diff.Drifted, versionDrift{Name: name, Wanted: p.Version, Installed: version})
+			}
+		}
+	}
+
+	explicit, err := explicitPackages(pm)
+	if err != nil {
+		return diff, err
+	}
+	for _, name := range uniqueSorted(explicit) {
+		if _, ok := versions[name]; ok && !wanted[name] {
+			diff.Extra = append(diff.Extra, name)
+		}
+	}
+
+	if len(manifest.Holds) > 0 {
+		held, err := heldPackages(pm)
+		if err != nil {
+			return diff, err
+		}
+		isHeld := map[string]bool{}
+		for _, name := range held {
+			isHeld[name] = true
+		}
+		for _, name := range manifest.Holds {
+			if !isHeld[name] {
+				diff.NotHeld = append(diff.NotHeld, name)
+			}
+		}
+	}
+	return diff, nil
+}
+
+// keyInstalled reports whether the key file written by 'pkgs add-key' exists
+func keyInstalled(pm *PackageManager, key ManifestSource) bool {
+	switch pm.Type {
+	case "debian":
+		return fileExists(filepath.Join("/etc/apt/keyrings", key.Name+".asc"))
+	case "redhat":
+		return fileExists(rpmKeyPath(key.Name))
+	case "alpine":
+		return fileExists(filepath.Join("/etc/apk/keys", key.Name))
+	default:
+		return false
+	}
+}
+
+// repoConfigured reports whether a manifest repository matches a configured
+// repository by name, file name or URL
+func repoConfigured(entries []repoEntry, repo ManifestSource) bool {
+	for _, e := range entries {
+		file := strings.TrimSuffix(filepath.Base(e.File), filepath.Ext(e.File))
+		if e.ID == repo.Name || file == repo.Name || (e.URL != "" && strings.Contains(repo.URL, e.URL)) {
+			return true
+		}
+	}
+	return false
+}
+
+// actionRows renders the changes apply would make as table rows
+func (d manifestComparison) actionRows() [][]string {
+	var rows [][]string
+	for _, key := range d.MissingKeys {
+		rows = append(rows, []string{"add-key", key.Name, key.URL})
+	}
+	for _, repo := range d.MissingRepos {
+		rows = append(rows, []string{"add-repo", repo.Name, repo.URL})
+	}
+	for _, name := range d.MissingPackages {
+		rows = append(rows, []string{"install", name, ""})
+	}
+	for _, name := range d.NotHeld {
+		rows = append(rows, []string{"hold", name, ""})
+	}
+	for _, name := range d.Extra {
+		rows = append(rows, []string{"remove", name, "not in manifest"})
+	}
+	return rows
+}
+
+// applyManifest makes the changes found by compareManifest
+func applyManifest(pm *PackageManager, diff manifestComparison) error {
+	for _, key := range diff.MissingKeys {
+		var err error
+		switch pm.Type {
+		case "debian":
+			err = addKeyApt(key.Name, key.URL, keyPin{})
+		case "redhat":
+			err = addKeyRpm(key.Name, key.URL, keyPin{})
+		case "alpine":
+			err = addKeyAlpine(key.Name, key.URL, keyPin{})
+		default:
+			err = unsupportedError("adding keys is not supported for package manager '%s'", pm.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add key %s: %w", key.Name, err)
+		}
+	}
+
+	for _, repo := range diff.MissingRepos {
+		var err error
+		switch pm.Type {
+		case "debian":
+			err = addRepoApt(repo.Name, repo.URL, prefersDeb822())
+		case "redhat":
+			// Manifests carry no dnf/yum keys, the repositories come from an existing system
+			err = addRepoDnfYum(repo.Name, repo.URL, "", true)
+		case "alpine":
+			err = addRepoAlpine(repo.Name, repo.URL)
+		case "macos":
+			err = addRepoHomebrew(repo.URL)
+		default:
+			err = unsupportedError("adding repositories is not supported for package manager '%s'", pm.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add repository %s: %w", repo.Name, err)
+		}
+	}
+
+	// New sources are only used once the package lists are refreshed
+	if len(diff.MissingKeys) > 0 || len(diff.MissingRepos) > 0 {
+		if err := ExecuteCommand(pm, "update", nil); err != nil {
+			return err
+		}
+	}
+
+	if len(diff.MissingPackages) > 0 {
+		names, err := translateGroupArgs(pm, diff.MissingPackages)
+		if err != nil {
+			return err
+		}
+		if err := ExecuteCommand(pm, "install", names); err != nil {
+			return err
+		}
+	}
+
+	if len(diff.NotHeld) > 0 {
+		if err := holdPackages(pm, diff.NotHeld); err != nil {
+			return err
+		}
+	}
+
+	if len(diff.Extra) > 0 {
+		if err := ExecuteCommand(pm, "remove", diff.Extra); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Applied %d change(s).\n", len(diff.actionRows()))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().Bool("prune", false, "Remove explicitly installed packages that are not in the manifest")
+}
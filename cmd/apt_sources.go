@@ -0,0 +1,555 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// aptSource is one apt repository entry
+type aptSource struct {
+	Types      []string
+	URIs       []string
+	Suites     []string
+	Components []string
+	Options    map[string]string
+	Enabled    bool
+}
+
+// parseAptLine parses a one-line style entry such as
+//
+//	deb [arch=amd64 signed-by=/etc/apt/keyrings/x.asc] https://example.com/apt stable main
+//
+// Commented-out entries ("# deb ...") are returned as disabled. The second
+// return value is false if the line is not a repository entry.
+func parseAptLine(line string) (aptSource, bool) {
+	src := aptSource{Enabled: true, Options: map[string]string{}}
+
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "#") {
+		src.Enabled = false
+		line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	}
+	if !strings.HasPrefix(line, "deb ") && !strings.HasPrefix(line, "deb-src ") {
+		return src, false
+	}
+
+	typ, rest, _ := strings.Cut(line, " ")
+	src.Types = []string{typ}
+	rest = strings.TrimSpace(rest)
+
+	// Options are enclosed in square brackets before the URI
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return src, false
+		}
+		for _, opt := range strings.Fields(rest[1:end]) {
+			key, value, _ := strings.Cut(opt, "=")
+			src.Options[key] = value
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) < 2 {
+		return src, false
+	}
+	src.URIs = []string{fields[0]}
+	src.Suites = []string{fields[1]}
+	src.Components = fields[2:]
+	return src, true
+}
+
+// describe renders the entry without options, e.g. "deb https://example.com/apt stable main"
+func (s aptSource) describe() string {
+	parts := []string{strings.Join(s.Types, ","), strings.Join(s.URIs, " "), strings.Join(s.Suites, " ")}
+	if len(s.Components) > 0 {
+		parts = append(parts, strings.Join(s.Components, " "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// aptSourcesDir holds the repository files of apt
+const aptSourcesDir = "/etc/apt/sources.list.d"
+
+// aptSourceFiles returns the main sources.list followed by the one-line style
+// .list files and the deb822 style .sources files
+func aptSourceFiles() ([]string, error) {
+	files := []string{systemPath("/etc/apt/sources.list")}
+	for _, pattern := range []string{"*.list", "*.sources"} {
+		matches, err := filepath.Glob(systemPath(filepath.Join(aptSourcesDir, pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repository files: %v", err)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// findAptSourceFile returns the .list or .sources file of the named repository
+func findAptSourceFile(name string) (string, error) {
+	for _, ext := range []string{".list", ".sources"} {
+		if path := filepath.Join(aptSourcesDir, name+ext); fileExists(path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no repository file for '%s' found in %s", name, aptSourcesDir)
+}
+
+// aptSourceID returns the repository name of a source file, its base name
+// without extension
+func aptSourceID(file string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".list"), ".sources")
+}
+
+// parseAptSources parses the entries of a source file in the format its
+// extension indicates
+func parseAptSources(file, content string) []aptSource {
+	if strings.HasSuffix(file, ".sources") {
+		return parseDeb822(content)
+	}
+	var sources []aptSource
+	for _, line := range strings.Split(content, "\n") {
+		if src, ok := parseAptLine(line); ok {
+			sources = append(sources, src)
+		}
+	}
+	return sources
+}
+
+// parseDeb822 parses a deb822 style .sources file, whose stanzas look like
+//
+//	Types: deb
+//	URIs: https://example.com/apt
+//	Suites: stable
+//	Components: main
+//	Signed-By: /etc/apt/keyrings/example.asc
+//	Enabled: no
+//
+// Field names are case-insensitive; fields other than Types, URIs, Suites,
+// Components and Enabled are returned as options with lower-case names.
+func parseDeb822(content string) []aptSource {
+	var sources []aptSource
+	for _, stanza := range deb822Stanzas(content) {
+		src := aptSource{Enabled: true, Options: map[string]string{}}
+		for _, field := range stanza {
+			values := strings.Fields(field.Value)
+			switch strings.ToLower(field.Name) {
+			case "types":
+				src.Types = values
+			case "uris":
+				src.URIs = values
+			case "suites":
+				src.Suites = values
+			case "components":
+				src.Components = values
+			case "enabled":
+				src.Enabled = strings.ToLower(field.Value) != "no"
+			default:
+				src.Options[strings.ToLower(field.Name)] = field.Value
+			}
+		}
+		if len(src.Types) > 0 && len(src.URIs) > 0 {
+			sources = append(sources, src)
+		}
+	}
+	return sources
+}
+
+// deb822Field is one field of a deb822 stanza. Multi-line values, such as
+// inline keys, keep only their first line.
+type deb822Field struct {
+	Name  string
+	Value string
+}
+
+// deb822Stanzas splits deb822 content into stanzas of fields
+func deb822Stanzas(content string) [][]deb822Field {
+	var stanzas [][]deb822Field
+	var current []deb822Field
+	for _, line := range strings.Split(content+"\n", "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(current) > 0 {
+				stanzas = append(stanzas, current)
+			}
+			current = nil
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, " "), strings.HasPrefix(line, "\t"):
+			// Comments and continuation lines
+		default:
+			name, value, found := strings.Cut(line, ":")
+			if found {
+				current = append(current, deb822Field{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+			}
+		}
+	}
+	return stanzas
+}
+
+// setDeb822Enabled sets the Enabled field of every stanza of a .sources file.
+// Enabled stanzas carry no Enabled field, which defaults to yes. The second
+// return value reports whether anything changed.
+func setDeb822Enabled(content string, enable bool) (string, bool) {
+	lines := strings.Split(content, "\n")
+	var result []string
+	changed := false
+	inStanza := false
+	hasEnabled := false
+	finishStanza := func() {
+		if inStanza && !enable && !hasEnabled {
+			result = append(result, "Enabled: no")
+			changed = true
+		}
+		inStanza, hasEnabled = false, false
+	}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			finishStanza()
+			result = append(result, line)
+			continue
+		}
+		if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			inStanza = true
+			name, value, _ := strings.Cut(line, ":")
+			if strings.EqualFold(strings.TrimSpace(name), "Enabled") {
+				hasEnabled = true
+				isEnabled := strings.ToLower(strings.TrimSpace(value)) != "no"
+				if isEnabled == enable {
+					result = append(result, line)
+				} else if !enable {
+					result = append(result, "Enabled: no")
+					changed = true
+				} else {
+					// Drop the field, enabled is the default
+					changed = true
+				}
+				continue
+			}
+		}
+		result = append(result, line)
+	}
+	// The last stanza may end without a blank line
+	finishStanza()
+	return strings.Join(result, "\n"), changed
+}
+
+// deb822FieldNames maps one-line options to their deb822 field names
+var deb822FieldNames = map[string]string{
+	"arch":      "Architectures",
+	"lang":      "Languages",
+	"target":    "Targets",
+	"pdiffs":    "PDiffs",
+	"signed-by": "Signed-By",
+}
+
+// formatDeb822 renders an entry as a deb822 stanza
+func formatDeb822(src aptSource) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Types: %s\n", strings.Join(src.Types, " "))
+	fmt.Fprintf(&b, "URIs: %s\n", strings.Join(src.URIs, " "))
+	fmt.Fprintf(&b, "Suites: %s\n", strings.Join(src.Suites, " "))
+	if len(src.Components) > 0 {
+		fmt.Fprintf(&b, "Components: %s\n", strings.Join(src.Components, " "))
+	}
+
+	keys := make([]string, 0, len(src.Options))
+	for key := range src.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name, ok := deb822FieldNames[key]
+		if !ok {
+			// Other options only differ in case, e.g. "check-valid-until" is "Check-Valid-Until"
+			parts := strings.Split(key, "-")
+			for i, part := range parts {
+				if part != "" {
+					parts[i] = strings.ToUpper(part[:1]) + part[1:]
+				}
+			}
+			name = strings.Join(parts, "-")
+		}
+		// Lists are comma-separated in one-line entries and space-separated in deb822
+		fmt.Fprintf(&b, "%s: %s\n", name, strings.ReplaceAll(src.Options[key], ",", " "))
+	}
+	if !src.Enabled {
+		b.WriteString("Enabled: no\n")
+	}
+	return b.String()
+}
+
+// prefersDeb822 reports whether the distribution writes its own sources in the
+// deb822 format, which is the case from Debian 12 and Ubuntu 24.04 on
+func prefersDeb822() bool {
+	distro, version := distroInfo()
+	major, minor, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return false
+	}
+	switch distro {
+	case "debian":
+		return n >= 12
+	case "ubuntu":
+		return n > 24 || (n == 24 && minor >= "04")
+	default:
+		return false
+	}
+}
+
+// aptLineChange is a one-line entry that is commented or uncommented
+type aptLineChange struct {
+	File    string
+	Line    int
+	Current string
+	New     string
+}
+
+// setAptLinesEnabled comments or uncomments the one-line entries containing
+// pattern in /etc/apt/sources.list and the .list files, after showing them
+func setAptLinesEnabled(pattern string, enable bool) error {
+	files, err := aptSourceFiles()
+	if err != nil {
+		return err
+	}
+
+	contents := map[string][]string{}
+	var changes []aptLineChange
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".list") {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to read %s: %v", file, err)
+		}
+		lines := strings.Split(string(content), "\n")
+		for i, line := range lines {
+			src, ok := parseAptLine(line)
+			if !ok || src.Enabled == enable || !strings.Contains(line, pattern) {
+				continue
+			}
+			newLine := "# " + line
+			if enable {
+				// Remove the comment sign together with the spaces after it
+				hash := strings.Index(line, "#")
+				newLine = line[:hash] + strings.TrimLeft(line[hash+1:], " \t")
+			}
+			changes = append(changes, aptLineChange{File: file, Line: i + 1, Current: line, New: newLine})
+			lines[i] = newLine
+		}
+		contents[file] = lines
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("No %s entries matching '%s' found.\n", strings.ToLower(statusLabel(!enable)), pattern)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(changes))
+	for _, c := range changes {
+		rows = append(rows, []string{fmt.Sprintf("%s:%d", c.File, c.Line), c.New})
+	}
+	printTable([]string{"LINE", "NEW CONTENT"}, rows)
+
+	if !askForConfirmation("Apply these changes?") {
+		return errCancelled
+	}
+
+	for _, file := range files {
+		changed := false
+		for _, c := range changes {
+			changed = changed || c.File == file
+		}
+		if !changed {
+			continue
+		}
+		if err := writeFileContent(file, strings.Join(contents[file], "\n"), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Updated %s\n", file)
+	}
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// setDeb822SourceTypes adds deb-src to or removes it from the Types field of
+// every stanza that also has deb. The second return value reports whether
+// anything changed.
+func setDeb822SourceTypes(content string, enable bool) (string, bool) {
+	lines := strings.Split(content, "\n")
+	changed := false
+	for i, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		if !found || strings.HasPrefix(line, "#") || !strings.EqualFold(strings.TrimSpace(name), "Types") {
+			continue
+		}
+		types := strings.Fields(value)
+		var hasDeb, hasSrc bool
+		var kept []string
+		for _, t := range types {
+			hasDeb = hasDeb || t == "deb"
+			hasSrc = hasSrc || t == "deb-src"
+			if t != "deb-src" {
+				kept = append(kept, t)
+			}
+		}
+		// Stanzas with only deb-src are left alone, they have no binary counterpart
+		switch {
+		case !hasDeb || hasSrc == enable:
+			continue
+		case enable:
+			types = append(types, "deb-src")
+		default:
+			types = kept
+		}
+		lines[i] = strings.TrimSpace(name) + ": " + strings.Join(types, " ")
+		changed = true
+	}
+	return strings.Join(lines, "\n"), changed
+}
+
+// setOneLineSources adds or uncomments a deb-src entry for every enabled deb
+// entry, or comments out all deb-src entries. The second return value reports
+// whether anything changed.
+func setOneLineSources(content string, enable bool) (string, bool) {
+	lines := strings.Split(content, "\n")
+	changed := false
+	if !enable {
+		for i, line := range lines {
+			if src, ok := parseAptLine(line); ok && src.Enabled && src.Types[0] == "deb-src" {
+				lines[i] = "# " + line
+				changed = true
+			}
+		}
+		return strings.Join(lines, "\n"), changed
+	}
+
+	// Existing deb-src entries, keyed by everything after the type
+	debs := map[string]bool{}
+	firstSource := map[string]int{}
+	enabledSource := map[string]bool{}
+	for i, line := range lines {
+		src, ok := parseAptLine(line)
+		if !ok {
+			continue
+		}
+		rest := aptLineRest(line)
+		switch {
+		case src.Types[0] == "deb" && src.Enabled:
+			debs[rest] = true
+		case src.Types[0] == "deb-src":
+			if _, seen := firstSource[rest]; !seen {
+				firstSource[rest] = i
+			}
+			enabledSource[rest] = enabledSource[rest] || src.Enabled
+		}
+	}
+
+	var result []string
+	for i, line := range lines {
+		src, ok := parseAptLine(line)
+		if !ok {
+			result = append(result, line)
+			continue
+		}
+		rest := aptLineRest(line)
+		switch {
+		case src.Types[0] == "deb-src" && !src.Enabled && debs[rest] && !enabledSource[rest] && firstSource[rest] == i:
+			// Uncomment the deb-src entry of an enabled deb entry
+			hash := strings.Index(line, "#")
+			result = append(result, line[:hash]+strings.TrimLeft(line[hash+1:], " \t"))
+			enabledSource[rest] = true
+			changed = true
+		case src.Types[0] == "deb" && src.Enabled && !enabledSource[rest]:
+			result = append(result, line)
+			if _, exists := firstSource[rest]; !exists {
+				indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+				result = append(result, indent+"deb-src "+rest)
+				firstSource[rest] = i
+				enabledSource[rest] = true
+				changed = true
+			}
+		default:
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n"), changed
+}
+
+// aptLineRest returns a one-line entry without its type and comment sign,
+// with normalized spacing
+func aptLineRest(line string) string {
+	_, rest, _ := strings.Cut(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#")), " ")
+	return strings.Join(strings.Fields(rest), " ")
+}
+
+// setAptSourcesEnabled turns the deb-src entries of a repository, or of all
+// repositories if name is empty, on or off
+func setAptSourcesEnabled(name string, enable bool) error {
+	var files []string
+	if name != "" {
+		file, err := findAptSourceFile(name)
+		if err != nil {
+			return err
+		}
+		files = []string{file}
+	} else {
+		var err error
+		if files, err = aptSourceFiles(); err != nil {
+			return err
+		}
+	}
+
+	var changedFiles []string
+	newContents := map[string]string{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to read %s: %v", file, err)
+		}
+
+		var newContent string
+		var changed bool
+		if strings.HasSuffix(file, ".sources") {
+			newContent, changed = setDeb822SourceTypes(string(content), enable)
+		} else {
+			newContent, changed = setOneLineSources(string(content), enable)
+		}
+		if changed {
+			changedFiles = append(changedFiles, file)
+			newContents[file] = newContent
+		}
+	}
+
+	if len(changedFiles) == 0 {
+		fmt.Printf("Source repositories are already %s.\n", strings.ToLower(statusLabel(enable)))
+		return nil
+	}
+	steps := make([]planStep, 0, len(changedFiles))
+	for _, file := range changedFiles {
+		steps = append(steps, planStep{"write", file})
+	}
+	if err := confirmPlan(steps...); err != nil {
+		return err
+	}
+	for _, file := range changedFiles {
+		if err := writeFileContent(file, newContents[file], 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Updated %s\n", file)
+	}
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
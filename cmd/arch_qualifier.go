@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// archNames are the names each package manager family gives an architecture
+type archNames struct {
+	debian, redhat, alpine, arch string
+}
+
+// architectures lists the known architectures with their aliases. The first
+// alias is the portable name used in messages.
+var architectures = []struct {
+	aliases []string
+	names   archNames
+}{
+	{[]string{"x86_64", "amd64", "x64"}, archNames{"amd64", "x86_64", "x86_64", "x86_64"}},
+	{[]string{"i686", "i386", "i586", "x86"}, archNames{"i386", "i686", "x86", "i686"}},
+	{[]string{"aarch64", "arm64"}, archNames{"arm64", "aarch64", "aarch64", "aarch64"}},
+	{[]string{"armhf", "armv7hl", "armv7h", "armv7"}, archNames{"armhf", "armv7hl", "armv7", "armv7h"}},
+	{[]string{"ppc64le", "ppc64el"}, archNames{"ppc64el", "ppc64le", "ppc64le", "ppc64le"}},
+	{[]string{"s390x"}, archNames{"s390x", "s390x", "s390x", "s390x"}},
+	{[]string{"riscv64"}, archNames{"riscv64", "riscv64", "riscv64", "riscv64"}},
+	{[]string{"noarch", "all", "any"}, archNames{"all", "noarch", "noarch", "any"}},
+}
+
+// findArch returns the index of an architecture in architectures, or -1
+func findArch(name string) int {
+	for i, a := range architectures {
+		if slices.Contains(a.aliases, strings.ToLower(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitArchQualifier splits a "name:arch" or "name.arch" argument. As package
+// names may contain dots, "name.arch" is only an architecture qualifier for a
+// known architecture.
+func splitArchQualifier(arg string) (name string, arch int, ok bool) {
+	if i := strings.LastIndex(arg, ":"); i > 0 && i < len(arg)-1 {
+		if arch := findArch(arg[i+1:]); arch >= 0 {
+			return arg[:i], arch, true
+		}
+		return arg, -1, false
+	}
+	if i := strings.LastIndex(arg, "."); i > 0 && i < len(arg)-1 {
+		if arch := findArch(arg[i+1:]); arch >= 0 {
+			return arg[:i], arch, true
+		}
+	}
+	return arg, -1, false
+}
+
+// nativeArch returns the index in architectures of the machine's architecture
+func nativeArch() int {
+	output, err := runCommandOutput("uname", "-m")
+	if err != nil {
+		return -1
+	}
+	return findArch(strings.TrimSpace(output))
+}
+
+// translateArchQualifiers rewrites architecture-qualified names such as
+// libc6:i386 or glibc.i686 into the native syntax:
+//
+//	apt:   name:arch, for the native or an added foreign architecture
+//	dnf:   name.arch
+//	other: the native architecture only, the qualifier is dropped
+func translateArchQualifiers(pm *PackageManager, args []string) ([]string, error) {
+	result := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "@") || isLocalPackageFile(pm, arg) {
+			result = append(result, arg)
+			continue
+		}
+		if name, _, pinned := splitVersionPin(pm, arg); pinned {
+			if _, _, ok := splitArchQualifier(name); ok {
+				return nil, fmt.Errorf("%s: an architecture cannot be combined with a version", arg)
+			}
+			result = append(result, arg)
+			continue
+		}
+		name, arch, ok := splitArchQualifier(arg)
+		if !ok {
+			result = append(result, arg)
+			continue
+		}
+		names := architectures[arch].names
+
+		switch pm.Type {
+		case "debian":
+			if names.debian != "all" {
+				native, _ := runCommandOutput("dpkg", "--print-architecture")
+				foreign, _ := runCommandOutput("dpkg", "--print-foreign-architectures")
+				if names.debian != strings.TrimSpace(native) && !slices.Contains(strings.Fields(foreign), names.debian) {
+					return nil, fmt.Errorf("architecture %s is not enabled, add it with 'dpkg --add-architecture %s' and run 'pkgs update'", names.debian, names.debian)
+				}
+			}
+			result = append(result, name+":"+names.debian)
+		case "redhat":
+			result = append(result, name+"."+names.redhat)
+		default:
+			if names.debian != "all" && arch != nativeArch() {
+				return nil, unsupportedError("package manager '%s' only installs packages for the native architecture, not %s", pm.Name, architectures[arch].aliases[0])
+			}
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import "fmt"
+
+// assumeNoFlag answers no to all prompts, see IsAssumeNo
+var assumeNoFlag bool
+
+// IsAssumeNo checks if every prompt is answered with no. Package operations
+// are simulated and other native commands only shown, as in a dry run, and
+// the changes pkgs makes itself stop at their confirmation.
+func IsAssumeNo() bool {
+	return assumeNoFlag
+}
+
+// checkAssumeNo rejects --assume-no together with an answer of yes
+func checkAssumeNo() error {
+	if !assumeNoFlag {
+		return nil
+	}
+	if yesFlag {
+		return fmt.Errorf("--assume-no cannot be combined with --yes")
+	}
+	if dryRunFlag {
+		return fmt.Errorf("--assume-no cannot be combined with --dry-run, which answers yes to show every change")
+	}
+	return nil
+}
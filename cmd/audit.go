@@ -0,0 +1,536 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// osvAPI is the endpoint of the OSV vulnerability database, which aggregates
+// the Debian, Ubuntu, Alpine, Rocky Linux and AlmaLinux security trackers
+const osvAPI = "https://api.osv.dev/v1"
+
+// Vulnerability is a known vulnerability affecting an installed package
+type Vulnerability struct {
+	ID        string    `json:"id"`
+	Aliases   []string  `json:"aliases,omitempty"`
+	Package   string    `json:"package"`
+	Version   string    `json:"version"`
+	Source    string    `json:"source,omitempty"`
+	Fixed     string    `json:"fixed,omitempty"`
+	Severity  string    `json:"severity"`
+	Summary   string    `json:"summary,omitempty"`
+	Published time.Time `json:"published"`
+}
+
+// auditPackage is an installed package as it is known to the security trackers,
+// which track source packages on Debian, Ubuntu and Alpine
+type auditPackage struct {
+	Name    string
+	Source  string
+	Version string
+}
+
+// severityRanks orders severities from least to most severe
+var severityRanks = map[string]int{"unknown": 0, "low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "List installed packages with known vulnerabilities",
+	Long: `Check the installed package versions against the OSV vulnerability
+database (https://osv.dev), which includes the Debian and Ubuntu security
+trackers, the Alpine secdb and the Rocky Linux and AlmaLinux advisories.
+
+Every vulnerability is listed with its severity, the affected package and the
+version that fixes it, if one is available. pkgs exits with an error when a
+critical vulnerability is found.
+
+Supported distributions: Debian, Ubuntu, Alpine Linux, Rocky Linux and
+AlmaLinux. Use arch-audit on Arch Linux and 'brew audit' is not a security
+check, so Homebrew is not supported.`,
+	Example: `  pkgs audit
+  pkgs audit --min-severity high
+  pkgs audit --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		minSeverity, _ := cmd.Flags().GetString("min-severity")
+		if _, ok := severityRanks[minSeverity]; !ok {
+			return fmt.Errorf("unknown severity '%s' (use low, medium, high or critical)", minSeverity)
+		}
+
+		vulns, err := auditInstalled(pm)
+		if err != nil {
+			return err
+		}
+
+		filtered := []Vulnerability{}
+		critical := 0
+		for _, v := range vulns {
+			if v.Severity == "critical" {
+				critical++
+			}
+			if severityRanks[v.Severity] >= severityRanks[minSeverity] {
+				filtered = append(filtered, v)
+			}
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := printJSON(filtered); err != nil {
+				return err
+			}
+		} else if len(filtered) == 0 {
+			fmt.Println("No known vulnerabilities found.")
+		} else {
+			defer startPager()()
+			rows := make([][]string, 0, len(filtered))
+			for _, v := range filtered {
+				fixed := v.Fixed
+				if fixed == "" {
+					fixed = "-"
+				}
+				rows = append(rows, []string{v.Severity, v.Package, v.Version, v.displayID(), fixed, truncate(v.Summary, 60)})
+			}
+			printTable([]string{"SEVERITY", "PACKAGE", "VERSION", "ID", "FIXED IN", "SUMMARY"}, rows)
+		}
+
+		if critical > 0 {
+			return fmt.Errorf("%d critical vulnerabilit(y/ies) found", critical)
+		}
+		return nil
+	},
+}
+
+// auditInstalled looks up the known vulnerabilities of all installed packages,
+// sorted by severity
+func auditInstalled(pm *PackageManager) ([]Vulnerability, error) {
+	ecosystem, err := osvEcosystem()
+	if err != nil {
+		return nil, err
+	}
+	packages, err := listAuditPackages(pm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Binary packages built from the same source share their vulnerabilities
+	type sourceKey struct{ name, version string }
+	binaries := map[sourceKey][]string{}
+	var sources []sourceKey
+	for _, p := range packages {
+		key := sourceKey{p.Source, p.Version}
+		if _, ok := binaries[key]; !ok {
+			sources = append(sources, key)
+		}
+		binaries[key] = append(binaries[key], p.Name)
+	}
+
+	queries := make([]osvQuery, 0, len(sources))
+	for _, s := range sources {
+		queries = append(queries, osvQuery{Package: osvPackage{Name: s.name, Ecosystem: ecosystem}, Version: s.version})
+	}
+	ids, err := osvQueryBatch(queries)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := osvFetchVulns(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	vulns := []Vulnerability{}
+	for i, s := range sources {
+		for _, id := range ids[i] {
+			detail, ok := details[id]
+			if !ok {
+				continue
+			}
+			v := detail.toVulnerability(s.name)
+			v.Version = s.version
+			for _, name := range binaries[s] {
+				entry := v
+				entry.Package = name
+				if name == s.name {
+					entry.Source = ""
+				}
+				vulns = append(vulns, entry)
+			}
+		}
+	}
+
+	sort.SliceStable(vulns, func(i, j int) bool {
+		if severityRanks[vulns[i].Severity] != severityRanks[vulns[j].Severity] {
+			return severityRanks[vulns[i].Severity] > severityRanks[vulns[j].Severity]
+		}
+		if vulns[i].Package != vulns[j].Package {
+			return vulns[i].Package < vulns[j].Package
+		}
+		return vulns[i].ID < vulns[j].ID
+	})
+	return vulns, nil
+}
+
+// osvEcosystem returns the OSV ecosystem of the running distribution
+func osvEcosystem() (string, error) {
+	distro, version := distroInfo()
+	major, _, _ := strings.Cut(version, ".")
+	switch distro {
+	case "debian":
+		return "Debian:" + major, nil
+	case "ubuntu":
+		// Only LTS releases are tracked with the LTS suffix, e.g. "Ubuntu:22.04:LTS"
+		if strings.HasSuffix(version, ".04") && len(major) == 2 && major[1]%2 == 0 {
+			return "Ubuntu:" + version + ":LTS", nil
+		}
+		return "Ubuntu:" + version, nil
+	case "alpine":
+		parts := strings.Split(version, ".")
+		if len(parts) < 2 {
+			return "", fmt.Errorf("unknown Alpine version '%s'", version)
+		}
+		return "Alpine:v" + parts[0] + "." + parts[1], nil
+	case "rocky":
+		return "Rocky Linux:" + major, nil
+	case "almalinux":
+		return "AlmaLinux:" + major, nil
+	default:
+		return "", unsupportedError("vulnerability data is not available for distribution '%s'", distro)
+	}
+}
+
+// listAuditPackages lists the installed packages with their source package
+func listAuditPackages(pm *PackageManager) ([]auditPackage, error) {
+	var packages []auditPackage
+	switch pm.Type {
+	case "debian":
+		output, err := runCommandOutput("dpkg-query", "-W", "-f", "${db:Status-Abbrev} ${Package} ${source:Package} ${source:Version}\n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed packages: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 4 || len(fields[0]) < 2 || fields[0][1] != 'i' {
+				continue
+			}
+			packages = append(packages, auditPackage{Name: fields[1], Source: fields[2], Version: fields[3]})
+		}
+	case "alpine":
+		// Output: "libcrypto3-3.1.4-r5 x86_64 {openssl} (Apache-2.0) [installed]"
+		output, err := runCommandOutput("apk", "list", "--installed")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed packages: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				continue
+			}
+			name, version := splitApkNameVersion(fields[0])
+			packages = append(packages, auditPackage{Name: name, Source: strings.Trim(fields[2], "{}"), Version: version})
+		}
+	default:
+		installed, err := listInstalled(pm)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range installed {
+			packages = append(packages, auditPackage{Name: p.Name, Source: p.Name, Version: p.Version})
+		}
+	}
+	return packages, nil
+}
+
+// osvPackage identifies a package in an OSV ecosystem
+type osvPackage struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+// osvQuery is one query of an OSV batch request
+type osvQuery struct {
+	Package osvPackage `json:"package"`
+	Version string     `json:"version"`
+}
+
+// osvVuln is the part of an OSV vulnerability record used by pkgs
+type osvVuln struct {
+	ID        string    `json:"id"`
+	Summary   string    `json:"summary"`
+	Details   string    `json:"details"`
+	Aliases   []string  `json:"aliases"`
+	Published time.Time `json:"published"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package osvPackage `json:"package"`
+		Ranges  []struct {
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+		EcosystemSpecific map[string]any `json:"ecosystem_specific"`
+		DatabaseSpecific  map[string]any `json:"database_specific"`
+	} `json:"affected"`
+	DatabaseSpecific map[string]any `json:"database_specific"`
+}
+
+// osvClient is used for all requests to the OSV API
+var osvClient = &http.Client{Transport: httpTransport, Timeout: 60 * time.Second}
+
+// osvQueryBatch returns the IDs of the vulnerabilities affecting each query
+func osvQueryBatch(queries []osvQuery) ([][]string, error) {
+	results := make([][]string, 0, len(queries))
+	// The API accepts at most 1000 queries per request
+	for start := 0; start < len(queries); start += 1000 {
+		end := min(start+1000, len(queries))
+		body, err := json.Marshal(map[string]any{"queries": queries[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		resp, err := osvClient.Post(osvAPI+"/querybatch", "application/json", bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the OSV database: %v", err)
+		}
+		var batch struct {
+			Results []struct {
+				Vulns []struct {
+					ID string `json:"id"`
+				} `json:"vulns"`
+			} `json:"results"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&batch)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to query the OSV database: %s", resp.Status)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the OSV response: %v", err)
+		}
+		for i := range queries[start:end] {
+			var ids []string
+			if i < len(batch.Results) {
+				for _, v := range batch.Results[i].Vulns {
+					ids = append(ids, v.ID)
+				}
+			}
+			results = append(results, ids)
+		}
+	}
+	return results, nil
+}
+
+// osvFetchVulns downloads the records of the given vulnerabilities
+func osvFetchVulns(ids [][]string) (map[string]osvVuln, error) {
+	unique := map[string]bool{}
+	for _, list := range ids {
+		for _, id := range list {
+			unique[id] = true
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	details := map[string]osvVuln{}
+	work := make(chan string)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range work {
+				vuln, err := osvFetchVuln(id)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				} else if err == nil {
+					details[id] = vuln
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for id := range unique {
+		work <- id
+	}
+	close(work)
+	wg.Wait()
+	return details, firstErr
+}
+
+// osvFetchVuln downloads one vulnerability record
+func osvFetchVuln(id string) (osvVuln, error) {
+	var vuln osvVuln
+	resp, err := osvClient.Get(osvAPI + "/vulns/" + id)
+	if err != nil {
+		return vuln, fmt.Errorf("failed to fetch %s: %v", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return vuln, fmt.Errorf("failed to fetch %s: %s", id, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&vuln); err != nil {
+		return vuln, fmt.Errorf("failed to decode %s: %v", id, err)
+	}
+	return vuln, nil
+}
+
+// toVulnerability converts an OSV record for the given source package
+func (v osvVuln) toVulnerability(source string) Vulnerability {
+	result := Vulnerability{
+		ID:        v.ID,
+		Aliases:   v.Aliases,
+		Source:    source,
+		Summary:   v.Summary,
+		Published: v.Published,
+		Severity:  "unknown",
+	}
+	if result.Summary == "" {
+		result.Summary, _, _ = strings.Cut(strings.TrimSpace(v.Details), "\n")
+	}
+
+	for _, s := range v.Severity {
+		switch {
+		case strings.HasPrefix(s.Score, "CVSS:3"):
+			result.Severity = cvssRating(cvss3BaseScore(s.Score))
+		case s.Type == "Ubuntu":
+			result.Severity = normalizeSeverity(s.Score)
+		}
+	}
+	if sev, ok := v.DatabaseSpecific["severity"].(string); ok && result.Severity == "unknown" {
+		result.Severity = normalizeSeverity(sev)
+	}
+
+	for _, a := range v.Affected {
+		if a.Package.Name != source {
+			continue
+		}
+		// Debian rates the urgency of a fix per package
+		if urgency, ok := a.EcosystemSpecific["urgency"].(string); ok && result.Severity == "unknown" {
+			result.Severity = normalizeSeverity(urgency)
+		}
+		for _, r := range a.Ranges {
+			for _, event := range r.Events {
+				if fixed, ok := event["fixed"]; ok && result.Fixed == "" {
+					result.Fixed = fixed
+				}
+			}
+		}
+	}
+	return result
+}
+
+// displayID prefers the CVE alias of a distribution advisory
+func (v Vulnerability) displayID() string {
+	if strings.HasPrefix(v.ID, "CVE-") {
+		return v.ID
+	}
+	for _, alias := range v.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			return alias
+		}
+	}
+	return v.ID
+}
+
+// normalizeSeverity maps tracker-specific ratings onto low/medium/high/critical
+func normalizeSeverity(s string) string {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "low", "medium", "high", "critical":
+		return s
+	case "moderate":
+		return "medium"
+	case "negligible", "unimportant":
+		return "low"
+	case "important":
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// cvssRating maps a CVSS base score to its qualitative rating
+func cvssRating(score float64) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	case score > 0:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+// cvss3BaseScore computes the base score of a CVSS 3.x vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", following the specification
+func cvss3BaseScore(vector string) float64 {
+	metrics := map[string]string{}
+	for _, part := range strings.Split(vector, "/")[1:] {
+		if key, value, found := strings.Cut(part, ":"); found {
+			metrics[key] = value
+		}
+	}
+
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	changed := metrics["S"] == "C"
+	privileges := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	if changed {
+		privileges = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+	}
+
+	value := func(metric string) float64 { return weights[metric][metrics[metric]] }
+	iss := 1 - (1-value("C"))*(1-value("I"))*(1-value("A"))
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0
+	}
+	exploitability := 8.22 * value("AV") * value("AC") * privileges[metrics["PR"]] * value("UI")
+
+	score := impact + exploitability
+	if changed {
+		score *= 1.08
+	}
+	return math.Ceil(math.Min(score, 10)*10) / 10
+}
+
+// truncate shortens text to at most n characters
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	return text[:n-3] + "..."
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().String("min-severity", "unknown", "Only show vulnerabilities of at least this severity (low, medium, high, critical)")
+	auditCmd.Flags().Bool("json", false, "Output results as JSON")
+}
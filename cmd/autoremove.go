@@ -13,17 +13,14 @@ var autoremoveCmd = &cobra.Command{
 	Short:   "Remove unused packages",
 	Long:    `Remove automatically installed packages that are no longer required using the native package manager.`,
 	Example: `  pkgs autoremove`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
 		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "autoremove", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
-		}
+		return ExecuteCommand(pm, "autoremove", args)
 	},
 }
 
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// changelogCmd represents the changelog command
+var changelogCmd = &cobra.Command{
+	Use:   "changelog package",
+	Short: "Show the changelog of a package",
+	Long: `Show the changelog of a package, to review what an upgrade brings before
+applying it. The changelog of the newest available version is shown where the
+package manager can fetch it.
+
+For apt-based systems (Debian/Ubuntu):
+  apt changelog package
+
+For dnf-based systems (Fedora/RHEL/CentOS):
+  dnf repoquery --changelogs --latest-limit 1 package
+
+For yum-based systems:
+  rpm -q --changelog package (installed packages only)
+
+For Arch Linux:
+  pacman -Qc package (only packages that ship a changelog)
+
+For Homebrew (macOS):
+  brew log package (the commit history of the formula)
+
+Alpine packages do not carry changelogs.`,
+	Example: `  pkgs changelog openssl
+  pkgs changelog --no-pager nginx | head -40`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+		name := args[0]
+
+		var bin string
+		var cmdArgs []string
+		switch pm.Name {
+		case "apt", "apt-get":
+			bin, cmdArgs = pm.Bin, []string{"changelog", name}
+		case "dnf":
+			bin, cmdArgs = "dnf", []string{"-q", "repoquery", "--changelogs", "--latest-limit", "1", name}
+		case "yum":
+			bin, cmdArgs = "rpm", []string{"-q", "--changelog", name}
+		case "pacman":
+			bin, cmdArgs = "pacman", []string{"-Qc", name}
+		case "brew":
+			bin, cmdArgs = "brew", []string{"log", "--oneline", name}
+		default:
+			return unsupportedError("changelogs are not available for package manager '%s'", pm.Name)
+		}
+
+		defer startPager()()
+
+		c := newCommand(bin, cmdArgs...)
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("failed to get the changelog of %s: %w", name, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+}
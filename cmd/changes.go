@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// changeEvent is a single entry of the system change report
+type changeEvent struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Action  string    `json:"action"`
+	Subject string    `json:"subject"`
+	Version string    `json:"version,omitempty"`
+}
+
+// changesCmd represents the changes command
+var changesCmd = &cobra.Command{
+	Use:   "changes",
+	Short: "Show package and repository changes since a date",
+	Long: `Show a chronological report of package and repository changes on this system.
+
+The report merges the changes pkgs logged in ` + transactionLogDir + `, the
+native package manager's transaction log and the modification times of
+repository and key files, which is useful for incident retrospectives.
+
+The --since value can be a date (2024-06-01), a date and time
+(2024-06-01T14:00:00) or a relative period such as 12h, 7d or 2w.`,
+	Example: `  pkgs changes --since 2024-06-01
+  pkgs changes --since 7d
+  pkgs changes --since 24h --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		since, err := parseSince(sinceFlag, time.Now())
+		if err != nil {
+			return err
+		}
+
+		events, warnings := collectChanges(pm, since)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			return printJSON(events)
+		}
+
+		defer startPager()()
+
+		if len(events) == 0 {
+			fmt.Printf("No changes found since %s.\n", since.Format("2006-01-02 15:04"))
+			return nil
+		}
+
+		rows := make([][]string, 0, len(events))
+		for _, e := range events {
+			rows = append(rows, []string{e.Time.Format("2006-01-02 15:04:05"), e.Source, e.Action, e.Subject, e.Version})
+		}
+		printTable([]string{"TIME", "SOURCE", "ACTION", "SUBJECT", "VERSION"}, rows)
+		return nil
+	},
+}
+
+// collectChanges gathers package and repository changes made after since.
+// Sources that cannot be read are reported as warnings rather than errors
+// so that the remaining sources still make it into the report.
+func collectChanges(pm *PackageManager, since time.Time) ([]changeEvent, []string) {
+	var events []changeEvent
+	var warnings []string
+
+	logged, err := pkgsLogChanges(since)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	events = append(events, logged...)
+
+	txs, err := readTransactions(pm)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	source := pm.Name + " log"
+	for _, tx := range txs {
+		if tx.Time.Before(since) {
+			continue
+		}
+		for _, c := range tx.Changes {
+			version := c.Version
+			if c.OldVersion != "" {
+				version = c.OldVersion + " -> " + c.Version
+			}
+			events = append(events, changeEvent{Time: tx.Time, Source: source, Action: c.Action, Subject: c.Package, Version: version})
+		}
+	}
+
+	for _, path := range repoConfigPaths(pm.Type) {
+		info, err := os.Stat(systemPath(path))
+		if err != nil || info.IsDir() || info.ModTime().Before(since) {
+			continue
+		}
+		events = append(events, changeEvent{Time: info.ModTime(), Source: "repo config", Action: "modified", Subject: path})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events, warnings
+}
+
+// pkgsLogChanges returns the commands and file changes pkgs recorded in its
+// transaction log after since
+func pkgsLogChanges(since time.Time) ([]changeEvent, error) {
+	path := filepath.Join(transactionLogDir, "history.jsonl")
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the pkgs log: %v", err)
+	}
+
+	var events []changeEvent
+	for _, line := range strings.Split(string(content), "\n") {
+		var entry TransactionLogEntry
+		if json.Unmarshal([]byte(line), &entry) != nil || entry.Time.Before(since) {
+			continue
+		}
+		// With --root only the changes made to the root count, whose files
+		// and native commands name it
+		if rootDir != "" && !strings.Contains(entry.File+" "+strings.Join(entry.Command, " "), rootDir) {
+			continue
+		}
+		event := changeEvent{Time: entry.Time.Local(), Source: "pkgs log", Action: entry.Action, Subject: entry.File}
+		switch {
+		case entry.Command != nil:
+			event.Subject = strings.Join(entry.Command, " ")
+			if entry.ExitCode != nil && *entry.ExitCode != 0 {
+				event.Action = fmt.Sprintf("run (exit %d)", *entry.ExitCode)
+			}
+		case entry.NewFile != "":
+			event.Subject = entry.File + " -> " + entry.NewFile
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// repoConfigPaths lists the repository, key and pinning files of a package manager type
+func repoConfigPaths(pmType string) []string {
+	var patterns []string
+	switch pmType {
+	case "debian":
+		patterns = []string{
+			"/etc/apt/sources.list",
+			"/etc/apt/sources.list.d/*",
+			"/etc/apt/keyrings/*",
+			"/etc/apt/trusted.gpg.d/*",
+			"/etc/apt/preferences.d/*",
+		}
+	case "redhat":
+		patterns = []string{"/etc/yum.repos.d/*", "/etc/pki/rpm-gpg/*"}
+	case "alpine":
+		patterns = []string{"/etc/apk/repositories", "/etc/apk/keys/*", "/etc/apk/world"}
+	case "arch":
+		patterns = []string{"/etc/pacman.conf", "/etc/pacman.d/*"}
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(systemPath(pattern))
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// parseSince parses an absolute date/time or a relative period (e.g. 7d) into a point in time
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("a starting point is required, e.g. --since 2024-06-01 or --since 7d")
+	}
+
+	layouts := []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	period, err := parsePeriod(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use a date like 2024-06-01 or a period like 7d", value)
+	}
+	return now.Add(-period), nil
+}
+
+// parsePeriod parses durations, additionally accepting days (d) and weeks (w)
+func parsePeriod(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(value, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid period %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid period %q", value)
+	}
+	return d, nil
+}
+
+func init() {
+	rootCmd.AddCommand(changesCmd)
+
+	changesCmd.Flags().String("since", "", "Only show changes after this date or period (e.g. 2024-06-01, 7d)")
+	changesCmd.Flags().Bool("json", false, "Output the report as JSON")
+}
@@ -12,17 +12,14 @@ var cleanCmd = &cobra.Command{
 	Short:   "Clean package cache",
 	Long:    `Clean the package cache to free up disk space using the native package manager.`,
 	Example: `  pkgs clean`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
 		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "clean", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
-		}
+		return ExecuteCommand(pm, "clean", args)
 	},
 }
 
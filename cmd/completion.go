@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// packageNamesCacheTTL is how long the list of available package names is reused
+// by shell completion, unless the package lists are refreshed earlier
+const packageNamesCacheTTL = 24 * time.Hour
+
+// completePackageNames completes the names of available packages for install,
+// info and reinstall. Paths are completed as files, for local package files.
+func completePackageNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.ContainsRune(toComplete, os.PathSeparator) || strings.HasPrefix(toComplete, ".") {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	pm := DetectPackageManager()
+	if pm == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, err := availablePackageNames(pm)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	given := map[string]bool{}
+	for _, arg := range args {
+		given[arg] = true
+	}
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) && !given[name] {
+			matches = append(matches, name)
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// availablePackageNames returns the names of all packages in the configured
+// repositories, cached in the user's cache directory
+func availablePackageNames(pm *PackageManager) ([]string, error) {
+	cacheFile := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		cacheFile = filepath.Join(dir, "pkgs", "package-names-"+pm.Name)
+	}
+
+	// The cache is stale once it expires or the package lists are refreshed
+	if info, err := os.Stat(cacheFile); err == nil && time.Since(info.ModTime()) < packageNamesCacheTTL && info.ModTime().After(lastMetadataUpdate(pm)) {
+		if content, err := os.ReadFile(cacheFile); err == nil {
+			return strings.Fields(string(content)), nil
+		}
+	}
+
+	names, err := queryPackageNames(pm)
+	if err != nil {
+		return nil, err
+	}
+	names = uniqueSorted(names)
+
+	// The cache is pkgs' own, it is written directly rather than through
+	// writeFileContent, which skips dry runs and logs the change. Completion
+	// still works when the cache cannot be written.
+	if cacheFile != "" && os.MkdirAll(filepath.Dir(cacheFile), 0755) == nil {
+		_ = os.WriteFile(cacheFile, []byte(strings.Join(names, "\n")+"\n"), 0644)
+	}
+	return names, nil
+}
+
+// queryPackageNames asks the native package manager for all available package names
+func queryPackageNames(pm *PackageManager) ([]string, error) {
+	var outputs []string
+	var commands [][]string
+	switch pm.Name {
+	case "apt", "apt-get":
+		commands = [][]string{{"apt-cache", "pkgnames"}}
+	case "dnf":
+		// -C only uses the metadata cache, so completion never waits for the network
+		commands = [][]string{{"dnf", "-q", "-C", "repoquery", "--qf", "%{name}\n"}}
+	case "yum":
+		commands = [][]string{{"yum", "-q", "-C", "list", "all"}}
+	case "apk":
+		commands = [][]string{{"apk", "search", "-q"}}
+	case "pacman":
+		commands = [][]string{{"pacman", "-Slq"}}
+	case "brew":
+		commands = [][]string{{"brew", "formulae"}, {"brew", "casks"}}
+	default:
+		return nil, unsupportedError("listing package names is not supported for package manager '%s'", pm.Name)
+	}
+	for _, c := range commands {
+		output, err := runCommandOutput(c[0], c[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+	}
+
+	var names []string
+	for _, line := range strings.Split(strings.Join(outputs, "\n"), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		name := fields[0]
+		if pm.Name == "yum" {
+			// Output: "curl.x86_64  7.29.0-59.el7  @base", below a section header
+			if len(fields) != 3 {
+				continue
+			}
+			if i := strings.LastIndex(name, "."); i > 0 {
+				name = name[:i]
+			}
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// systemConfigFile holds the configuration shared by all users
+const systemConfigFile = "/etc/pkgs/config.yaml"
+
+// Config is the pkgs configuration. The system file is read first, the
+// user's file overrides its entries.
+type Config struct {
+	// Packages maps portable package names to the names used by a package
+	// manager (apt, dnf, ...), a distribution (ubuntu, fedora, ...) or a
+	// family (debian, redhat, alpine, arch, macos), tried in that order
+	Packages map[string]map[string]string `yaml:"packages"`
+
+	// Commands overrides the native arguments of pkgs commands, by package
+	// manager, distribution or family like Packages. An empty value makes
+	// a command unsupported.
+	Commands map[string]map[string]string `yaml:"commands"`
+
+	// HTTP configures pkgs' own downloads
+	HTTP HTTPConfig `yaml:"http"`
+
+	// Yes answers yes to all prompts by default, like -y
+	Yes *bool `yaml:"yes"`
+
+	// SystemLog also sends the transaction log to "journald" or "syslog"
+	SystemLog string `yaml:"system-log"`
+
+	// Timeout is the default of --timeout
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Interactive is "auto", or "never" for yes mode like Yes
+	Interactive string `yaml:"interactive"`
+}
+
+// nonInteractiveEnv keeps package managers and the tools they run from
+// prompting in yes mode
+var nonInteractiveEnv = map[string]string{
+	"DEBIAN_FRONTEND":          "noninteractive",
+	"APT_LISTCHANGES_FRONTEND": "none",
+	"NEEDRESTART_MODE":         "a",
+	"UCF_FORCE_CONFFOLD":       "1",
+}
+
+var (
+	configOnce   sync.Once
+	loadedConfig *Config
+	configErr    error
+)
+
+// loadConfig reads the configuration files once
+func loadConfig() (*Config, error) {
+	configOnce.Do(func() {
+		loadedConfig, configErr = readConfigFiles(configFiles())
+	})
+	return loadedConfig, configErr
+}
+
+// configFiles returns the configuration files to read: PKGS_CONFIG alone if
+// set, otherwise the system file and the user's file
+func configFiles() []string {
+	if file := os.Getenv("PKGS_CONFIG"); file != "" {
+		return []string{file}
+	}
+	files := []string{systemConfigFile}
+	if file := userConfigFile(); file != "" {
+		files = append(files, file)
+	}
+	return files
+}
+
+// userConfigFile returns pkgs/config.yaml in the user's configuration
+// directory. When pkgs was elevated with sudo, this is the directory of the
+// user who invoked it, not of root.
+func userConfigFile() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "pkgs", "config.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if name := os.Getenv("SUDO_USER"); name != "" && os.Geteuid() == 0 {
+		if u, lookupErr := user.Lookup(name); lookupErr == nil {
+			home, err = u.HomeDir, nil
+		}
+	}
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".config", "pkgs", "config.yaml")
+}
+
+// readConfigFiles merges the configuration files that exist
+func readConfigFiles(files []string) (*Config, error) {
+	config := &Config{Packages: map[string]map[string]string{}, Commands: map[string]map[string]string{}}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration %s: %v", file, err)
+		}
+
+		var fileConfig Config
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&fileConfig); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid configuration %s: %v", file, err)
+		}
+		for name, natives := range fileConfig.Packages {
+			config.Packages[name] = natives
+		}
+		switch fileConfig.Interactive {
+		case "", "auto", "never":
+		default:
+			return nil, fmt.Errorf("invalid configuration %s: interactive must be auto or never, not '%s'", file, fileConfig.Interactive)
+		}
+		if fileConfig.Interactive != "" {
+			config.Interactive = fileConfig.Interactive
+		}
+		switch fileConfig.SystemLog {
+		case "", "none", "journald", "syslog":
+		default:
+			return nil, fmt.Errorf("invalid configuration %s: system-log must be journald, syslog or none, not '%s'", file, fileConfig.SystemLog)
+		}
+		if fileConfig.SystemLog != "" {
+			config.SystemLog = fileConfig.SystemLog
+		}
+		if fileConfig.Timeout != 0 {
+			config.Timeout = fileConfig.Timeout
+		}
+		if fileConfig.Yes != nil {
+			config.Yes = fileConfig.Yes
+		}
+		// A later file only overrides the HTTP settings it sets, e.g. a user
+		// proxy keeps the system-wide certificate authorities
+		if fileConfig.HTTP.Proxy != "" {
+			config.HTTP.Proxy = fileConfig.HTTP.Proxy
+		}
+		if fileConfig.HTTP.CACert != "" {
+			config.HTTP.CACert = fileConfig.HTTP.CACert
+		}
+		if fileConfig.HTTP.ClientCert != "" {
+			config.HTTP.ClientCert = fileConfig.HTTP.ClientCert
+		}
+		if fileConfig.HTTP.ClientKey != "" {
+			config.HTTP.ClientKey = fileConfig.HTTP.ClientKey
+		}
+		if fileConfig.HTTP.InsecureSkipVerify != nil {
+			config.HTTP.InsecureSkipVerify = fileConfig.HTTP.InsecureSkipVerify
+		}
+		// The arguments root passes to the package manager must not come from
+		// a file the invoking user can write
+		if len(fileConfig.Commands) > 0 && os.Geteuid() == 0 && file != systemConfigFile {
+			fmt.Fprintf(os.Stderr, "Ignoring commands: in %s, as root only %s can override native arguments\n", file, systemConfigFile)
+			fileConfig.Commands = nil
+		}
+		for key, commands := range fileConfig.Commands {
+			if config.Commands[key] == nil {
+				config.Commands[key] = map[string]string{}
+			}
+			for command, native := range commands {
+				config.Commands[key][command] = native
+			}
+		}
+	}
+	return config, nil
+}
+
+// neverInteractive reports whether the configuration forbids prompts
+func (c *Config) neverInteractive() bool {
+	return c.Interactive == "never"
+}
+
+// setNonInteractiveEnv exports the variables of nonInteractiveEnv that are
+// not set yet, for all commands pkgs runs
+func setNonInteractiveEnv() {
+	for name, value := range nonInteractiveEnv {
+		if _, found := os.LookupEnv(name); !found {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+// nativeNames returns the names a portable package name stands for on this
+// system. A mapping may list several packages separated by spaces, or none
+// for a package this system does not need.
+func (c *Config) nativeNames(pm *PackageManager, name string) []string {
+	natives, ok := c.Packages[name]
+	if !ok {
+		return []string{name}
+	}
+	distro, _ := distroInfo()
+	for _, key := range []string{pm.Name, distro, pm.Type} {
+		if native, ok := natives[key]; ok && key != "" {
+			return strings.Fields(native)
+		}
+	}
+	return []string{name}
+}
+
+// overrideCommands replaces the command mappings of a package manager by
+// the configured ones, the family's first, then the distribution's and the
+// package manager's
+func (c *Config) overrideCommands(pm *PackageManager) {
+	if len(c.Commands) == 0 {
+		return
+	}
+	distro, _ := distroInfo()
+	for _, key := range []string{pm.Type, distro, pm.Name} {
+		commands, ok := c.Commands[key]
+		if !ok || key == "" {
+			continue
+		}
+		for command, native := range commands {
+			if args := strings.Fields(native); len(args) > 0 {
+				pm.Commands[command] = args
+			} else {
+				delete(pm.Commands, command)
+			}
+		}
+	}
+}
+
+// translatePackageNames replaces portable package names by the native ones
+// configured under "packages:", keeping version pins such as name@1.24
+func translatePackageNames(pm *PackageManager, args []string) ([]string, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Packages) == 0 {
+		return args, nil
+	}
+
+	result := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "@") || isLocalPackageFile(pm, arg) {
+			result = append(result, arg)
+			continue
+		}
+		name, _, _ := splitVersionPin(pm, arg)
+		pin := arg[len(name):]
+		natives := config.nativeNames(pm, name)
+		if len(natives) > 1 && pin != "" {
+			return nil, fmt.Errorf("%s stands for several packages (%s) and cannot be pinned to a version", name, strings.Join(natives, ", "))
+		}
+		for _, native := range natives {
+			result = append(result, native+pin)
+		}
+	}
+	return result, nil
+}
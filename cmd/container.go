@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// containerFlag names the running container pkgs manages packages in
+var containerFlag string
+
+// containerRuntime is docker or podman once the container was found, native
+// commands then run in the container
+var containerRuntime string
+
+// containerRuntimes are looked for the container, in order
+var containerRuntimes = []string{"docker", "podman"}
+
+// containerCommands only run the package manager, so they work in a
+// container. The commands editing repository files and keys, or reading
+// state pkgs keeps on the host, are not supported there.
+var containerCommands = []string{
+	"autoremove", "clean", "depends", "files", "groups", "info", "install",
+	"installed", "list", "orphans", "outdated", "owns", "raw", "reinstall",
+	"remove", "search", "update", "upgrade", "which", "why",
+}
+
+// inContainer reports whether native commands run in a container
+func inContainer() bool {
+	return containerRuntime != "" && !remoteRun
+}
+
+// startContainer finds the container given with --container and checks
+// that the command can run in it
+func startContainer(command string) error {
+	if containerFlag == "" || remoteRun {
+		return nil
+	}
+	if IsUserMode() {
+		return fmt.Errorf("--container cannot be combined with --user")
+	}
+	if !slices.Contains(containerCommands, command) {
+		return unsupportedError("'%s' is not supported with --container, only commands running the package manager are", command)
+	}
+	runtime, err := findContainerRuntime(containerFlag)
+	if err != nil {
+		return err
+	}
+	containerRuntime = runtime
+	return nil
+}
+
+// findContainerRuntime returns the runtime running a container
+func findContainerRuntime(name string) (string, error) {
+	found := false
+	for _, runtime := range containerRuntimes {
+		if _, err := exec.LookPath(runtime); err != nil {
+			continue
+		}
+		found = true
+		output, err := runCommandOutput(runtime, "container", "inspect", "--format", "{{.State.Running}}", name)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(output) != "true" {
+			return "", fmt.Errorf("container '%s' is not running", name)
+		}
+		return runtime, nil
+	}
+	if !found {
+		return "", fmt.Errorf("--container needs docker or podman, neither is installed")
+	}
+	return "", fmt.Errorf("no container named '%s' found with %s", name, strings.Join(containerRuntimes, " or "))
+}
+
+// containerExecArgs returns the runtime arguments running a native command
+// as root in the container. No terminal is allocated, so that the output of
+// queries can be parsed; prompts are still answered on stdin. The variables
+// keeping tools from prompting in yes mode are passed on.
+func containerExecArgs(name string, args []string) []string {
+	execArgs := []string{"exec", "-i", "-u", "0"}
+	for _, variable := range slices.Sorted(maps.Keys(nonInteractiveEnv)) {
+		if value, found := os.LookupEnv(variable); found {
+			execArgs = append(execArgs, "-e", variable+"="+value)
+		}
+	}
+	execArgs = append(execArgs, containerFlag, name)
+	return append(execArgs, args...)
+}
+
+// lookPath checks that a native tool is installed on the system packages
+// are managed on
+func lookPath(name string) error {
+	if !inContainer() {
+		_, err := exec.LookPath(name)
+		return err
+	}
+	_, err := runCommandOutput("sh", "-c", `command -v "$1"`, "sh", name)
+	return err
+}
+
+// readSystemFile reads a file of the system packages are managed on
+func readSystemFile(path string) (string, error) {
+	if !inContainer() {
+		return readFileContent(path)
+	}
+	content, err := runCommandOutput("cat", path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s in container %s: %v", path, containerFlag, err)
+	}
+	return content, nil
+}
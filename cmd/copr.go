@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// coprHub is the COPR instance of the Fedora project
+const coprHub = "copr.fedorainfracloud.org"
+
+// parseCopr splits a "copr:owner/project" repository name. Group projects
+// have owners starting with "@".
+func parseCopr(name string) (owner, project string, ok bool) {
+	rest, found := strings.CutPrefix(name, "copr:")
+	if !found {
+		return "", "", false
+	}
+	owner, project, found = strings.Cut(rest, "/")
+	if !found || owner == "" || project == "" || strings.Contains(project, "/") {
+		return "", "", false
+	}
+	return owner, project, true
+}
+
+// coprRepoID returns the repository ID the dnf copr plugin uses, so that
+// repositories added either way can be managed both ways
+func coprRepoID(owner, project string) string {
+	if group, ok := strings.CutPrefix(owner, "@"); ok {
+		owner = "group_" + group
+	}
+	return fmt.Sprintf("copr:%s:%s:%s", coprHub, owner, project)
+}
+
+// coprRepoFile returns the path of the .repo file of a COPR project
+func coprRepoFile(owner, project string) string {
+	return filepath.Join(getRepoConfig("redhat").baseDir, "_"+coprRepoID(owner, project)+".repo")
+}
+
+// hasDnfCopr reports whether the copr command of dnf-plugins-core is available
+func hasDnfCopr(pm *PackageManager) bool {
+	if pm.Name != "dnf" {
+		return false
+	}
+	_, err := runCommandOutput(pm.Bin, "copr", "--help")
+	return err == nil
+}
+
+// coprChroot returns the COPR build target of the system, e.g. "fedora-41"
+// or "epel-9" for RHEL and its rebuilds
+func coprChroot() (string, error) {
+	distro, version := distroInfo()
+	if distro == "fedora" {
+		return "fedora-" + version, nil
+	}
+	major, _, _ := strings.Cut(version, ".")
+	if major == "" {
+		return "", fmt.Errorf("cannot determine the release from /etc/os-release")
+	}
+	return "epel-" + major, nil
+}
+
+// addCopr enables a COPR project with 'dnf copr', or by downloading its .repo
+// file if the plugin is not installed
+func addCopr(pm *PackageManager, owner, project string) error {
+	if hasDnfCopr(pm) {
+		return executeConfirmed(pm.Bin, "copr", "enable", "-y", owner+"/"+project)
+	}
+
+	chroot, err := coprChroot()
+	if err != nil {
+		return err
+	}
+	path := owner
+	if group, ok := strings.CutPrefix(owner, "@"); ok {
+		path = "g/" + group
+	}
+	url := fmt.Sprintf("https://%s/coprs/%s/%s/repo/%s/dnf.repo", coprHub, path, project, chroot)
+
+	repoFile := coprRepoFile(owner, project)
+	if fileExists(repoFile) {
+		fmt.Printf("Repository already exists in %s\n", repoFile)
+		return nil
+	}
+	if err := confirmPlan(planStep{"download", url}, planStep{"write", repoFile}); err != nil {
+		return err
+	}
+	if err := ensureDirExists(filepath.Dir(repoFile)); err != nil {
+		return err
+	}
+	if IsDryRun() {
+		fmt.Printf("Would download %s to %s\n", url, repoFile)
+		return nil
+	}
+	fmt.Printf("Downloading repository file from %s...\n", url)
+	if err := downloadFile(url, repoFile); err != nil {
+		return fmt.Errorf("failed to download repository file (does %s/%s build for %s?): %v", owner, project, chroot, err)
+	}
+	if content, err := readFileContent(repoFile); err == nil {
+		logFileChange("write", repoFile, "", content)
+	}
+	fmt.Printf("Repository file added to %s\n", repoFile)
+	return nil
+}
+
+// setCoprEnabled enables or disables a COPR project
+func setCoprEnabled(pm *PackageManager, owner, project string, enable bool) error {
+	if hasDnfCopr(pm) {
+		action := "disable"
+		if enable {
+			action = "enable"
+		}
+		return executeConfirmed(pm.Bin, "copr", action, owner+"/"+project)
+	}
+
+	repoFile := coprRepoFile(owner, project)
+	content, err := readFileContent(repoFile)
+	if err != nil {
+		return err
+	}
+	newContent := setRepoEnabled(content, coprRepoID(owner, project), enable)
+	if newContent == content {
+		fmt.Printf("Repository copr:%s/%s is already %s\n", owner, project, strings.ToLower(statusLabel(enable)))
+		return nil
+	}
+	if err := confirmPlan(planStep{"write", repoFile}); err != nil {
+		return err
+	}
+	if err := writeFileContent(repoFile, newContent, 0644); err != nil {
+		return err
+	}
+	verb := "disabled"
+	if enable {
+		verb = "enabled"
+	}
+	fmt.Printf("Successfully %s copr:%s/%s in %s\n", verb, owner, project, repoFile)
+	return nil
+}
+
+// removeCopr removes a COPR project
+func removeCopr(pm *PackageManager, owner, project string) error {
+	if hasDnfCopr(pm) {
+		return executeNative(pm.Bin, "copr", "remove", owner+"/"+project)
+	}
+
+	repoFile := coprRepoFile(owner, project)
+	if err := removeFile(repoFile); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("repository copr:%s/%s not found (no %s)", owner, project, repoFile)
+		}
+		return fmt.Errorf("failed to remove %s: %v", repoFile, err)
+	}
+	fmt.Printf("Removed %s\n", repoFile)
+	return nil
+}
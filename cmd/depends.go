@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// dependsCmd represents the depends command
+var dependsCmd = &cobra.Command{
+	Use:     "depends package",
+	Aliases: []string{"deps"},
+	Short:   "Show the dependencies of a package",
+	Long: `Show the packages a package depends on using the native package manager.
+
+By default the direct dependencies are listed one per line. Use --tree to
+follow the dependencies recursively; packages that were already expanded
+elsewhere in the tree are marked with (*) instead of being repeated.
+
+For apt-based systems (Debian/Ubuntu):
+  apt-cache depends package
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf repoquery --requires --resolve package
+
+For Alpine Linux:
+  apk info -R package
+
+For Arch Linux:
+  pactree package (from pacman-contrib)
+
+For Homebrew (macOS):
+  brew deps --direct package`,
+	Example: `  pkgs depends curl
+  pkgs depends --tree curl
+  pkgs depends --tree --depth 2 nginx`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		tree, _ := cmd.Flags().GetBool("tree")
+		depth, _ := cmd.Flags().GetInt("depth")
+
+		lister, err := dependencyLister(pm, tree)
+		if err != nil {
+			return err
+		}
+		return printDependencies(args[0], lister, tree, depth)
+	},
+}
+
+// dependencyLister returns a function listing the direct dependencies of a
+// package. With recursive set, backends that can resolve the whole tree in
+// one query do so on the first call and answer later calls from memory.
+func dependencyLister(pm *PackageManager, recursive bool) (func(string) ([]string, error), error) {
+	switch pm.Type {
+	case "debian":
+		graph := map[string][]string{}
+		return func(name string) ([]string, error) {
+			if deps, ok := graph[name]; ok {
+				return deps, nil
+			}
+			args := []string{"depends", "--no-recommends", "--no-suggests", "--no-conflicts",
+				"--no-breaks", "--no-replaces", "--no-enhances"}
+			if recursive {
+				args = append(args, "--recurse")
+			}
+			output, err := runCommandOutput("apt-cache", append(args, name)...)
+			if err != nil {
+				return nil, err
+			}
+			for pkg, deps := range splitAptDependsBlocks(output) {
+				graph[pkg] = parseAptDepends(deps, "Depends:", "PreDepends:")
+			}
+			return graph[name], nil
+		}, nil
+	case "redhat":
+		return func(name string) ([]string, error) {
+			return dnfRepoquery(pm, "--requires", "--resolve", name)
+		}, nil
+	case "alpine":
+		return func(name string) ([]string, error) {
+			output, err := runCommandOutput("apk", "info", "-qR", name)
+			if err != nil {
+				return nil, err
+			}
+			return parseApkDepends(output), nil
+		}, nil
+	case "arch":
+		return func(name string) ([]string, error) {
+			return pactreeDirect(name)
+		}, nil
+	case "macos":
+		return func(name string) ([]string, error) {
+			output, err := runCommandOutput("brew", "deps", "--direct", name)
+			if err != nil {
+				return nil, err
+			}
+			return strings.Fields(output), nil
+		}, nil
+	default:
+		return nil, unsupportedError("listing dependencies is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// parseAptDepends parses "apt-cache depends/rdepends" output, keeping the
+// lines of the given relation kinds; virtual packages lose their <> markers
+func parseAptDepends(output string, kinds ...string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		// Alternatives are prefixed with "|"
+		line = strings.TrimLeft(strings.TrimSpace(line), "|")
+		for _, kind := range kinds {
+			value, found := strings.CutPrefix(line, kind)
+			if !found {
+				continue
+			}
+			name := strings.Trim(strings.TrimSpace(value), "<>")
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// splitAptDependsBlocks splits "apt-cache depends/rdepends --recurse" output
+// into the relation lines of each package. Package names start at the first column.
+func splitAptDependsBlocks(output string) map[string]string {
+	blocks := map[string]string{}
+	current := ""
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" || line == "Reverse Depends:" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "|") {
+			current = strings.Trim(line, "<>")
+			blocks[current] += ""
+			continue
+		}
+		blocks[current] += line + "\n"
+	}
+	return blocks
+}
+
+// dnfRepoquery runs a repoquery relation query and returns the package names.
+// The query arguments name the package themselves, as a positional argument or
+// as the value of an option like --whatrequires.
+func dnfRepoquery(pm *PackageManager, queryArgs ...string) ([]string, error) {
+	bin, args := pm.Bin, []string{"repoquery", "-q"}
+	if pm.Name == "yum" {
+		// yum provides repoquery as a separate tool from yum-utils
+		bin, args = "repoquery", []string{"-q"}
+	}
+	args = append(append(args, "--qf", "%{name}\n"), queryArgs...)
+
+	output, err := runCommandOutput(bin, args...)
+	if err != nil {
+		return nil, err
+	}
+	return uniqueSorted(strings.Fields(output)), nil
+}
+
+// parseApkDepends parses "apk info -qR" output, dropping version constraints
+func parseApkDepends(output string) []string {
+	var names []string
+	for _, line := range strings.Fields(output) {
+		if i := strings.IndexAny(line, "=<>~"); i > 0 {
+			line = line[:i]
+		}
+		names = append(names, line)
+	}
+	return uniqueSorted(names)
+}
+
+// pactreeDirect lists the direct dependencies (or dependents with "-r") of a
+// package using pactree, whose linear output starts with the package itself
+func pactreeDirect(name string, extraArgs ...string) ([]string, error) {
+	if err := lookPath("pactree"); err != nil {
+		return nil, fmt.Errorf("pactree is not installed (install the pacman-contrib package)")
+	}
+	args := append([]string{"-lu", "-d", "1"}, extraArgs...)
+	output, err := runCommandOutput("pactree", append(args, name)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for i, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if fields := strings.Fields(line); i > 0 && len(fields) > 0 {
+			names = append(names, fields[0])
+		}
+	}
+	return names, nil
+}
+
+// uniqueSorted returns the distinct values of names in sorted order
+func uniqueSorted(names []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// printDependencies prints the relations of root as a list or, with tree set,
+// recursively as a tree limited to depth levels (0 means unlimited)
+func printDependencies(root string, lister func(string) ([]string, error), tree bool, depth int) error {
+	direct, err := lister(root)
+	if err != nil {
+		return fmt.Errorf("failed to query package '%s': %v", root, err)
+	}
+
+	defer startPager()()
+
+	if !tree {
+		for _, name := range direct {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	fmt.Println(root)
+	cache := map[string][]string{root: direct}
+	expanded := map[string]bool{root: true}
+	printTreeLevel(direct, "", 1, depth, lister, cache, expanded)
+	return nil
+}
+
+// printTreeLevel prints one level of a dependency tree with box-drawing
+// connectors. Every package is expanded only once.
+func printTreeLevel(names []string, prefix string, level, depth int, lister func(string) ([]string, error),
+	cache map[string][]string, expanded map[string]bool) {
+	for i, name := range names {
+		connector, childPrefix := "├── ", prefix+"│   "
+		if i == len(names)-1 {
+			connector, childPrefix = "└── ", prefix+"    "
+		}
+
+		if expanded[name] {
+			fmt.Printf("%s%s%s (*)\n", prefix, connector, name)
+			continue
+		}
+		fmt.Printf("%s%s%s\n", prefix, connector, name)
+		if depth > 0 && level >= depth {
+			continue
+		}
+		expanded[name] = true
+
+		children, ok := cache[name]
+		if !ok {
+			// Virtual names and unresolvable entries are shown as leaves
+			children, _ = lister(name)
+			cache[name] = children
+		}
+		printTreeLevel(children, childPrefix, level+1, depth, lister, cache, expanded)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(dependsCmd)
+
+	dependsCmd.Flags().Bool("tree", false, "Show dependencies recursively as a tree")
+	dependsCmd.Flags().Int("depth", 0, "Maximum tree depth (0 for unlimited)")
+}
@@ -1,9 +1,5 @@
 package cmd
 
-import (
-	"os/exec"
-)
-
 // PackageManager represents a system package manager
 type PackageManager struct {
 	Name     string
@@ -12,35 +8,34 @@ type PackageManager struct {
 	Commands map[string][]string
 }
 
-// DetectPackageManager identifies which package manager is available on the system
+// DetectPackageManager identifies which package manager is available on the
+// system, with the command mappings overridden in the configuration
 func DetectPackageManager() *PackageManager {
-	// Check for Homebrew (macOS)
-	if _, err := exec.LookPath("brew"); err == nil {
-		return &PackageManager{
-			Name: "brew",
-			Bin:  "brew",
-			Type: "macos",
-			Commands: map[string][]string{
-				"install":      {"install"},
-				"reinstall":    {"reinstall"},
-				"remove":       {"uninstall"},
-				"update":       {"update"},
-				"upgrade":      {"upgrade"},
-				"search":       {"search"},
-				"info":         {"info"},
-				"autoremove":   {"autoremove"},
-				"clean":        {"cleanup"},
-				"add-repo":     {"tap"},
-				"add-key":      {""},
-				"enable-repo":  {""},
-				"disable-repo": {""},
-				"list-repos":   {""},
-			},
+	pm := detectPackageManager()
+	if pm != nil {
+		detectedBackend = pm.Name
+		// Configuration errors are reported before any command runs
+		if config, err := loadConfig(); err == nil {
+			config.overrideCommands(pm)
 		}
 	}
+	return pm
+}
+
+// detectPackageManager identifies the native package manager
+func detectPackageManager() *PackageManager {
+	// In user mode only package managers that need no root are considered
+	if IsUserMode() {
+		return detectUserPackageManager()
+	}
+
+	// Check for Homebrew (macOS)
+	if err := lookPath("brew"); err == nil {
+		return brewPackageManager()
+	}
 
 	// Check for apt (Debian/Ubuntu)
-	if _, err := exec.LookPath("apt"); err == nil {
+	if err := lookPath("apt"); err == nil {
 		return &PackageManager{
 			Name: "apt",
 			Bin:  "apt",
@@ -65,7 +60,7 @@ func DetectPackageManager() *PackageManager {
 	}
 
 	// Check for apt-get (older Debian/Ubuntu)
-	if _, err := exec.LookPath("apt-get"); err == nil {
+	if err := lookPath("apt-get"); err == nil {
 		return &PackageManager{
 			Name: "apt-get",
 			Bin:  "apt-get",
@@ -90,7 +85,7 @@ func DetectPackageManager() *PackageManager {
 	}
 
 	// Check for dnf (Fedora/RHEL/CentOS)
-	if _, err := exec.LookPath("dnf"); err == nil {
+	if err := lookPath("dnf"); err == nil {
 		return &PackageManager{
 			Name: "dnf",
 			Bin:  "dnf",
@@ -115,7 +110,7 @@ func DetectPackageManager() *PackageManager {
 	}
 
 	// Check for yum (older Fedora/RHEL/CentOS)
-	if _, err := exec.LookPath("yum"); err == nil {
+	if err := lookPath("yum"); err == nil {
 		return &PackageManager{
 			Name: "yum",
 			Bin:  "yum",
@@ -140,7 +135,7 @@ func DetectPackageManager() *PackageManager {
 	}
 
 	// Check for apk (Alpine Linux)
-	if _, err := exec.LookPath("apk"); err == nil {
+	if err := lookPath("apk"); err == nil {
 		return &PackageManager{
 			Name: "apk",
 			Bin:  "apk",
@@ -165,7 +160,7 @@ func DetectPackageManager() *PackageManager {
 	}
 
 	// Check for pacman (Arch Linux)
-	if _, err := exec.LookPath("pacman"); err == nil {
+	if err := lookPath("pacman"); err == nil {
 		return &PackageManager{
 			Name: "pacman",
 			Bin:  "pacman",
@@ -178,7 +173,7 @@ func DetectPackageManager() *PackageManager {
 				"upgrade":      {"-Syu"},
 				"search":       {"-Ss"},
 				"info":         {"-Qi"},
-				"autoremove":   {"-Rs", "$(pacman -Qdtq)"},
+				"autoremove":   {"-Rns"},
 				"clean":        {"-Sc"},
 				"add-repo":     {""},
 				"add-key":      {""},
@@ -191,3 +186,28 @@ func DetectPackageManager() *PackageManager {
 
 	return nil
 }
+
+// brewPackageManager describes Homebrew, which also runs without root on Linux
+func brewPackageManager() *PackageManager {
+	return &PackageManager{
+		Name: "brew",
+		Bin:  "brew",
+		Type: "macos",
+		Commands: map[string][]string{
+			"install":      {"install"},
+			"reinstall":    {"reinstall"},
+			"remove":       {"uninstall"},
+			"update":       {"update"},
+			"upgrade":      {"upgrade"},
+			"search":       {"search"},
+			"info":         {"info"},
+			"autoremove":   {"autoremove"},
+			"clean":        {"cleanup"},
+			"add-repo":     {"tap"},
+			"add-key":      {""},
+			"enable-repo":  {""},
+			"disable-repo": {""},
+			"list-repos":   {""},
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ManifestDifference is one difference between a manifest and the system
+type ManifestDifference struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff manifest",
+	Short: "Compare the system with a package manifest",
+	Long: `Compare the system with a manifest written by 'pkgs freeze' and show the
+differences without changing anything:
+
+  missing      packages in the manifest that are not installed
+  extra        explicitly installed packages that are not in the manifest
+  version      packages installed in another version than recorded
+  not-held     packages the manifest holds that are not held
+  missing-repo repositories in the manifest that are not configured
+  missing-key  keys in the manifest that are not installed
+
+pkgs exits with an error if there are differences, so the command can be used
+to detect configuration drift.`,
+	Example: `  pkgs diff packages.yaml
+  pkgs diff --json packages.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		manifest, err := readManifest(args[0])
+		if err != nil {
+			return err
+		}
+		if err := checkManifestBackend(pm, manifest); err != nil {
+			return err
+		}
+		comparison, err := compareManifest(pm, manifest)
+		if err != nil {
+			return err
+		}
+		differences := comparison.differences()
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := printJSON(differences); err != nil {
+				return err
+			}
+		} else if len(differences) == 0 {
+			fmt.Println("The system matches the manifest.")
+		} else {
+			defer startPager()()
+			rows := make([][]string, 0, len(differences))
+			for _, d := range differences {
+				rows = append(rows, []string{d.Kind, d.Name, d.Detail})
+			}
+			printTable([]string{"KIND", "NAME", "DETAIL"}, rows)
+		}
+
+		if len(differences) > 0 {
+			return fmt.Errorf("%d difference(s) found", len(differences))
+		}
+		return nil
+	},
+}
+
+// differences lists every difference of the comparison
+func (c manifestComparison) differences() []ManifestDifference {
+	differences := []ManifestDifference{}
+	for _, key := range c.MissingKeys {
+		differences = append(differences, ManifestDifference{Kind: "missing-key", Name: key.Name, Detail: key.URL})
+	}
+	for _, repo := range c.MissingRepos {
+		differences = append(differences, ManifestDifference{Kind: "missing-repo", Name: repo.Name, Detail: repo.URL})
+	}
+	for _, name := range c.MissingPackages {
+		differences = append(differences, ManifestDifference{Kind: "missing", Name: name})
+	}
+	for _, d := range c.Drifted {
+		differences = append(differences, ManifestDifference{
+			Kind:   "version",
+			Name:   d.Name,
+			Detail: fmt.Sprintf("installed %s, manifest %s", d.Installed, d.Wanted),
+		})
+	}
+	for _, name := range c.NotHeld {
+		differences = append(differences, ManifestDifference{Kind: "not-held", Name: name})
+	}
+	for _, name := range c.Extra {
+		differences = append(differences, ManifestDifference{Kind: "extra", Name: name})
+	}
+	return differences
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().Bool("json", false, "Output results as JSON")
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// digestReport is the data rendered by the digest command
+type digestReport struct {
+	Host     string
+	Backend  string
+	From     time.Time
+	To       time.Time
+	Sections []digestSection
+	Warnings []string
+}
+
+// digestSection is one titled table of the digest
+type digestSection struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+}
+
+// digestCmd represents the digest command
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Generate a human-readable summary of recent package activity",
+	Long: `Generate a summary of package activity over a period, suitable for emailing
+or posting to a wiki.
+
+The digest lists upgrades applied, other package installs and removals, and
+repository configuration changes, based on the same sources as 'pkgs changes',
+followed by the updates that are still pending (see 'pkgs outdated') and the
+vulnerabilities published during the period that affect installed packages
+(see 'pkgs audit').`,
+	Example: `  pkgs digest
+  pkgs digest --period 30d --output html > digest.html`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		periodFlag, _ := cmd.Flags().GetString("period")
+		period, err := parsePeriod(periodFlag)
+		if err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("output")
+		if format != "md" && format != "html" {
+			return fmt.Errorf("unsupported output format '%s' (use md or html)", format)
+		}
+
+		report := buildDigest(pm, time.Now(), period)
+		if format == "html" {
+			return renderDigestHTML(report)
+		}
+		renderDigestMarkdown(report)
+		return nil
+	},
+}
+
+// buildDigest collects the data for a digest covering the given period
+func buildDigest(pm *PackageManager, now time.Time, period time.Duration) digestReport {
+	host, _ := os.Hostname()
+	report := digestReport{
+		Host:    host,
+		Backend: pm.Name,
+		From:    now.Add(-period),
+		To:      now,
+	}
+
+	events, warnings := collectChanges(pm, report.From)
+	report.Warnings = append(report.Warnings, warnings...)
+
+	columns := []string{"Time", "Action", "Subject", "Version"}
+	upgrades := digestSection{Title: "Upgrades applied", Columns: columns}
+	others := digestSection{Title: "Other package changes", Columns: columns}
+	repos := digestSection{Title: "Repository changes", Columns: columns}
+	for _, e := range events {
+		row := []string{e.Time.Format("2006-01-02 15:04"), e.Action, e.Subject, e.Version}
+		switch {
+		case e.Source == "repo config":
+			repos.Rows = append(repos.Rows, row)
+		case e.Action == "upgrade":
+			upgrades.Rows = append(upgrades.Rows, row)
+		default:
+			others.Rows = append(others.Rows, row)
+		}
+	}
+	report.Sections = append(report.Sections, upgrades, others, repos)
+
+	// Pending updates reflect the package lists as of the last 'pkgs update'
+	pending := digestSection{Title: "Pending updates", Columns: []string{"Package", "Current", "Candidate", "Repo"}}
+	outdated, err := listOutdated(pm)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("pending updates are not included: %v", err))
+	}
+	for _, p := range outdated {
+		pending.Rows = append(pending.Rows, []string{p.Name, p.Current, p.Candidate, p.Repo})
+	}
+	report.Sections = append(report.Sections, pending)
+
+	// Vulnerabilities published during the period that affect installed packages
+	newVulns := digestSection{Title: "New vulnerabilities", Columns: []string{"Published", "Severity", "Package", "ID", "Fixed in"}}
+	vulns, err := auditInstalled(pm)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("new vulnerabilities are not included: %v", err))
+	}
+	for _, v := range vulns {
+		if v.Published.Before(report.From) {
+			continue
+		}
+		newVulns.Rows = append(newVulns.Rows, []string{v.Published.Format("2006-01-02"), v.Severity, v.Package, v.displayID(), v.Fixed})
+	}
+	report.Sections = append(report.Sections, newVulns)
+	return report
+}
+
+// renderDigestMarkdown writes the digest as Markdown
+func renderDigestMarkdown(r digestReport) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Package digest for %s\n\n", r.Host)
+	fmt.Fprintf(&b, "Period: %s to %s (package manager: %s)\n\n", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"), r.Backend)
+
+	for _, section := range r.Sections {
+		fmt.Fprintf(&b, "## %s (%d)\n\n", section.Title, len(section.Rows))
+		if len(section.Rows) == 0 {
+			b.WriteString("None.\n\n")
+			continue
+		}
+		fmt.Fprintf(&b, "| %s |\n|%s\n", strings.Join(section.Columns, " | "), strings.Repeat("---|", len(section.Columns)))
+		for _, row := range section.Rows {
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(row, " | "))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Warnings) > 0 {
+		b.WriteString("## Notes\n\n")
+		for _, w := range r.Warnings {
+			fmt.Fprintf(&b, "- %s\n", w)
+		}
+	}
+	fmt.Print(b.String())
+}
+
+// digestHTMLTemplate renders the digest as a standalone HTML page
+var digestHTMLTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("2006-01-02") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Package digest for {{.Host}}</title></head>
+<body>
+<h1>Package digest for {{.Host}}</h1>
+<p>Period: {{date .From}} to {{date .To}} (package manager: {{.Backend}})</p>
+{{range .Sections}}<h2>{{.Title}} ({{len .Rows}})</h2>
+{{if .Rows}}<table border="1" cellpadding="4">
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{else}}<p>None.</p>{{end}}
+{{end}}{{if .Warnings}}<h2>Notes</h2>
+<ul>{{range .Warnings}}<li>{{.}}</li>{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+// renderDigestHTML writes the digest as HTML
+func renderDigestHTML(r digestReport) error {
+	return digestHTMLTemplate.Execute(os.Stdout, r)
+}
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+
+	digestCmd.Flags().String("period", "7d", "Period covered by the digest (e.g. 7d, 2w, 48h)")
+	digestCmd.Flags().String("output", "md", "Output format: md or html")
+}
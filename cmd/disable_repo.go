@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -17,86 +16,114 @@ var disableRepoCmd = &cobra.Command{
 
 For apt-based systems (Debian/Ubuntu):
   pkgs disable-repo name
-  Disables a repository by commenting out entries in /etc/apt/sources.list.d/name.list
+  Disables a repository by commenting out entries in /etc/apt/sources.list.d/name.list,
+  or by setting 'Enabled: no' in /etc/apt/sources.list.d/name.sources
+
+  pkgs disable-repo --match text
+  Comments out the one-line entries containing text in /etc/apt/sources.list
+  and the .list files, e.g. all backports entries, after showing them
 
 For dnf/yum-based systems (Fedora/RHEL/CentOS):
   pkgs disable-repo name
-  Sets 'enabled=0' in the repository file in /etc/yum.repos.d/
+  Sets 'enabled=0' in the repository file in /etc/yum.repos.d/,
+  or uses 'dnf copr disable' for COPR projects given as copr:owner/project
 
 For Alpine Linux:
   pkgs disable-repo name
-  Comments out the repository in /etc/apk/repositories`,
+  Comments out the repository in /etc/apk/repositories
+
+For Arch Linux:
+  pkgs disable-repo name
+  Comments out the [name] section and its options in /etc/pacman.conf`,
 	Example: `  # Disable a repository for apt-based systems
   pkgs disable-repo nodesource
 
+  # Disable the backports entries of /etc/apt/sources.list
+  pkgs disable-repo --match backports
+
   # Disable a repository for dnf/yum-based systems
   pkgs disable-repo docker-ce
 
   # Disable a repository for Alpine Linux
-  pkgs disable-repo edge-testing`,
-	Run: func(cmd *cobra.Command, args []string) {
+  pkgs disable-repo edge-testing
+
+  # Disable a repository for Arch Linux
+  pkgs disable-repo multilib`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
+		}
+
+		// Individual entries of the one-line files are selected by a pattern
+		if pattern, _ := cmd.Flags().GetString("match"); pattern != "" {
+			if len(args) != 0 {
+				return fmt.Errorf("give either a repository name or --match")
+			}
+			if pm.Type != "debian" {
+				return unsupportedError("--match is only supported for apt-based systems")
+			}
+			return setAptLinesEnabled(pattern, false)
 		}
 
 		// Check arguments
 		if len(args) != 1 {
-			fmt.Println("Error: Repository name is required.")
-			fmt.Println("Usage: pkgs disable-repo name")
-			return
+			return fmt.Errorf("repository name is required (usage: pkgs disable-repo name)")
 		}
 		name := args[0]
 
 		// Disable repository based on package manager
 		switch pm.Type {
 		case "debian":
-			if err := disableRepoApt(name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+			return disableRepoApt(name)
 		case "redhat":
-			if err := disableRepoDnfYum(name); err != nil {
-				fmt.Printf("Error: %v\n", err)
+			if owner, project, ok := parseCopr(name); ok {
+				return setCoprEnabled(pm, owner, project, false)
 			}
+			return disableRepoDnfYum(name)
 		case "alpine":
-			if err := disableRepoAlpine(name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+			return disableRepoAlpine(name)
 		case "arch":
-			fmt.Println("For Arch Linux, you need to manually edit /etc/pacman.conf to disable repositories.")
+			return setRepoEnabledPacman(name, false)
 		case "macos":
 			fmt.Println("For Homebrew, you can use 'brew untap' to remove a tap completely.")
 			fmt.Println("There is no direct way to disable a tap while keeping it installed.")
-		default:
-			fmt.Println("Disabling repositories is not supported for this package manager.")
 		}
+
+		// The remaining backends only print guidance for doing this manually
+		return unsupportedError("disabling repositories is not supported for package manager '%s'", pm.Name)
 	},
 }
 
 // disableRepoApt disables a repository for apt-based systems
 func disableRepoApt(name string) error {
-	// Check for the repository file
-	repoPath := filepath.Join("/etc/apt/sources.list.d", name+".list")
-	if _, err := os.Stat(repoPath); os.IsNotExist(err) {
-		return fmt.Errorf("repository file %s does not exist", repoPath)
+	repoPath, err := findAptSourceFile(name)
+	if err != nil {
+		return err
 	}
 
 	// Read the repository file
-	content, err := os.ReadFile(repoPath)
+	content, err := os.ReadFile(systemPath(repoPath))
 	if err != nil {
 		return fmt.Errorf("failed to read repository file: %v", err)
 	}
 
-	// Comment out all non-commented lines
-	lines := strings.Split(string(content), "\n")
+	var newContent string
 	modified := false
-	for i, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		if trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#") {
-			lines[i] = "# " + line
-			modified = true
+	if strings.HasSuffix(repoPath, ".sources") {
+		// deb822 files have a field for this instead of comments
+		newContent, modified = setDeb822Enabled(string(content), false)
+	} else {
+		// Comment out all non-commented lines
+		lines := strings.Split(string(content), "\n")
+		for i, line := range lines {
+			trimmedLine := strings.TrimSpace(line)
+			if trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#") {
+				lines[i] = "# " + line
+				modified = true
+			}
 		}
+		newContent = strings.Join(lines, "\n")
 	}
 
 	if !modified {
@@ -104,9 +131,12 @@ func disableRepoApt(name string) error {
 		return nil
 	}
 
+	if err := confirmPlan(planStep{"write", repoPath}); err != nil {
+		return err
+	}
 	// Write the modified content back
-	if err := os.WriteFile(repoPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
-		return fmt.Errorf("failed to write repository file: %v", err)
+	if err := writeFileContent(repoPath, newContent, 0644); err != nil {
+		return err
 	}
 
 	fmt.Printf("Successfully disabled repository in %s\n", repoPath)
@@ -118,12 +148,17 @@ func disableRepoApt(name string) error {
 func disableRepoDnfYum(name string) error {
 	config := getRepoConfig("redhat")
 
-	repoFile, found, err := findRepoFile(config.baseDir, config.fileExtension, name)
+	var scanErrs fileErrors
+	repoFile, found, err := findRepoFile(config.baseDir, config.fileExtension, name, &scanErrs)
 	if err != nil {
 		return err
 	}
 
 	if !found {
+		// The repository may be defined in one of the files that could not be read
+		if err := scanErrs.err(); err != nil {
+			return fmt.Errorf("no repository with ID '%s' found in the readable files of %s: %w", name, config.baseDir, err)
+		}
 		return fmt.Errorf("no repository with ID '%s' found in %s", name, config.baseDir)
 	}
 
@@ -135,23 +170,26 @@ func disableRepoDnfYum(name string) error {
 	newContent := setRepoEnabled(content, name, false)
 	if newContent == content {
 		fmt.Printf("Repository '%s' is already disabled\n", name)
-		return nil
+		return scanErrs.err()
 	}
 
+	if err := confirmPlan(planStep{"write", repoFile}); err != nil {
+		return err
+	}
 	if err := writeFileContent(repoFile, newContent, 0644); err != nil {
 		return err
 	}
 
 	fmt.Printf("Successfully disabled repository '%s' in %s\n", name, repoFile)
 	fmt.Println("Run 'pkgs update' to update the package lists.")
-	return nil
+	return scanErrs.err()
 }
 
 // disableRepoAlpine disables a repository for Alpine Linux
 func disableRepoAlpine(name string) error {
 	// Read the repositories file
 	repoFile := "/etc/apk/repositories"
-	content, err := os.ReadFile(repoFile)
+	content, err := os.ReadFile(systemPath(repoFile))
 	if err != nil {
 		return fmt.Errorf("failed to read repositories file: %v", err)
 	}
@@ -171,9 +209,12 @@ func disableRepoAlpine(name string) error {
 		return fmt.Errorf("repository %s not found or already disabled", name)
 	}
 
+	if err := confirmPlan(planStep{"write", repoFile}); err != nil {
+		return err
+	}
 	// Write the modified content back
-	if err := os.WriteFile(repoFile, []byte(strings.Join(lines, "\n")), 0644); err != nil {
-		return fmt.Errorf("failed to write repositories file: %v", err)
+	if err := writeFileContent(repoFile, strings.Join(lines, "\n"), 0644); err != nil {
+		return err
 	}
 
 	fmt.Printf("Successfully disabled repository %s in %s\n", name, repoFile)
@@ -183,4 +224,6 @@ func disableRepoAlpine(name string) error {
 
 func init() {
 	rootCmd.AddCommand(disableRepoCmd)
+
+	disableRepoCmd.Flags().String("match", "", "Disable the apt entries containing this text, in sources.list and all .list files")
 }
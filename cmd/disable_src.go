@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// disableSrcCmd represents the disable-src command
+var disableSrcCmd = &cobra.Command{
+	Use:   "disable-src [name]",
+	Short: "Disable the source package repositories",
+	Long: `Disable the deb-src repositories turned on with 'pkgs enable-src', for all
+repositories or only the named one.
+
+For apt-based systems (Debian/Ubuntu):
+  In one-line .list files the deb-src entries are commented out. In deb822
+  .sources files deb-src is removed from the Types field.`,
+	Example: `  # Disable the source repositories of all repositories
+  pkgs disable-src
+
+  # Disable the source repository of a single repository
+  pkgs disable-src nodesource`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+		if pm.Type != "debian" {
+			return unsupportedError("source repositories are only supported for apt-based systems, not for package manager '%s'", pm.Name)
+		}
+
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		return setAptSourcesEnabled(name, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(disableSrcCmd)
+}
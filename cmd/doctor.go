@@ -0,0 +1,426 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// staleMetadataAge is the age after which package lists are reported as stale
+	staleMetadataAge = 7 * 24 * time.Hour
+	// lowDiskSpace is the free space below which a filesystem is reported
+	lowDiskSpace = 1 << 30
+)
+
+// DoctorCheck is one finding of the doctor command
+type DoctorCheck struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the package manager setup for common problems",
+	Long: `Run a series of health checks and suggest fixes for the problems found:
+
+  metadata   package lists that were not refreshed for a week or never
+  packages   broken or half-installed packages (dpkg --audit, dnf check,
+             pacman -Dk, brew missing)
+  locks      lock files held by a running or crashed package manager
+  repos      repositories that are defined more than once
+  keys       signing keys referenced by a repository that do not exist
+  disk       filesystems with less than 1 GiB free
+
+pkgs exits with an error if any check reports a warning or an error.`,
+	Example: `  pkgs doctor
+  pkgs doctor --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		checks := runDoctorChecks(pm)
+
+		problems := 0
+		for _, c := range checks {
+			if c.Status != "ok" {
+				problems++
+			}
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := printJSON(checks); err != nil {
+				return err
+			}
+		} else {
+			rows := make([][]string, 0, len(checks))
+			var fixes []string
+			for _, c := range checks {
+				status := colorize(c.Status, colorYellow)
+				if c.Status == "ok" {
+					status = colorize(c.Status, colorGreen)
+				}
+				rows = append(rows, []string{c.Check, status, c.Detail})
+				if c.Fix != "" {
+					fixes = append(fixes, c.Fix)
+				}
+			}
+			printTable([]string{"CHECK", "STATUS", "DETAIL"}, rows)
+
+			if len(fixes) > 0 {
+				fmt.Println("\nSuggested fixes:")
+				for _, fix := range fixes {
+					fmt.Printf("  - %s\n", fix)
+				}
+			}
+		}
+
+		if problems > 0 {
+			return fmt.Errorf("%d problem(s) found", problems)
+		}
+		return nil
+	},
+}
+
+// runDoctorChecks runs every health check for the package manager
+func runDoctorChecks(pm *PackageManager) []DoctorCheck {
+	var checks []DoctorCheck
+	checks = append(checks, checkMetadataAge(pm)...)
+	checks = append(checks, checkBrokenPackages(pm)...)
+	checks = append(checks, checkLocks(pm)...)
+	checks = append(checks, checkDuplicateRepos(pm)...)
+	checks = append(checks, checkRepoKeys(pm)...)
+	checks = append(checks, checkDiskSpace(pm)...)
+	return checks
+}
+
+// checkMetadataAge reports package lists that were not refreshed recently
+func checkMetadataAge(pm *PackageManager) []DoctorCheck {
+	if pm.Type == "macos" {
+		// Homebrew refreshes itself before installing or upgrading
+		return []DoctorCheck{{Check: "metadata", Status: "ok", Detail: "refreshed automatically by " + pm.Name}}
+	}
+
+	newest := lastMetadataUpdate(pm)
+	fix := "run 'pkgs update' to refresh the package lists"
+	if newest.IsZero() {
+		return []DoctorCheck{{Check: "metadata", Status: "warning", Detail: "package lists have never been downloaded", Fix: fix}}
+	}
+	age := time.Since(newest)
+	detail := fmt.Sprintf("package lists refreshed %s ago", formatAge(age))
+	if age > staleMetadataAge {
+		return []DoctorCheck{{Check: "metadata", Status: "warning", Detail: detail, Fix: fix}}
+	}
+	return []DoctorCheck{{Check: "metadata", Status: "ok", Detail: detail}}
+}
+
+// lastMetadataUpdate returns when the package lists were last downloaded, or
+// the zero time if that is unknown
+func lastMetadataUpdate(pm *PackageManager) time.Time {
+	var patterns []string
+	switch pm.Type {
+	case "debian":
+		// apt always touches the partial directory; the stamp is written by update-notifier
+		patterns = []string{"/var/lib/apt/periodic/update-success-stamp", "/var/lib/apt/lists/partial"}
+	case "redhat":
+		patterns = []string{"/var/cache/dnf/*/repodata", "/var/cache/libdnf5/*/repodata", "/var/cache/yum/*/*/repomd.xml"}
+	case "alpine":
+		patterns = []string{"/var/cache/apk/APKINDEX.*.tar.gz"}
+	case "arch":
+		patterns = []string{"/var/lib/pacman/sync/*.db"}
+	case "macos":
+		patterns = []string{"/opt/homebrew/.git/FETCH_HEAD", "/usr/local/Homebrew/.git/FETCH_HEAD"}
+	}
+
+	var newest time.Time
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			if info, err := os.Stat(path); err == nil && info.ModTime().After(newest) {
+				newest = info.ModTime()
+			}
+		}
+	}
+	return newest
+}
+
+// formatAge renders a duration in the largest sensible unit
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+}
+
+// checkBrokenPackages runs the native consistency check of the package database
+func checkBrokenPackages(pm *PackageManager) []DoctorCheck {
+	var name, fix string
+	var args []string
+	switch pm.Type {
+	case "debian":
+		name, args = "dpkg", []string{"--audit"}
+		fix = "run 'dpkg --configure -a' and 'apt-get install -f' to finish interrupted installations"
+	case "redhat":
+		name, args = pm.Bin, []string{"-q", "check"}
+		fix = fmt.Sprintf("review '%s check' and reinstall or remove the affected packages", pm.Bin)
+	case "arch":
+		name, args = "pacman", []string{"-Dk"}
+		fix = "review 'pacman -Dk' and install the missing dependencies"
+	case "macos":
+		name, args = "brew", []string{"missing"}
+		fix = "run 'brew install' for the missing dependencies, see also 'brew doctor'"
+	default:
+		return []DoctorCheck{{Check: "packages", Status: "ok", Detail: "no consistency check available for " + pm.Name}}
+	}
+
+	// The checks print problems and exit non-zero when they find any
+	output, err := runCommandOutput(name, args...)
+	output = strings.TrimSpace(output)
+	if pm.Type == "arch" && err == nil {
+		output = ""
+	}
+	if output == "" {
+		if err != nil {
+			return []DoctorCheck{{Check: "packages", Status: "error", Detail: fmt.Sprintf("%s %s failed: %v", name, strings.Join(args, " "), err)}}
+		}
+		return []DoctorCheck{{Check: "packages", Status: "ok", Detail: "no broken packages"}}
+	}
+
+	lines := strings.Split(output, "\n")
+	detail := fmt.Sprintf("%s %s reported %d line(s), starting with: %s", name, strings.Join(args, " "), len(lines), strings.TrimSpace(lines[0]))
+	return []DoctorCheck{{Check: "packages", Status: "error", Detail: detail, Fix: fix}}
+}
+
+// checkLocks reports lock files held by another process or left behind by a crash
+func checkLocks(pm *PackageManager) []DoctorCheck {
+	var checks []DoctorCheck
+	switch pm.Type {
+	case "debian":
+		for _, path := range []string{"/var/lib/dpkg/lock-frontend", "/var/lib/dpkg/lock", "/var/lib/apt/lists/lock", "/var/cache/apt/archives/lock"} {
+			if pid := fcntlLockHolder(path); pid > 0 {
+				checks = append(checks, DoctorCheck{
+					Check:  "locks",
+					Status: "warning",
+					Detail: fmt.Sprintf("%s is held by %s", path, describeProcess(pid)),
+					Fix:    "wait for the running package manager (e.g. unattended-upgrades) to finish",
+				})
+			}
+		}
+	case "redhat":
+		pidFiles, _ := filepath.Glob("/var/cache/dnf/*lock.pid")
+		more, _ := filepath.Glob("/var/lib/dnf/*lock.pid")
+		for _, path := range append(append(pidFiles, more...), "/var/run/yum.pid") {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			pid, _ := strconv.Atoi(strings.TrimSpace(string(content)))
+			if processRunning(pid) {
+				checks = append(checks, DoctorCheck{
+					Check:  "locks",
+					Status: "warning",
+					Detail: fmt.Sprintf("%s is held by %s", path, describeProcess(pid)),
+					Fix:    fmt.Sprintf("wait for the running %s process to finish", pm.Bin),
+				})
+			} else {
+				checks = append(checks, DoctorCheck{
+					Check:  "locks",
+					Status: "warning",
+					Detail: fmt.Sprintf("stale lock %s (process %d is gone)", path, pid),
+					Fix:    fmt.Sprintf("remove %s", path),
+				})
+			}
+		}
+	case "alpine":
+		if path := "/lib/apk/db/lock"; flockHeld(path) {
+			checks = append(checks, DoctorCheck{
+				Check:  "locks",
+				Status: "warning",
+				Detail: path + " is held by another apk process",
+				Fix:    "wait for the running apk process to finish",
+			})
+		}
+	case "arch":
+		// pacman uses the existence of the file as its lock
+		if path := "/var/lib/pacman/db.lck"; fileExists(path) {
+			checks = append(checks, DoctorCheck{
+				Check:  "locks",
+				Status: "warning",
+				Detail: path + " exists",
+				Fix:    fmt.Sprintf("if no pacman process is running, remove %s", path),
+			})
+		}
+	}
+
+	if len(checks) == 0 {
+		checks = append(checks, DoctorCheck{Check: "locks", Status: "ok", Detail: "no package manager locks held"})
+	}
+	return checks
+}
+
+// describeProcess renders a PID with its command name when it can be read from /proc
+func describeProcess(pid int) string {
+	if comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid)); err == nil {
+		return fmt.Sprintf("PID %d (%s)", pid, strings.TrimSpace(string(comm)))
+	}
+	return fmt.Sprintf("PID %d", pid)
+}
+
+// checkDuplicateRepos reports enabled repositories that are configured more than once
+func checkDuplicateRepos(pm *PackageManager) []DoctorCheck {
+	if pm.Type == "macos" {
+		return []DoctorCheck{{Check: "repos", Status: "ok", Detail: "no repository files to check"}}
+	}
+	entries, err := listRepos(pm)
+
+	var checks []DoctorCheck
+	if err != nil {
+		checks = append(checks, DoctorCheck{Check: "repos", Status: "error", Detail: err.Error()})
+	}
+
+	// Repositories are identified by their ID on dnf/yum and pacman, and by
+	// their full definition on apt and apk
+	seen := map[string]string{}
+	for _, e := range entries {
+		if !e.Enabled {
+			continue
+		}
+		key := strings.TrimSuffix(e.URL, "/")
+		if pm.Type == "redhat" || pm.Type == "arch" {
+			key = e.ID
+		}
+		first, duplicate := seen[key]
+		if !duplicate {
+			seen[key] = e.File
+			continue
+		}
+		checks = append(checks, DoctorCheck{
+			Check:  "repos",
+			Status: "warning",
+			Detail: fmt.Sprintf("%s is defined in %s and %s", key, first, e.File),
+			Fix:    fmt.Sprintf("remove the duplicate definition from %s", e.File),
+		})
+	}
+
+	if len(checks) == 0 {
+		checks = append(checks, DoctorCheck{Check: "repos", Status: "ok", Detail: fmt.Sprintf("%d repositories, no duplicates", len(entries))})
+	}
+	return checks
+}
+
+// checkRepoKeys reports signing keys referenced by repositories that do not exist
+func checkRepoKeys(pm *PackageManager) []DoctorCheck {
+	var missing [][2]string // key path, repository file
+	switch pm.Type {
+	case "debian":
+		files, _ := aptSourceFiles()
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			for _, src := range parseAptSources(file, string(content)) {
+				if !src.Enabled {
+					continue
+				}
+				// signed-by may also hold fingerprints, only paths are checked
+				for _, key := range strings.Split(src.Options["signed-by"], ",") {
+					if strings.HasPrefix(key, "/") && !fileExists(key) {
+						missing = append(missing, [2]string{key, file})
+					}
+				}
+			}
+		}
+	case "redhat":
+		gpgkeyPattern := regexp.MustCompile(`(?m)^gpgkey\s*=\s*(.*)$`)
+		files, _ := filepath.Glob("/etc/yum.repos.d/*.repo")
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			for _, section := range extractAllRepoSections(string(content)) {
+				if strings.Contains(section.content, "enabled=0") {
+					continue
+				}
+				for _, match := range gpgkeyPattern.FindAllStringSubmatch(section.content, -1) {
+					for _, key := range strings.Fields(strings.ReplaceAll(match[1], ",", " ")) {
+						path, local := strings.CutPrefix(key, "file://")
+						if local && !fileExists(path) {
+							missing = append(missing, [2]string{path, file})
+						}
+					}
+				}
+			}
+		}
+	default:
+		return []DoctorCheck{{Check: "keys", Status: "ok", Detail: "repository keys are managed by " + pm.Name}}
+	}
+
+	if len(missing) == 0 {
+		return []DoctorCheck{{Check: "keys", Status: "ok", Detail: "all referenced keys exist"}}
+	}
+	checks := make([]DoctorCheck, 0, len(missing))
+	for _, m := range missing {
+		checks = append(checks, DoctorCheck{
+			Check:  "keys",
+			Status: "error",
+			Detail: fmt.Sprintf("%s referenced by %s does not exist", m[0], m[1]),
+			Fix:    fmt.Sprintf("import the key with 'pkgs add-key' or remove the repository in %s", m[1]),
+		})
+	}
+	return checks
+}
+
+// checkDiskSpace reports filesystems holding packages that are low on free space
+func checkDiskSpace(pm *PackageManager) []DoctorCheck {
+	paths := []string{"/", "/var"}
+	if pm.Type == "macos" {
+		paths = []string{"/"}
+	}
+
+	var checks []DoctorCheck
+	seen := map[uint64]bool{}
+	for _, path := range paths {
+		free, total, err := diskSpace(path)
+		if err != nil {
+			checks = append(checks, DoctorCheck{Check: "disk", Status: "warning", Detail: fmt.Sprintf("cannot check %s: %v", path, err)})
+			continue
+		}
+		// /var is usually on the root filesystem
+		if seen[total] {
+			continue
+		}
+		seen[total] = true
+
+		detail := fmt.Sprintf("%s: %s free of %s", path, formatSize(int64(free)), formatSize(int64(total)))
+		if free < lowDiskSpace {
+			checks = append(checks, DoctorCheck{Check: "disk", Status: "warning", Detail: detail, Fix: "free up space, e.g. with 'pkgs clean' and 'pkgs autoremove'"})
+		} else {
+			checks = append(checks, DoctorCheck{Check: "disk", Status: "ok", Detail: detail})
+		}
+	}
+	return checks
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().Bool("json", false, "Output results as JSON")
+}
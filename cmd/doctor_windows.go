@@ -0,0 +1,19 @@
+//go:build windows
+
+package cmd
+
+import "errors"
+
+// fcntlLockHolder always reports no lock on Windows, where no supported package manager runs
+func fcntlLockHolder(path string) int { return 0 }
+
+// flockHeld always reports no lock on Windows
+func flockHeld(path string) bool { return false }
+
+// processRunning is not needed on Windows and always reports false
+func processRunning(pid int) bool { return false }
+
+// diskSpace is not implemented on Windows
+func diskSpace(path string) (free, total uint64, err error) {
+	return 0, 0, errors.New("disk space check is not supported on Windows")
+}
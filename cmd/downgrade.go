@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// packageVersion is one version of a package that can be installed
+type packageVersion struct {
+	Version   string
+	Source    string
+	Installed bool
+}
+
+// pacmanCacheDir is where pacman keeps downloaded packages
+const pacmanCacheDir = "/var/cache/pacman/pkg"
+
+// downgradeCmd represents the downgrade command
+var downgradeCmd = &cobra.Command{
+	Use:   "downgrade package[=version]",
+	Short: "Downgrade a package to a previous or specified version",
+	Long: `Downgrade a package to the given version, or to the newest version older
+than the installed one if no version is given. Use --list to show the
+versions that are available.
+
+For apt-based systems (Debian/Ubuntu):
+  apt install --allow-downgrades package=version (versions from apt-cache madison)
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf downgrade package[-version] (versions from dnf list --showduplicates)
+
+For Alpine Linux:
+  apk add package=version (versions from apk policy); a version is required
+
+For Arch Linux:
+  pacman -U with a package file from /var/cache/pacman/pkg
+
+For Homebrew (macOS):
+  brew install package@version, for formulae that have versioned variants`,
+	Example: `  pkgs downgrade curl
+  pkgs downgrade curl=7.88.1-10+deb12u5
+  pkgs downgrade --list curl`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+		name, version, _ := strings.Cut(args[0], "=")
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			versions, err := availableVersions(pm, name)
+			if err != nil {
+				return err
+			}
+			if len(versions) == 0 {
+				fmt.Printf("No versions of %s found.\n", name)
+				return nil
+			}
+			rows := make([][]string, 0, len(versions))
+			for _, v := range versions {
+				rows = append(rows, []string{v.Version, yesNo(v.Installed), v.Source})
+			}
+			printTable([]string{"VERSION", "INSTALLED", "SOURCE"}, rows)
+			return nil
+		}
+
+		fmt.Printf("Using package manager: %s\n", pm.Name)
+		switch pm.Type {
+		case "debian":
+			return downgradeApt(pm, name, version)
+		case "redhat":
+			target := name
+			if version != "" {
+				target = name + "-" + version
+			}
+			fullCmd := []string{"downgrade"}
+			addYesFlagIfNeeded(pm, &fullCmd)
+			return executeNative(pm.Bin, append(fullCmd, target)...)
+		case "alpine":
+			if version == "" {
+				return fmt.Errorf("apk only knows the versions in the configured repositories, specify one with %s=version (see 'pkgs downgrade --list %s')", name, name)
+			}
+			return executeNative("apk", "add", name+"="+version)
+		case "arch":
+			return downgradePacman(pm, name, version)
+		case "macos":
+			if version == "" {
+				return unsupportedError("Homebrew keeps no older versions, specify a versioned formula with %s=version (see 'pkgs downgrade --list %s')", name, name)
+			}
+			return executeNative("brew", "install", name+"@"+version)
+		default:
+			return unsupportedError("downgrading packages is not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+// availableVersions lists the versions of a package the package manager can install
+func availableVersions(pm *PackageManager, name string) ([]packageVersion, error) {
+	var versions []packageVersion
+	switch pm.Type {
+	case "debian":
+		// Output: "curl | 7.88.1-10+deb12u5 | http://deb.debian.org/debian bookworm/main amd64 Packages"
+		output, err := runCommandOutput("apt-cache", "madison", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query versions of %s: %v", name, err)
+		}
+		installed := dpkgVersion(name)
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Split(line, "|")
+			if len(fields) != 3 || strings.TrimSpace(fields[0]) != name {
+				continue
+			}
+			v := strings.TrimSpace(fields[1])
+			versions = append(versions, packageVersion{Version: v, Source: strings.TrimSpace(fields[2]), Installed: v == installed})
+		}
+	case "redhat":
+		// Output: "curl.x86_64  7.76.1-26.el9  @baseos" with "@" marking the installed package
+		output, err := runCommandOutput(pm.Bin, "-q", "list", "--showduplicates", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query versions of %s: %v", name, err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 3 || !strings.HasPrefix(fields[0], name+".") {
+				continue
+			}
+			installed := strings.HasPrefix(fields[2], "@") || fields[2] == "installed"
+			versions = append(versions, packageVersion{Version: fields[1], Source: strings.TrimPrefix(fields[2], "@"), Installed: installed})
+		}
+	case "alpine":
+		// Output: "curl policy:\n  8.5.0-r0:\n    lib/apk/db/installed\n    https://..."
+		output, err := runCommandOutput("apk", "policy", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query versions of %s: %v", name, err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "    ") && strings.HasSuffix(trimmed, ":"):
+				versions = append(versions, packageVersion{Version: strings.TrimSuffix(trimmed, ":")})
+			case len(versions) > 0 && trimmed == "lib/apk/db/installed":
+				versions[len(versions)-1].Installed = true
+			case len(versions) > 0 && trimmed != "" && versions[len(versions)-1].Source == "":
+				versions[len(versions)-1].Source = trimmed
+			}
+		}
+	case "arch":
+		installed := ""
+		if output, err := runCommandOutput("pacman", "-Q", name); err == nil {
+			if fields := strings.Fields(output); len(fields) == 2 {
+				installed = fields[1]
+			}
+		}
+		for _, file := range pacmanCachedPackages(name) {
+			v := pacmanFileVersion(name, file)
+			versions = append(versions, packageVersion{Version: v, Source: file, Installed: v == installed})
+		}
+	case "macos":
+		output, err := runCommandOutput("brew", "search", "/^"+regexp.QuoteMeta(name)+"@/")
+		if err != nil {
+			return nil, fmt.Errorf("failed to search versioned formulae of %s: %v", name, err)
+		}
+		for _, result := range parseBrewSearch(output) {
+			if _, v, found := strings.Cut(result.Name, "@"); found {
+				versions = append(versions, packageVersion{Version: v, Source: result.Name, Installed: result.Installed})
+			}
+		}
+	default:
+		return nil, unsupportedError("listing package versions is not supported for package manager '%s'", pm.Name)
+	}
+	return versions, nil
+}
+
+// downgradeApt installs an older version with apt, picking the newest version
+// below the installed one if none is given
+func downgradeApt(pm *PackageManager, name, version string) error {
+	if version == "" {
+		installed := dpkgVersion(name)
+		if installed == "" {
+			return fmt.Errorf("package '%s' is not installed", name)
+		}
+		versions, err := availableVersions(pm, name)
+		if err != nil {
+			return err
+		}
+		version = newestOlderVersion(versions, installed, func(a, b string) bool {
+			return runCommand("dpkg", "--compare-versions", a, "lt", b) == nil
+		})
+		if version == "" {
+			return fmt.Errorf("no version of %s older than %s is available", name, installed)
+		}
+	}
+
+	fullCmd := []string{"install", "--allow-downgrades"}
+	addYesFlagIfNeeded(pm, &fullCmd)
+	return executeNative(pm.Bin, append(fullCmd, name+"="+version)...)
+}
+
+// downgradePacman installs an older version from the pacman package cache
+func downgradePacman(pm *PackageManager, name, version string) error {
+	versions, err := availableVersions(pm, name)
+	if err != nil {
+		return err
+	}
+
+	if version == "" {
+		installed := ""
+		for _, v := range versions {
+			if v.Installed {
+				installed = v.Version
+			}
+		}
+		if installed == "" {
+			return fmt.Errorf("package '%s' is not installed or not in %s", name, pacmanCacheDir)
+		}
+		version = newestOlderVersion(versions, installed, func(a, b string) bool {
+			output, err := runCommandOutput("vercmp", a, b)
+			return err == nil && strings.HasPrefix(strings.TrimSpace(output), "-")
+		})
+		if version == "" {
+			return fmt.Errorf("no version of %s older than %s is in %s", name, installed, pacmanCacheDir)
+		}
+	}
+
+	for _, v := range versions {
+		if v.Version == version {
+			fullCmd := []string{"-U"}
+			addYesFlagIfNeeded(pm, &fullCmd)
+			return executeNative("pacman", append(fullCmd, v.Source)...)
+		}
+	}
+	return fmt.Errorf("version %s of %s is not in %s", version, name, pacmanCacheDir)
+}
+
+// newestOlderVersion returns the highest version below installed, using less
+// to compare versions the way the package manager does
+func newestOlderVersion(versions []packageVersion, installed string, less func(a, b string) bool) string {
+	best := ""
+	for _, v := range versions {
+		if !less(v.Version, installed) {
+			continue
+		}
+		if best == "" || less(best, v.Version) {
+			best = v.Version
+		}
+	}
+	return best
+}
+
+// pacmanCachedPackages returns the cached package files of a package, whose
+// names look like "name-version-release-arch.pkg.tar.zst"
+func pacmanCachedPackages(name string) []string {
+	files, _ := filepath.Glob(filepath.Join(pacmanCacheDir, name+"-*.pkg.tar*"))
+	var result []string
+	for _, file := range files {
+		// Skip signatures and packages whose name merely starts with the same prefix
+		if !strings.HasSuffix(file, ".sig") && pacmanFileVersion(name, file) != "" {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// pacmanFileVersion returns the "version-release" part of a cached package file
+// name, or "" if the file belongs to a different package
+func pacmanFileVersion(name, file string) string {
+	base := filepath.Base(file)
+	base = base[:strings.Index(base, ".pkg.tar")]
+	parts := strings.Split(strings.TrimPrefix(base, name+"-"), "-")
+	// What remains must be exactly version, release and architecture
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[0] + "-" + parts[1]
+}
+
+func init() {
+	rootCmd.AddCommand(downgradeCmd)
+
+	downgradeCmd.Flags().Bool("list", false, "List the versions that are available")
+}
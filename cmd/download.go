@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// downloadCmd represents the download command
+var downloadCmd = &cobra.Command{
+	Use:     "download [packages...]",
+	Aliases: []string{"fetch"},
+	Short:   "Download packages without installing them",
+	Long: `Download package files into a directory without installing them, for
+example to transfer them to an air-gapped system or to inspect them.
+
+With --deps the dependencies are downloaded as well. apt and pacman only
+fetch the dependencies that are not installed on this system.
+
+For apt-based systems (Debian/Ubuntu):
+  apt-get download package, or apt-get install --download-only with --deps
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf download --destdir dir [--resolve] package (yumdownloader on yum)
+
+For Alpine Linux:
+  apk fetch -o dir [-R] package
+
+For Arch Linux:
+  pacman -Sw --cachedir dir package
+
+For Homebrew (macOS):
+  brew fetch [--deps] package; files stay in the Homebrew cache`,
+	Example: `  pkgs download nginx
+  pkgs download --deps --dir /tmp/offline nginx`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		dirFlag, _ := cmd.Flags().GetString("dir")
+		deps, _ := cmd.Flags().GetBool("deps")
+		dir, err := filepath.Abs(dirFlag)
+		if err != nil {
+			return fmt.Errorf("invalid directory %s: %v", dirFlag, err)
+		}
+		if pm.Type != "macos" {
+			if err := ensureDirExists(dir); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Using package manager: %s\n", pm.Name)
+		switch pm.Type {
+		case "debian":
+			if !deps {
+				// apt-get download always writes to the current directory
+				fmt.Printf("Executing: apt-get download %s (in %s)\n", strings.Join(args, " "), dir)
+				c := newCommand("apt-get", append([]string{"download"}, args...)...)
+				prepareCommand(c)
+				c.Dir = dir
+				return c.Run()
+			}
+			// apt needs the partial directory to exist inside a custom archive directory
+			if err := ensureDirExists(filepath.Join(dir, "partial")); err != nil {
+				return err
+			}
+			fullCmd := []string{"install", "--download-only", "-o", "Dir::Cache::archives=" + dir}
+			addYesFlagIfNeeded(pm, &fullCmd)
+			err := executeNative("apt-get", append(fullCmd, args...)...)
+			os.Remove(filepath.Join(dir, "partial"))
+			return err
+		case "redhat":
+			bin, fullCmd := pm.Bin, []string{"download", "--destdir", dir}
+			if pm.Name == "yum" {
+				bin, fullCmd = "yumdownloader", []string{"--destdir", dir}
+			}
+			if deps {
+				fullCmd = append(fullCmd, "--resolve")
+			}
+			return executeNative(bin, append(fullCmd, args...)...)
+		case "alpine":
+			fullCmd := []string{"fetch", "-o", dir}
+			if deps {
+				fullCmd = append(fullCmd, "-R")
+			}
+			return executeNative("apk", append(fullCmd, args...)...)
+		case "arch":
+			fullCmd := []string{"-Sw", "--cachedir", dir}
+			if !deps {
+				// Skip dependency resolution entirely
+				fullCmd = append(fullCmd, "--nodeps", "--nodeps")
+			}
+			addYesFlagIfNeeded(pm, &fullCmd)
+			return executeNative("pacman", append(fullCmd, args...)...)
+		case "macos":
+			fullCmd := []string{"fetch"}
+			if deps {
+				fullCmd = append(fullCmd, "--deps")
+			}
+			if err := executeNative("brew", append(fullCmd, args...)...); err != nil {
+				return err
+			}
+			fmt.Println("Homebrew keeps downloads in its cache, see 'brew --cache'.")
+			return nil
+		default:
+			return unsupportedError("downloading packages is not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(downloadCmd)
+
+	downloadCmd.Flags().String("dir", ".", "Directory to download the packages to")
+	downloadCmd.Flags().Bool("deps", false, "Also download dependencies")
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// dryRunFlag only shows what a command would do, see IsDryRun
+var dryRunFlag bool
+
+// IsDryRun checks if commands only show what they would do. Package
+// operations run in the simulation mode of the package manager, repository
+// and key commands print the files they would write or remove.
+func IsDryRun() bool {
+	return dryRunFlag
+}
+
+// simulatedCommands are the package operations ExecuteCommand simulates
+var simulatedCommands = []string{"install", "reinstall", "remove", "upgrade", "autoremove", "clean"}
+
+// simulationArgs returns the native arguments of a command in the simulation
+// mode of the package manager, or false if it has none:
+//
+//	apt:    -s
+//	dnf:    --assumeno, which shows the transaction and aborts it
+//	apk:    -s
+//	pacman: --print, without refreshing the databases
+//	brew:   --dry-run, for install, upgrade, autoremove and cleanup
+func simulationArgs(pm *PackageManager, command string, cmdArgs []string) ([]string, bool) {
+	if !slices.Contains(simulatedCommands, command) || len(cmdArgs) == 0 {
+		return nil, false
+	}
+	switch pm.Type {
+	case "debian", "alpine":
+		return append([]string{"-s"}, cmdArgs...), true
+	case "redhat":
+		if command == "clean" {
+			return nil, false
+		}
+		return append([]string{"--assumeno"}, cmdArgs...), true
+	case "arch":
+		if command == "clean" {
+			return nil, false
+		}
+		// -Syu would refresh the databases before printing
+		operation := strings.ReplaceAll(cmdArgs[0], "y", "")
+		return append([]string{operation, "--print"}, cmdArgs[1:]...), true
+	case "macos":
+		switch cmdArgs[0] {
+		case "install", "upgrade", "autoremove", "cleanup":
+			return append(append([]string{}, cmdArgs...), "--dry-run"), true
+		}
+	}
+	return nil, false
+}
+
+// printDryRun announces a command that a dry run does not execute
+func printDryRun(name string, args ...string) {
+	fmt.Printf("Would run: %s %s\n", name, strings.Join(args, " "))
+}
+
+// printDryRunWrite shows the content a dry run does not write to a file
+func printDryRunWrite(path, content string) {
+	if !utf8.ValidString(content) || strings.ContainsRune(content, 0) {
+		fmt.Printf("Would write %s (%d bytes of binary data)\n", path, len(content))
+		return
+	}
+	fmt.Printf("Would write %s:\n", path)
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+// removeFile removes a file, or only announces it in a dry run
+func removeFile(path string) error {
+	path = systemPath(path)
+	if IsDryRun() {
+		fmt.Printf("Would remove %s\n", path)
+		return nil
+	}
+	old, _ := os.ReadFile(path)
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	logFileChange("remove", path, string(old), "")
+	return nil
+}
+
+// renameFile renames a file, or only announces it in a dry run
+func renameFile(oldPath, newPath string) error {
+	oldPath, newPath = systemPath(oldPath), systemPath(newPath)
+	if IsDryRun() {
+		fmt.Printf("Would rename %s to %s\n", oldPath, newPath)
+		return nil
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	logTransaction(TransactionLogEntry{Action: "rename", File: oldPath, NewFile: newPath})
+	return nil
+}
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// editRepoCmd represents the edit-repo command
+var editRepoCmd = &cobra.Command{
+	Use:   "edit-repo name",
+	Short: "Edit a repository definition in an editor",
+	Long: `Open the file defining a repository in $VISUAL or $EDITOR (vi if neither is
+set). The edited copy is checked before it replaces the original, so a typo
+cannot break the next update; if the check fails the copy can be edited again
+or discarded. The original is replaced in one step, never left half written.
+
+For apt-based systems (Debian/Ubuntu):
+  Edits /etc/apt/sources.list.d/name.list or name.sources, or
+  /etc/apt/sources.list for the name "sources.list"
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  Edits the file in /etc/yum.repos.d/ defining the repository
+
+For Alpine Linux:
+  Edits /etc/apk/repositories, the name is ignored
+
+For Arch Linux:
+  Edits /etc/pacman.conf, the name is ignored`,
+	Example: `  pkgs edit-repo nodesource
+  EDITOR=nano pkgs edit-repo docker-ce`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		path, err := repoDefinitionFile(pm, args[0])
+		if err != nil {
+			return err
+		}
+		return editRepoFile(path, repoValidator(pm, path))
+	},
+}
+
+// repoDefinitionFile returns the file defining a repository
+func repoDefinitionFile(pm *PackageManager, name string) (string, error) {
+	switch pm.Type {
+	case "debian":
+		if name == "sources.list" {
+			return "/etc/apt/sources.list", nil
+		}
+		return findAptSourceFile(name)
+	case "redhat":
+		config := getRepoConfig("redhat")
+		var scanErrs fileErrors
+		repoFile, found, err := findRepoFile(config.baseDir, config.fileExtension, name, &scanErrs)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("no repository with ID '%s' found in %s", name, config.baseDir)
+		}
+		return repoFile, nil
+	case "alpine":
+		return "/etc/apk/repositories", nil
+	case "arch":
+		return pacmanConf, nil
+	default:
+		return "", unsupportedError("editing repositories is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// repoValidator returns the check for the syntax of a repository file
+func repoValidator(pm *PackageManager, path string) func(string) []string {
+	switch {
+	case pm.Type == "debian" && strings.HasSuffix(path, ".sources"):
+		return validateDeb822Sources
+	case pm.Type == "debian":
+		return validateAptList
+	case pm.Type == "redhat":
+		return validateRepoFile
+	case pm.Type == "alpine":
+		return validateApkRepositories
+	default:
+		return validatePacmanConf
+	}
+}
+
+// editRepoFile lets the user edit a copy of path and replaces path with it
+// once validate reports no problems
+func editRepoFile(path string, validate func(string) []string) error {
+	original, err := readFileContent(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	// Keep the extension so that editors pick the right syntax highlighting
+	tmp, err := os.CreateTemp("", "pkgs-edit-*"+filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Close()
+
+	content := original
+	for {
+		if err := writeFileContent(tmp.Name(), content, 0600); err != nil {
+			return err
+		}
+		if err := runEditor(tmp.Name()); err != nil {
+			return err
+		}
+		if content, err = readFileContent(tmp.Name()); err != nil {
+			return err
+		}
+		if content == original {
+			fmt.Println("No changes made.")
+			return nil
+		}
+
+		problems := validate(content)
+		if len(problems) == 0 {
+			break
+		}
+		fmt.Fprintf(os.Stderr, "%s has errors:\n", path)
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "  %s\n", problem)
+		}
+		if IsYesMode() || !askForConfirmation("Edit again?") {
+			return fmt.Errorf("changes to %s discarded", path)
+		}
+	}
+
+	if IsDryRun() {
+		printDryRunWrite(path, content)
+		return nil
+	}
+
+	// Replace the file in one step so that it is never seen half written
+	staged := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".pkgs-new")
+	if err := os.WriteFile(staged, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", staged, err)
+	}
+	if err := os.Rename(staged, path); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to replace %s: %v", path, err)
+	}
+	logFileChange("write", path, original, content)
+	fmt.Printf("Updated %s\n", path)
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// runEditor opens a file in the user's editor
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may be given with arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	cmd := newCommand(fields[0], append(fields[1:], path)...)
+	prepareCommand(cmd)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %v", fields[0], err)
+	}
+	return nil
+}
+
+// validateAptList checks one-line style apt entries
+func validateAptList(content string) []string {
+	var problems []string
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if _, ok := parseAptLine(trimmed); !ok {
+			problems = append(problems, fmt.Sprintf("line %d: expected \"deb [options] uri suite [components]\"", i+1))
+		}
+	}
+	return problems
+}
+
+// validateDeb822Sources checks the stanzas of a .sources file
+func validateDeb822Sources(content string) []string {
+	var problems []string
+	lines := strings.Split(content, "\n")
+	for start := 0; start < len(lines); start++ {
+		if strings.TrimSpace(lines[start]) == "" {
+			continue
+		}
+		fields := map[string]string{}
+		end := start
+		for ; end < len(lines) && strings.TrimSpace(lines[end]) != ""; end++ {
+			line := lines[end]
+			if strings.HasPrefix(line, "#") || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				continue
+			}
+			name, value, found := strings.Cut(line, ":")
+			if !found || strings.TrimSpace(name) == "" || strings.ContainsAny(strings.TrimSpace(name), " \t") {
+				problems = append(problems, fmt.Sprintf("line %d: expected \"Field: value\"", end+1))
+				continue
+			}
+			fields[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+		}
+
+		// Stanzas of only comments are fine
+		if len(fields) > 0 {
+			where := fmt.Sprintf("stanza at line %d", start+1)
+			for _, required := range []string{"Types", "URIs", "Suites"} {
+				if fields[strings.ToLower(required)] == "" {
+					problems = append(problems, fmt.Sprintf("%s: missing %s field", where, required))
+				}
+			}
+			for _, typ := range strings.Fields(fields["types"]) {
+				if typ != "deb" && typ != "deb-src" {
+					problems = append(problems, fmt.Sprintf("%s: unknown type %q", where, typ))
+				}
+			}
+			// Only flat repositories, whose suite ends with "/", have no components
+			for _, suite := range strings.Fields(fields["suites"]) {
+				if !strings.HasSuffix(suite, "/") && fields["components"] == "" {
+					problems = append(problems, fmt.Sprintf("%s: missing Components field for suite %s", where, suite))
+					break
+				}
+			}
+		}
+		start = end
+	}
+	return problems
+}
+
+// validateRepoFile checks the INI syntax of a .repo file
+func validateRepoFile(content string) []string {
+	var problems []string
+	seen := map[string]bool{}
+	section := ""
+	hasURL := false
+	finish := func() {
+		if section != "" && !hasURL {
+			problems = append(problems, fmt.Sprintf("[%s]: missing baseurl, metalink or mirrorlist", section))
+		}
+	}
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "", strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, ";"):
+		case strings.HasPrefix(trimmed, "["):
+			finish()
+			if !strings.HasSuffix(trimmed, "]") || len(trimmed) < 3 {
+				problems = append(problems, fmt.Sprintf("line %d: invalid section header", i+1))
+				section = ""
+				continue
+			}
+			section, hasURL = trimmed[1:len(trimmed)-1], false
+			if seen[section] {
+				problems = append(problems, fmt.Sprintf("line %d: repository %s is defined twice", i+1, section))
+			}
+			seen[section] = true
+		case line != trimmed && (line[0] == ' ' || line[0] == '\t'):
+			// Continuation of a list, e.g. further baseurl values
+		default:
+			key, _, found := strings.Cut(trimmed, "=")
+			switch {
+			case !found:
+				problems = append(problems, fmt.Sprintf("line %d: expected \"option=value\"", i+1))
+			case section == "":
+				problems = append(problems, fmt.Sprintf("line %d: option outside of a [repository] section", i+1))
+			default:
+				switch strings.TrimSpace(key) {
+				case "baseurl", "metalink", "mirrorlist":
+					hasURL = true
+				}
+			}
+		}
+	}
+	finish()
+	return problems
+}
+
+// validateApkRepositories checks the lines of /etc/apk/repositories
+func validateApkRepositories(content string) []string {
+	var problems []string
+	for i, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		// Tagged repositories are written as "@tag url"
+		if strings.HasPrefix(fields[0], "@") {
+			fields = fields[1:]
+		}
+		if len(fields) != 1 || (!strings.Contains(fields[0], "://") && !strings.HasPrefix(fields[0], "/")) {
+			problems = append(problems, fmt.Sprintf("line %d: expected a URL or a path, optionally after an @tag", i+1))
+		}
+	}
+	return problems
+}
+
+// validatePacmanConf checks the sections and options of pacman.conf
+func validatePacmanConf(content string) []string {
+	var problems []string
+	hasOptions := false
+	section := ""
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "", strings.HasPrefix(trimmed, "#"):
+		case strings.HasPrefix(trimmed, "["):
+			if !strings.HasSuffix(trimmed, "]") || len(trimmed) < 3 {
+				problems = append(problems, fmt.Sprintf("line %d: invalid section header", i+1))
+				continue
+			}
+			section = trimmed[1 : len(trimmed)-1]
+			hasOptions = hasOptions || section == "options"
+		case section == "":
+			problems = append(problems, fmt.Sprintf("line %d: option outside of a section", i+1))
+		default:
+			// Options are either "Name = value" or a bare flag such as "Color"
+			key, _, _ := strings.Cut(trimmed, "=")
+			if key = strings.TrimSpace(key); key == "" || strings.ContainsAny(key, " \t") {
+				problems = append(problems, fmt.Sprintf("line %d: expected \"Option = value\"", i+1))
+			}
+		}
+	}
+	if !hasOptions {
+		problems = append(problems, "missing [options] section")
+	}
+	return problems
+}
+
+func init() {
+	rootCmd.AddCommand(editRepoCmd)
+}
@@ -16,71 +16,90 @@ var enableRepoCmd = &cobra.Command{
 
 For apt-based systems (Debian/Ubuntu):
   pkgs enable-repo name
-  Enables a repository by uncommenting entries in /etc/apt/sources.list.d/name.list
+  Enables a repository by uncommenting entries in /etc/apt/sources.list.d/name.list,
+  or by removing 'Enabled: no' from /etc/apt/sources.list.d/name.sources
+
+  pkgs enable-repo --match text
+  Uncomments the one-line entries containing text in /etc/apt/sources.list
+  and the .list files, e.g. all deb-src entries, after showing them
 
 For dnf/yum-based systems (Fedora/RHEL/CentOS):
   pkgs enable-repo name
-  Sets 'enabled=1' in the repository file in /etc/yum.repos.d/
+  Sets 'enabled=1' in the repository file in /etc/yum.repos.d/,
+  or uses 'dnf copr enable' for COPR projects given as copr:owner/project
 
 For Alpine Linux:
   pkgs enable-repo name
-  Uncomments the repository in /etc/apk/repositories`,
+  Uncomments the repository in /etc/apk/repositories
+
+For Arch Linux:
+  pkgs enable-repo name
+  Uncomments the [name] section and its options in /etc/pacman.conf`,
 	Example: `  # Enable a repository for apt-based systems
   pkgs enable-repo nodesource
 
+  # Enable the backports entries of /etc/apt/sources.list
+  pkgs enable-repo --match backports
+
   # Enable a repository for dnf/yum-based systems
   pkgs enable-repo docker-ce
 
   # Enable a repository for Alpine Linux
-  pkgs enable-repo edge-testing`,
-	Run: func(cmd *cobra.Command, args []string) {
+  pkgs enable-repo edge-testing
+
+  # Enable a repository for Arch Linux
+  pkgs enable-repo multilib`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
+		}
+
+		// Individual entries of the one-line files are selected by a pattern
+		if pattern, _ := cmd.Flags().GetString("match"); pattern != "" {
+			if len(args) != 0 {
+				return fmt.Errorf("give either a repository name or --match")
+			}
+			if pm.Type != "debian" {
+				return unsupportedError("--match is only supported for apt-based systems")
+			}
+			return setAptLinesEnabled(pattern, true)
 		}
 
 		// Check arguments
 		if len(args) != 1 {
-			fmt.Println("Error: Repository name is required.")
-			fmt.Println("Usage: pkgs enable-repo name")
-			return
+			return fmt.Errorf("repository name is required (usage: pkgs enable-repo name)")
 		}
 		name := args[0]
 
 		// Enable repository based on package manager
 		switch pm.Type {
 		case "debian":
-			if err := enableRepoApt(name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+			return enableRepoApt(name)
 		case "redhat":
-			if err := enableRepoDnfYum(name); err != nil {
-				fmt.Printf("Error: %v\n", err)
+			if owner, project, ok := parseCopr(name); ok {
+				return setCoprEnabled(pm, owner, project, true)
 			}
+			return enableRepoDnfYum(name)
 		case "alpine":
-			if err := enableRepoAlpine(name); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
+			return enableRepoAlpine(name)
 		case "arch":
-			fmt.Println("For Arch Linux, you need to manually edit /etc/pacman.conf to enable repositories.")
+			return setRepoEnabledPacman(name, true)
 		case "macos":
 			fmt.Println("For Homebrew, taps are always enabled if they are installed.")
 			fmt.Println("If you need to add a tap, use 'pkgs add-repo tap-name' instead.")
-		default:
-			fmt.Println("Enabling repositories is not supported for this package manager.")
 		}
+
+		// The remaining backends only print guidance for doing this manually
+		return unsupportedError("enabling repositories is not supported for package manager '%s'", pm.Name)
 	},
 }
 
 // enableRepoApt enables a repository for apt-based systems
 func enableRepoApt(name string) error {
-	config := getRepoConfig("debian")
-
-	// Check for the repository file
-	repoPath := filepath.Join(config.baseDir, name+config.fileExtension)
-	if !fileExists(repoPath) {
-		return fmt.Errorf("repository file %s does not exist", repoPath)
+	repoPath, err := findAptSourceFile(name)
+	if err != nil {
+		return err
 	}
 
 	// Read the repository file
@@ -89,16 +108,23 @@ func enableRepoApt(name string) error {
 		return err
 	}
 
-	// Uncomment all commented lines that are not comments themselves
-	lines := strings.Split(content, "\n")
+	var newContent string
 	modified := false
-	for i, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmedLine, "# deb") || strings.HasPrefix(trimmedLine, "#deb") {
-			// Remove the comment marker
-			lines[i] = strings.TrimPrefix(strings.TrimPrefix(line, "# "), "#")
-			modified = true
+	if strings.HasSuffix(repoPath, ".sources") {
+		// deb822 files are disabled with an "Enabled: no" field
+		newContent, modified = setDeb822Enabled(content, true)
+	} else {
+		// Uncomment all commented lines that are not comments themselves
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			trimmedLine := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmedLine, "# deb") || strings.HasPrefix(trimmedLine, "#deb") {
+				// Remove the comment marker
+				lines[i] = strings.TrimPrefix(strings.TrimPrefix(line, "# "), "#")
+				modified = true
+			}
 		}
+		newContent = strings.Join(lines, "\n")
 	}
 
 	if !modified {
@@ -106,8 +132,11 @@ func enableRepoApt(name string) error {
 		return nil
 	}
 
+	if err := confirmPlan(planStep{"write", repoPath}); err != nil {
+		return err
+	}
 	// Write the modified content back
-	if err := writeFileContent(repoPath, strings.Join(lines, "\n"), 0644); err != nil {
+	if err := writeFileContent(repoPath, newContent, 0644); err != nil {
 		return err
 	}
 
@@ -120,12 +149,17 @@ func enableRepoApt(name string) error {
 func enableRepoDnfYum(name string) error {
 	config := getRepoConfig("redhat")
 
-	repoFile, found, err := findRepoFile(config.baseDir, config.fileExtension, name)
+	var scanErrs fileErrors
+	repoFile, found, err := findRepoFile(config.baseDir, config.fileExtension, name, &scanErrs)
 	if err != nil {
 		return err
 	}
 
 	if !found {
+		// The repository may be defined in one of the files that could not be read
+		if err := scanErrs.err(); err != nil {
+			return fmt.Errorf("no repository with ID '%s' found in the readable files of %s: %w", name, config.baseDir, err)
+		}
 		return fmt.Errorf("no repository with ID '%s' found in %s", name, config.baseDir)
 	}
 
@@ -137,16 +171,19 @@ func enableRepoDnfYum(name string) error {
 	newContent := setRepoEnabled(content, name, true)
 	if newContent == content {
 		fmt.Printf("Repository '%s' is already enabled\n", name)
-		return nil
+		return scanErrs.err()
 	}
 
+	if err := confirmPlan(planStep{"write", repoFile}); err != nil {
+		return err
+	}
 	if err := writeFileContent(repoFile, newContent, 0644); err != nil {
 		return err
 	}
 
 	fmt.Printf("Successfully enabled repository '%s' in %s\n", name, repoFile)
 	fmt.Println("Run 'pkgs update' to update the package lists.")
-	return nil
+	return scanErrs.err()
 }
 
 // enableRepoAlpine enables a repository in Alpine Linux
@@ -186,6 +223,9 @@ func enableRepoAlpine(name string) error {
 		return fmt.Errorf("repository %s not found or already enabled", name)
 	}
 
+	if err := confirmPlan(planStep{"write", repoFile}); err != nil {
+		return err
+	}
 	// Write the modified content back
 	if err := writeFileContent(repoFile, strings.Join(lines, "\n"), 0644); err != nil {
 		return err
@@ -198,4 +238,6 @@ func enableRepoAlpine(name string) error {
 
 func init() {
 	rootCmd.AddCommand(enableRepoCmd)
+
+	enableRepoCmd.Flags().String("match", "", "Enable the apt entries containing this text, in sources.list and all .list files")
 }
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// enableSrcCmd represents the enable-src command
+var enableSrcCmd = &cobra.Command{
+	Use:   "enable-src [name]",
+	Short: "Enable the source package repositories",
+	Long: `Enable the deb-src repositories that 'apt-get source' and
+'apt-get build-dep' download source packages from, for all repositories or
+only the named one. Use 'pkgs disable-src' to turn them off again.
+
+For apt-based systems (Debian/Ubuntu):
+  In one-line .list files a commented out deb-src entry is uncommented, or a
+  deb-src entry is added after every deb entry that has none. In deb822
+  .sources files deb-src is added to the Types field.`,
+	Example: `  # Enable the source repositories of all repositories
+  pkgs enable-src
+
+  # Enable the source repository of a single repository
+  pkgs enable-src nodesource`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+		if pm.Type != "debian" {
+			return unsupportedError("source repositories are only supported for apt-based systems, not for package manager '%s'", pm.Name)
+		}
+
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		return setAptSourcesEnabled(name, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(enableSrcCmd)
+}
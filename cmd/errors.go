@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Exit codes for failures detected by pkgs itself. They are kept in a range
+// that package managers do not use, so a failing native command can keep its
+// own exit code (e.g. 100 for apt) without being confused with them.
+const (
+	ExitError            = 1
+	ExitNoPackageManager = 111
+	ExitUnsupported      = 112
+	ExitPrivilege        = 113
+	ExitCancelled        = 114
+	ExitNativeFailure    = 115
+	ExitPartialFailure   = 116
+	ExitPasswordRequired = 117
+	ExitTimeout          = 118
+)
+
+// exitError attaches a pkgs exit code to an error
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode wraps an error so that pkgs exits with the given code
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// errNoPackageManager is returned when no supported package manager is found
+var errNoPackageManager = withExitCode(ExitNoPackageManager, errors.New("no supported package manager detected on this system"))
+
+// errCancelled is returned when the user declines a confirmation prompt
+var errCancelled = withExitCode(ExitCancelled, errors.New("operation cancelled by user"))
+
+// unsupportedError reports an operation the detected package manager cannot perform
+func unsupportedError(format string, args ...any) error {
+	return withExitCode(ExitUnsupported, fmt.Errorf(format, args...))
+}
+
+// fileErrors collects per-file failures so that a scan can process every
+// file and report all problems together at the end
+type fileErrors struct {
+	messages []string
+}
+
+// add records a failure for one file
+func (f *fileErrors) add(err error) {
+	f.messages = append(f.messages, err.Error())
+}
+
+// err returns the collected failures as a partial-failure error, or nil
+func (f *fileErrors) err() error {
+	if len(f.messages) == 0 {
+		return nil
+	}
+	return withExitCode(ExitPartialFailure, fmt.Errorf("%d file(s) could not be processed:\n  %s",
+		len(f.messages), strings.Join(f.messages, "\n  ")))
+}
+
+// ExitCode returns the process exit code for an error returned by Execute.
+// Failures of the wrapped package manager keep its own exit code so that
+// scripts can react to it; native commands that could not be started or were
+// killed map to ExitNativeFailure, and any other error maps to ExitError.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var codeErr *exitError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() > 0 {
+			return exitErr.ExitCode()
+		}
+		return ExitNativeFailure
+	}
+
+	var execErr *exec.Error
+	if errors.As(err, &execErr) {
+		return ExitNativeFailure
+	}
+	return ExitError
+}
@@ -1,12 +1,34 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 )
 
+// newCommand creates a command for a native tool, which runs in the container
+// with --container and works on the root directory with --root. Read-only
+// pacman queries run as the invoking user when pkgs itself was elevated with
+// sudo.
+func newCommand(name string, args ...string) *exec.Cmd {
+	if inContainer() {
+		return commandWithTimeout(containerRuntime, containerExecArgs(name, args)...)
+	}
+	if rootDir != "" {
+		args = append(rootArgs(name), args...)
+	}
+	cmd := commandWithTimeout(name, args...)
+	// Another root is only readable as root
+	if name == "pacman" && isPacmanQuery(args) && rootDir == "" {
+		runAsInvokingUser(cmd)
+	}
+	return cmd
+}
+
 // prepareCommand sets up standard I/O for a command
 func prepareCommand(cmd *exec.Cmd) {
 	cmd.Stdout = os.Stdout
@@ -17,7 +39,7 @@ func prepareCommand(cmd *exec.Cmd) {
 // combineErrors combines two errors if both are non-nil
 func combineErrors(err1, err2 error) error {
 	if err1 != nil && err2 != nil {
-		return fmt.Errorf("%v; %v", err1, err2)
+		return fmt.Errorf("%w; %w", err1, err2)
 	}
 	if err1 != nil {
 		return err1
@@ -25,15 +47,6 @@ func combineErrors(err1, err2 error) error {
 	return err2
 }
 
-// getPacmanAutoremoveScript returns the script for pacman autoremove
-func getPacmanAutoremoveScript() string {
-	script := "pacman -Rns $(pacman -Qdtq) 2>/dev/null || echo 'No orphaned packages to remove'"
-	if IsYesMode() {
-		script = "pacman --noconfirm -Rns $(pacman -Qdtq) 2>/dev/null || echo 'No orphaned packages to remove'"
-	}
-	return script
-}
-
 // ExecuteCommand runs a package manager command with the given arguments
 func ExecuteCommand(pm *PackageManager, command string, args []string) error {
 	if pm == nil {
@@ -43,45 +56,106 @@ func ExecuteCommand(pm *PackageManager, command string, args []string) error {
 	// Get the command arguments for the specific package manager
 	cmdArgs, ok := pm.Commands[command]
 	if !ok {
-		return fmt.Errorf("command '%s' not supported for package manager '%s'", command, pm.Name)
+		return unsupportedError("command '%s' not supported for package manager '%s'", command, pm.Name)
 	}
 
-	// Special handling for pacman autoremove which uses shell expansion
+	// pacman removes orphans by name, which are listed first
 	if pm.Name == "pacman" && command == "autoremove" {
-		return executeShell(getPacmanAutoremoveScript())
+		orphans, err := listOrphans(pm)
+		if err != nil {
+			return err
+		}
+		if len(orphans) == 0 {
+			fmt.Println("No orphaned packages to remove")
+			return nil
+		}
+		args = make([]string, 0, len(orphans))
+		for _, orphan := range orphans {
+			args = append(args, orphan.Name)
+		}
 	}
 
 	// Special handling for Homebrew autoremove
 	if pm.Name == "brew" && command == "autoremove" {
 		// Homebrew doesn't have a direct autoremove command, but it has a command to remove unused dependencies
+		var dryRun []string
+		if IsDryRun() {
+			dryRun = []string{"--dry-run"}
+		}
 		fmt.Println("Removing unused dependencies with Homebrew...")
-		cmd := exec.Command("brew", "autoremove")
+		cmd := newCommand("brew", append([]string{"autoremove"}, dryRun...)...)
 		prepareCommand(cmd)
-		err := cmd.Run()
+		err := runChild(cmd)
 
 		// Also run cleanup to remove old versions
 		fmt.Println("Cleaning up old versions of formulae...")
-		cleanupCmd := exec.Command("brew", "cleanup")
+		cleanupCmd := newCommand("brew", append([]string{"cleanup"}, dryRun...)...)
 		prepareCommand(cleanupCmd)
-		cleanupErr := cleanupCmd.Run()
+		cleanupErr := runChild(cleanupCmd)
 
 		return combineErrors(err, cleanupErr)
 	}
 
+	// Nix installs flake references, plain names refer to nixpkgs
+	if pm.Name == "nix" && command == "install" {
+		args = nixInstallables(args)
+	}
+
 	// Prepare the full command with arguments
 	fullCmd := append([]string{}, cmdArgs...)
 
-	// Add yes flag for non-interactive mode if needed
-	addYesFlagIfNeeded(pm, &fullCmd)
+	if IsDryRun() || IsAssumeNo() {
+		// Simulations never prompt, so no yes flag is needed. With --assume-no
+		// dnf/yum --assumeno shows the transaction and declines it, the other
+		// package managers would go ahead without asking.
+		simulated, ok := simulationArgs(pm, command, cmdArgs)
+		if !ok {
+			printDryRun(pm.Bin, append(fullCmd, args...)...)
+			return nil
+		}
+		fullCmd = simulated
+	} else {
+		// Add yes flag for non-interactive mode if needed
+		addYesFlagIfNeeded(pm, &fullCmd)
+	}
 
 	// Add the user arguments
 	fullCmd = append(fullCmd, args...)
 
 	fmt.Printf("Executing: %s %s\n", pm.Bin, strings.Join(fullCmd, " "))
 
-	cmd := exec.Command(pm.Bin, fullCmd...)
+	cmd := newCommand(pm.Bin, fullCmd...)
 	prepareCommand(cmd)
-	return cmd.Run()
+
+	// Capture pacman transactions to summarize alpm hook output afterwards
+	var transcript bytes.Buffer
+	capture := pm.Name == "pacman" && !isPacmanQuery(fullCmd) && !IsDryRun() && !IsAssumeNo()
+	// dnf/yum --assumeno only tells an aborted transaction from a failure in its output
+	assumedNo := pm.Type == "redhat" && (IsDryRun() || IsAssumeNo())
+	if capture || assumedNo {
+		cmd.Stdout = io.MultiWriter(os.Stdout, &transcript)
+		cmd.Stderr = io.MultiWriter(os.Stderr, &transcript)
+	}
+
+	err := runChild(cmd)
+	if capture {
+		printPacmanSummary(parsePacmanTransaction(transcript.String()))
+	}
+
+	// dnf/yum check-update exits with 100 when updates are available, which is not a failure
+	var exitErr *exec.ExitError
+	if pm.Type == "redhat" && command == "update" && errors.As(err, &exitErr) && exitErr.ExitCode() == 100 {
+		return nil
+	}
+	// dnf/yum --assumeno exits with 1 after aborting the transaction, which
+	// is the end of a dry run and a declined transaction with --assume-no
+	if assumedNo && errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && strings.Contains(transcript.String(), "Operation aborted") {
+		if IsDryRun() {
+			return nil
+		}
+		return errCancelled
+	}
+	return err
 }
 
 // addYesFlagIfNeeded adds the appropriate yes flag for non-interactive mode based on the package manager
@@ -89,10 +163,14 @@ func addYesFlagIfNeeded(pm *PackageManager, cmdArgs *[]string) {
 	if IsYesMode() {
 		switch pm.Name {
 		case "apt", "apt-get":
-			// For apt/apt-get, use -y
+			// For apt/apt-get, use -y, and keep modified configuration files
+			// rather than asking what to do with the packaged ones
 			if !containsFlag(*cmdArgs, "-y") {
 				*cmdArgs = append([]string{"-y"}, *cmdArgs...)
 			}
+			if !containsFlag(*cmdArgs, "Dpkg::Options::=--force-confold") {
+				*cmdArgs = append([]string{"-o", "Dpkg::Options::=--force-confdef", "-o", "Dpkg::Options::=--force-confold"}, *cmdArgs...)
+			}
 		case "dnf", "yum":
 			// For dnf/yum, use -y
 			if !containsFlag(*cmdArgs, "-y") {
@@ -103,16 +181,26 @@ func addYesFlagIfNeeded(pm *PackageManager, cmdArgs *[]string) {
 			if !containsFlag(*cmdArgs, "--noconfirm") {
 				*cmdArgs = append([]string{"--noconfirm"}, *cmdArgs...)
 			}
+		case "flatpak":
+			// For flatpak, use -y after the subcommand
+			if len(*cmdArgs) > 0 && !containsFlag(*cmdArgs, "-y") {
+				*cmdArgs = append([]string{(*cmdArgs)[0], "-y"}, (*cmdArgs)[1:]...)
+			}
 		}
 	}
 }
 
-// executeShell executes a shell command directly
-func executeShell(command string) error {
-	fmt.Printf("Executing: %s\n", command)
-	cmd := exec.Command("sh", "-c", command)
+// executeNative runs a native tool with the terminal attached, announcing it like ExecuteCommand.
+// Dry runs and --assume-no only show it.
+func executeNative(name string, args ...string) error {
+	if IsDryRun() || IsAssumeNo() {
+		printDryRun(name, args...)
+		return nil
+	}
+	fmt.Printf("Executing: %s %s\n", name, strings.Join(args, " "))
+	cmd := newCommand(name, args...)
 	prepareCommand(cmd)
-	return cmd.Run()
+	return runChild(cmd)
 }
 
 // containsFlag checks if a flag is already present in the command arguments
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// filesCmd represents the files command
+var filesCmd = &cobra.Command{
+	Use:   "files package",
+	Short: "List the files installed by a package",
+	Long: `List the files installed by a package using the native package manager.
+
+The output is one absolute path per line on every system. Directories that
+only exist to hold the package's files are left out. Use --glob to only show
+paths matching a shell pattern; a pattern without a slash is matched against
+the file name, otherwise against the full path.
+
+For apt-based systems (Debian/Ubuntu):
+  dpkg -L package
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  rpm -ql package
+
+For Alpine Linux:
+  apk info -L package
+
+For Arch Linux:
+  pacman -Ql package
+
+For Homebrew (macOS):
+  brew list package`,
+	Example: `  pkgs files curl
+  pkgs files nginx --glob '*.conf'
+  pkgs files openssl --glob '/usr/lib/*'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		pattern, _ := cmd.Flags().GetString("glob")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern '%s': %v", pattern, err)
+		}
+
+		files, err := listPackageFiles(pm, args[0])
+		if err != nil {
+			return err
+		}
+
+		defer startPager()()
+
+		for _, file := range files {
+			if pattern == "" || matchFileGlob(pattern, file) {
+				fmt.Println(file)
+			}
+		}
+		return nil
+	},
+}
+
+// listPackageFiles returns the files installed by a package
+func listPackageFiles(pm *PackageManager, name string) ([]string, error) {
+	var output string
+	var err error
+	switch pm.Type {
+	case "debian":
+		output, err = runCommandOutput("dpkg", "-L", name)
+	case "redhat":
+		output, err = runCommandOutput("rpm", "-ql", name)
+	case "alpine":
+		output, err = runCommandOutput("apk", "info", "-qL", name)
+	case "arch":
+		output, err = runCommandOutput("pacman", "-Qlq", name)
+	case "macos":
+		output, err = runCommandOutput("brew", "list", name)
+	default:
+		return nil, unsupportedError("listing package files is not supported for package manager '%s'", pm.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("package '%s' is not installed", name)
+	}
+
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		// Directories are listed by dpkg and pacman ("/." and trailing slashes), skip them
+		if line == "" || line == "/." || strings.HasSuffix(line, "/") || strings.HasPrefix(line, "(") {
+			continue
+		}
+		// apk lists paths relative to the root directory
+		if !strings.HasPrefix(line, "/") {
+			line = "/" + line
+		}
+		files = append(files, line)
+	}
+
+	// dpkg lists every parent directory as a plain path
+	if pm.Type == "debian" {
+		files = dropParentDirs(files)
+	}
+	return files, nil
+}
+
+// dropParentDirs removes entries that are a parent directory of another entry
+func dropParentDirs(files []string) []string {
+	parents := map[string]bool{}
+	for _, file := range files {
+		for dir := path.Dir(file); dir != "/" && !parents[dir]; dir = path.Dir(dir) {
+			parents[dir] = true
+		}
+	}
+
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		if !parents[file] {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// matchFileGlob matches a pattern against the full path if it contains a
+// slash, otherwise against the file name only
+func matchFileGlob(pattern, file string) bool {
+	target := file
+	if !strings.Contains(pattern, "/") {
+		target = path.Base(file)
+	}
+	matched, _ := path.Match(pattern, target)
+	return matched
+}
+
+func init() {
+	rootCmd.AddCommand(filesCmd)
+
+	filesCmd.Flags().String("glob", "", "Only show files matching a shell pattern (e.g. '*.conf')")
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a captured set of explicitly installed packages
+type Manifest struct {
+	Backend       string            `json:"backend" yaml:"backend"`
+	Distro        string            `json:"distro,omitempty" yaml:"distro,omitempty"`
+	DistroVersion string            `json:"distro_version,omitempty" yaml:"distro_version,omitempty"`
+	Generated     time.Time         `json:"generated" yaml:"generated"`
+	Packages      []ManifestPackage `json:"packages" yaml:"packages"`
+	Repos         []ManifestSource  `json:"repos,omitempty" yaml:"repos,omitempty"`
+	Keys          []ManifestSource  `json:"keys,omitempty" yaml:"keys,omitempty"`
+	Holds         []string          `json:"holds,omitempty" yaml:"holds,omitempty"`
+}
+
+// ManifestPackage is one package of a manifest; the version is optional
+type ManifestPackage struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// ManifestSource is a repository or key of a manifest, with the same name and
+// URL arguments as 'pkgs add-repo' and 'pkgs add-key'
+type ManifestSource struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+// freezeCmd represents the freeze command
+var freezeCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "Export the explicitly installed packages as a manifest",
+	Long: `Write the packages that were installed explicitly (not as dependencies) to
+a YAML or JSON manifest. The manifest records the package manager and the
+distribution, so the package set can be reviewed or re-created on another
+machine with 'pkgs apply'.
+
+With --versions the installed version of every package is recorded as well.`,
+	Example: `  pkgs freeze > packages.yaml
+  pkgs freeze --versions --format json --output packages.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "yaml" && format != "json" {
+			return fmt.Errorf("unsupported manifest format '%s' (use yaml or json)", format)
+		}
+		withVersions, _ := cmd.Flags().GetBool("versions")
+
+		manifest, err := buildManifest(pm, withVersions)
+		if err != nil {
+			return err
+		}
+		data, err := encodeManifest(manifest, format)
+		if err != nil {
+			return err
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" || output == "-" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := writeFileContent(output, string(data), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d package(s) to %s\n", len(manifest.Packages), output)
+		return nil
+	},
+}
+
+// buildManifest collects the explicitly installed packages of the system
+func buildManifest(pm *PackageManager, withVersions bool) (Manifest, error) {
+	manifest := Manifest{Backend: pm.Name, Generated: time.Now().UTC().Truncate(time.Second)}
+	manifest.Distro, manifest.DistroVersion = distroInfo()
+
+	explicit, err := explicitPackages(pm)
+	if err != nil {
+		return manifest, err
+	}
+	installed, err := listInstalled(pm)
+	if err != nil {
+		return manifest, err
+	}
+	versions := map[string]string{}
+	for _, p := range installed {
+		versions[p.Name] = p.Version
+	}
+
+	// Only packages that are still installed are recorded
+	seen := map[string]bool{}
+	manifest.Packages = []ManifestPackage{}
+	for _, name := range explicit {
+		version, ok := versions[name]
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		pkg := ManifestPackage{Name: name}
+		if withVersions {
+			pkg.Version = version
+		}
+		manifest.Packages = append(manifest.Packages, pkg)
+	}
+	sort.Slice(manifest.Packages, func(i, j int) bool { return manifest.Packages[i].Name < manifest.Packages[j].Name })
+	return manifest, nil
+}
+
+// encodeManifest renders a manifest as YAML or JSON
+func encodeManifest(manifest Manifest, format string) ([]byte, error) {
+	if format == "json" {
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode manifest: %v", err)
+		}
+		return append(data, '\n'), nil
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// readManifest loads a YAML or JSON manifest; JSON is a subset of YAML, so
+// both are parsed by the YAML decoder
+func readManifest(path string) (Manifest, error) {
+	var manifest Manifest
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read manifest: %v", err)
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("failed to parse manifest %s: %v", path, err)
+	}
+	return manifest, nil
+}
+
+// distroInfo returns the distribution ID and version, e.g. "debian" and "12"
+func distroInfo() (string, string) {
+	if runtime.GOOS == "darwin" {
+		version, _ := runCommandOutput("sw_vers", "-productVersion")
+		return "macos", strings.TrimSpace(version)
+	}
+
+	content, err := readSystemFile("/etc/os-release")
+	if err != nil {
+		return runtime.GOOS, ""
+	}
+	var id, version string
+	for _, line := range strings.Split(content, "\n") {
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			id = value
+		case "VERSION_ID":
+			version = value
+		}
+	}
+	return id, version
+}
+
+func init() {
+	rootCmd.AddCommand(freezeCmd)
+
+	freezeCmd.Flags().Bool("versions", false, "Record the installed version of every package")
+	freezeCmd.Flags().String("format", "yaml", "Manifest format (yaml or json)")
+	freezeCmd.Flags().StringP("output", "o", "", "Write the manifest to a file instead of stdout")
+}
+
+// osReleaseValue returns a field of /etc/os-release, e.g. VERSION_CODENAME
+func osReleaseValue(key string) string {
+	content, err := readSystemFile("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if name, value, found := strings.Cut(line, "="); found && name == key {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// fullUpgradeStep is one stage of a full upgrade
+type fullUpgradeStep struct {
+	Name string
+	Run  func() error
+}
+
+// fullUpgradeCmd represents the full-upgrade command
+var fullUpgradeCmd = &cobra.Command{
+	Use:     "full-upgrade",
+	Aliases: []string{"fu"},
+	Short:   "Update, upgrade, autoremove and clean in one go",
+	Long: `Refresh the package lists, upgrade all packages (allowing dependency
+changes), remove unused packages and clean the package cache. pkgs asks for
+confirmation once and then runs every step non-interactively; a summary of all
+steps is printed at the end. A failing step stops the remaining ones.
+
+For apt-based systems (Debian/Ubuntu):
+  apt update, apt full-upgrade (apt-get dist-upgrade), apt autoremove, apt clean
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf check-update, dnf upgrade, dnf autoremove, dnf clean all
+
+For Alpine Linux:
+  apk update, apk upgrade --available, apk cache clean
+
+For Arch Linux:
+  pacman -Sy, pacman -Syu, removal of orphans, pacman -Sc
+
+For Homebrew (macOS):
+  brew update, brew upgrade, brew autoremove, brew cleanup`,
+	Example: `  pkgs full-upgrade
+  pkgs -y full-upgrade`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		fmt.Printf("Using package manager: %s\n", pm.Name)
+		if !askForConfirmation("Update, upgrade all packages, remove unused packages and clean the cache?") {
+			return errCancelled
+		}
+		// The one confirmation above covers the native prompts of every step
+		yesFlag = true
+
+		pending := -1
+		steps := []fullUpgradeStep{
+			{"update", func() error {
+				err := ExecuteCommand(pm, "update", nil)
+				if err == nil {
+					if outdated, err := listOutdated(pm); err == nil {
+						pending = len(outdated)
+					}
+				}
+				return err
+			}},
+			{"upgrade", func() error { return fullUpgrade(pm) }},
+			{"autoremove", func() error {
+				if pm.Type == "alpine" {
+					// apk drops unneeded packages on every run
+					return nil
+				}
+				return ExecuteCommand(pm, "autoremove", nil)
+			}},
+			{"clean", func() error { return ExecuteCommand(pm, "clean", nil) }},
+		}
+
+		rows := make([][]string, 0, len(steps))
+		var failed error
+		for _, step := range steps {
+			if failed != nil {
+				rows = append(rows, []string{step.Name, "skipped", ""})
+				continue
+			}
+			fmt.Printf("\n==> %s\n", step.Name)
+			start := time.Now()
+			result := "ok"
+			if err := step.Run(); err != nil {
+				failed = fmt.Errorf("%s step failed: %w", step.Name, err)
+				result = "failed"
+			}
+			rows = append(rows, []string{step.Name, result, time.Since(start).Round(time.Second).String()})
+		}
+
+		fmt.Println("\nSummary:")
+		printTable([]string{"STEP", "RESULT", "TIME"}, rows)
+		if pending >= 0 {
+			fmt.Printf("\n%d package(s) had updates available.\n", pending)
+		}
+		if failed == nil {
+			printRestartHint()
+		}
+		return failed
+	},
+}
+
+// fullUpgrade upgrades all packages, allowing new dependencies and removals
+func fullUpgrade(pm *PackageManager) error {
+	var fullCmd []string
+	switch pm.Name {
+	case "apt":
+		fullCmd = []string{"full-upgrade"}
+	case "apt-get":
+		fullCmd = []string{"dist-upgrade"}
+	case "apk":
+		fullCmd = []string{"upgrade", "--available"}
+	default:
+		return ExecuteCommand(pm, "upgrade", nil)
+	}
+	addYesFlagIfNeeded(pm, &fullCmd)
+	return executeNative(pm.Bin, fullCmd...)
+}
+
+func init() {
+	rootCmd.AddCommand(fullUpgradeCmd)
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// groupsCmd represents the groups command
+var groupsCmd = &cobra.Command{
+	Use:   "groups [group]",
+	Short: "List package groups or the packages in a group",
+	Long: `List the available package groups, or the packages that belong to a group.
+The groups are listed one per line by the name they are installed and removed
+with, using an "@" prefix, e.g. 'pkgs install @development-tools'.
+
+For apt-based systems (Debian/Ubuntu):
+  tasksel --list-tasks (apt-cache search '^task-' without tasksel);
+  @name installs the task with apt's "name^" syntax, a "task-" prefix is dropped
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf group list (by group id), dnf group info group; @name is passed to dnf as is
+
+For Arch Linux:
+  pacman -Sg; @name installs the group by its name
+
+apk and Homebrew do not have package groups.`,
+	Example: `  pkgs groups
+  pkgs groups development-tools
+  pkgs install @development-tools`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		defer startPager()()
+
+		if len(args) == 1 {
+			return showGroup(pm, strings.TrimPrefix(args[0], "@"))
+		}
+
+		groups, err := listGroups(pm)
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			fmt.Println(group)
+		}
+		return nil
+	},
+}
+
+// listGroups returns the names of the available groups, sorted, as they are
+// given to the install and remove commands with an "@" prefix
+func listGroups(pm *PackageManager) ([]string, error) {
+	var name string
+	var args []string
+	var groupName func(line string) string
+	switch pm.Type {
+	case "debian":
+		if err := lookPath("tasksel"); err == nil {
+			// Output: "u ssh-server\tSSH server"
+			name, args = "tasksel", []string{"--list-tasks"}
+			groupName = func(line string) string {
+				if fields := strings.Fields(line); len(fields) > 1 {
+					return fields[1]
+				}
+				return ""
+			}
+		} else {
+			// Output: "task-ssh-server - SSH server", the task is the name without "task-"
+			name, args = "apt-cache", []string{"search", "--names-only", "^task-"}
+			groupName = func(line string) string {
+				if fields := strings.Fields(line); len(fields) > 0 {
+					return strings.TrimPrefix(fields[0], "task-")
+				}
+				return ""
+			}
+		}
+	case "redhat":
+		if pm.Bin == "dnf" && isDnf5() {
+			// Output: "development-tools  Development Tools  no" after an "ID" header
+			name, args = pm.Bin, []string{"group", "list"}
+			groupName = func(line string) string {
+				if fields := strings.Fields(line); len(fields) > 0 && fields[0] != "ID" {
+					return fields[0]
+				}
+				return ""
+			}
+		} else {
+			// Output: "   Development Tools (development)" below section headers
+			name, args = pm.Bin, []string{"group", "list", "--ids"}
+			groupName = func(line string) string {
+				if !strings.HasPrefix(line, " ") || !strings.HasSuffix(line, ")") {
+					return ""
+				}
+				open := strings.LastIndex(line, "(")
+				if open < 0 {
+					return ""
+				}
+				return line[open+1 : len(line)-1]
+			}
+		}
+	case "arch":
+		// Output: "base-devel gcc", one line per group member
+		name, args = "pacman", []string{"-Sg"}
+		groupName = func(line string) string {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				return fields[0]
+			}
+			return ""
+		}
+	default:
+		return nil, unsupportedError("package groups are not supported for package manager '%s'", pm.Name)
+	}
+
+	output, err := runCommandOutput(name, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list package groups: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		if group := groupName(strings.TrimRight(line, " \t")); group != "" {
+			seen[group] = true
+		}
+	}
+	groups := make([]string, 0, len(seen))
+	for group := range seen {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// showGroup lists the packages of one group
+func showGroup(pm *PackageManager, group string) error {
+	var output string
+	var err error
+	switch pm.Type {
+	case "debian":
+		group = strings.TrimPrefix(group, "task-")
+		if lookPath("tasksel") == nil {
+			output, err = runCommandOutput("tasksel", "--task-packages", group)
+		} else {
+			output, err = runCommandOutput("apt-cache", "depends", "task-"+group)
+		}
+	case "redhat":
+		output, err = runCommandOutput(pm.Bin, "group", "info", group)
+	case "arch":
+		output, err = runCommandOutput("pacman", "-Sg", group)
+	default:
+		return unsupportedError("package groups are not supported for package manager '%s'", pm.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to show package group %s: %w", group, err)
+	}
+	fmt.Print(output)
+	return nil
+}
+
+// translateGroupArgs rewrites "@group" arguments into the native group syntax
+func translateGroupArgs(pm *PackageManager, args []string) ([]string, error) {
+	result := make([]string, 0, len(args))
+	for _, arg := range args {
+		group, isGroup := strings.CutPrefix(arg, "@")
+		if !isGroup {
+			result = append(result, arg)
+			continue
+		}
+		switch pm.Type {
+		case "debian":
+			// apt's "name^" takes the task name, the package without "task-"
+			result = append(result, strings.TrimPrefix(group, "task-")+"^")
+		case "redhat":
+			result = append(result, arg)
+		case "arch":
+			result = append(result, group)
+		default:
+			return nil, unsupportedError("package groups are not supported for package manager '%s'", pm.Name)
+		}
+	}
+	return result, nil
+}
+
+func init() {
+	rootCmd.AddCommand(groupsCmd)
+}
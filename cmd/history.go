@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [id]",
+	Short: "Show the package transaction history",
+	Long: `Show the transactions recorded by the native package manager as a
+chronological list of installs, removals and upgrades with IDs. Pass an ID to
+see the individual package changes of one transaction.
+
+The history is read from:
+  /var/log/apt/history.log (apt)
+  /var/log/dnf.rpm.log (dnf) or /var/log/yum.log (yum)
+  /var/log/pacman.log (pacman)
+
+apk and Homebrew do not keep a transaction log.`,
+	Example: `  pkgs history
+  pkgs history --limit 10
+  pkgs history 42
+  pkgs history --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		txs, err := readTransactions(pm)
+		if err != nil {
+			return err
+		}
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		if len(args) == 1 {
+			tx, err := findTransaction(txs, args[0])
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				return printJSON(tx)
+			}
+			defer startPager()()
+			printTransaction(tx)
+			return nil
+		}
+
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(txs) > limit {
+			txs = txs[len(txs)-limit:]
+		}
+		if jsonOutput {
+			return printJSON(txs)
+		}
+
+		defer startPager()()
+
+		if len(txs) == 0 {
+			fmt.Println("No transactions found.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(txs))
+		for _, tx := range txs {
+			rows = append(rows, []string{strconv.Itoa(tx.ID), tx.Time.Format("2006-01-02 15:04"), summarizeChanges(tx.Changes), tx.Command})
+		}
+		printTable([]string{"ID", "DATE", "CHANGES", "COMMAND"}, rows)
+		return nil
+	},
+}
+
+// findTransaction returns the transaction with the given ID
+func findTransaction(txs []transaction, id string) (transaction, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return transaction{}, fmt.Errorf("invalid transaction ID '%s'", id)
+	}
+	for _, tx := range txs {
+		if tx.ID == n {
+			return tx, nil
+		}
+	}
+	return transaction{}, fmt.Errorf("transaction %d not found (see 'pkgs history')", n)
+}
+
+// summarizeChanges counts the changes of a transaction per action, e.g. "upgrade 3, install 1"
+func summarizeChanges(changes []packageChange) string {
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Action]++
+	}
+	actions := make([]string, 0, len(counts))
+	for action := range counts {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	parts := make([]string, 0, len(actions))
+	for _, action := range actions {
+		parts = append(parts, fmt.Sprintf("%s %d", action, counts[action]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printTransaction prints the details of one transaction
+func printTransaction(tx transaction) {
+	fmt.Printf("Transaction %d, %s\n", tx.ID, tx.Time.Format("2006-01-02 15:04:05"))
+	if tx.Command != "" {
+		fmt.Printf("Command: %s\n", tx.Command)
+	}
+	fmt.Println()
+
+	rows := make([][]string, 0, len(tx.Changes))
+	for _, c := range tx.Changes {
+		version := c.Version
+		if c.OldVersion != "" {
+			version = c.OldVersion + " -> " + c.Version
+		}
+		rows = append(rows, []string{c.Action, c.Package, version})
+	}
+	printTable([]string{"ACTION", "PACKAGE", "VERSION"}, rows)
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().Bool("json", false, "Output the history as JSON")
+	historyCmd.Flags().Int("limit", 0, "Only show the most recent transactions")
+}
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// pacmanConf is the pacman configuration file holding IgnorePkg and the repositories
+const pacmanConf = "/etc/pacman.conf"
+
+// holdCmd represents the hold command
+var holdCmd = &cobra.Command{
+	Use:     "hold [packages...]",
+	Aliases: []string{"pin", "lock"},
+	Short:   "Keep packages at their current version during upgrades",
+	Long: `Hold one or more packages at their currently installed version, so that
+upgrades leave them alone. Use --list to show the packages that are currently
+held, and 'pkgs unhold' to release them again.
+
+For apt-based systems (Debian/Ubuntu):
+  apt-mark hold package
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf versionlock add package (needs the versionlock plugin on dnf 4 and yum)
+
+For Alpine Linux:
+  apk add package=<installed version>, which pins the version in /etc/apk/world
+
+For Arch Linux:
+  Adds the package to IgnorePkg in /etc/pacman.conf
+
+For Homebrew (macOS):
+  brew pin package`,
+	Example: `  pkgs hold nginx
+  pkgs hold linux linux-headers
+  pkgs hold --list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			held, err := heldPackages(pm)
+			if err != nil {
+				return err
+			}
+			for _, name := range held {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		// Check arguments
+		if len(args) == 0 {
+			return fmt.Errorf("at least one package name is required (usage: pkgs hold package...)")
+		}
+
+		step, err := holdStep(pm, args, true)
+		if err != nil {
+			return err
+		}
+		if err := confirmPlan(step); err != nil {
+			return err
+		}
+		return holdPackages(pm, args)
+	},
+}
+
+// holdStep is the change holding or releasing packages makes, for the plan
+// confirmed by hold and unhold
+func holdStep(pm *PackageManager, names []string, hold bool) (planStep, error) {
+	switch pm.Type {
+	case "debian":
+		action := "unhold"
+		if hold {
+			action = "hold"
+		}
+		return runStep("apt-mark", append([]string{action}, names...)...), nil
+	case "redhat":
+		action := "delete"
+		if hold {
+			action = "add"
+		}
+		return runStep(pm.Bin, append([]string{"versionlock", action}, names...)...), nil
+	case "alpine":
+		if !hold {
+			return runStep("apk", append([]string{"add"}, names...)...), nil
+		}
+		constraints, err := apkHoldConstraints(names)
+		if err != nil {
+			return planStep{}, err
+		}
+		return runStep("apk", append([]string{"add"}, constraints...)...), nil
+	case "arch":
+		return planStep{"write", pacmanConf}, nil
+	case "macos":
+		action := "unpin"
+		if hold {
+			action = "pin"
+		}
+		return runStep("brew", append([]string{action}, names...)...), nil
+	default:
+		return planStep{}, unsupportedError("holding packages is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// holdPackages keeps packages at their installed version
+func holdPackages(pm *PackageManager, names []string) error {
+	switch pm.Type {
+	case "debian":
+		return executeNative("apt-mark", append([]string{"hold"}, names...)...)
+	case "redhat":
+		return versionlock(pm, "add", names)
+	case "alpine":
+		return holdApk(names)
+	case "arch":
+		return setPacmanIgnored(names, true)
+	case "macos":
+		return executeNative("brew", append([]string{"pin"}, names...)...)
+	default:
+		return unsupportedError("holding packages is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// heldPackages returns the names of the packages that are currently held
+func heldPackages(pm *PackageManager) ([]string, error) {
+	switch pm.Type {
+	case "debian":
+		output, err := runCommandOutput("apt-mark", "showhold")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list held packages: %v", err)
+		}
+		return strings.Fields(output), nil
+	case "redhat":
+		output, err := runCommandOutput(pm.Bin, "-q", "versionlock", "list")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list locked packages: %v (is the versionlock plugin installed?)", err)
+		}
+		return parseVersionlockList(output), nil
+	case "alpine":
+		// Held packages carry a version constraint in the world file
+		content, err := readFileContent(apkWorldFile)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, entry := range strings.Fields(content) {
+			if name, _, found := strings.Cut(entry, "="); found {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	case "arch":
+		content, err := readFileContent(pacmanConf)
+		if err != nil {
+			return nil, err
+		}
+		return pacmanIgnoredPackages(content), nil
+	case "macos":
+		output, err := runCommandOutput("brew", "list", "--pinned")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pinned formulae: %v", err)
+		}
+		return strings.Fields(output), nil
+	default:
+		return nil, unsupportedError("holding packages is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// parseVersionlockList parses "versionlock list" output, which is one
+// "name-epoch:version-release.*" pattern per line on dnf 4 and yum, and
+// blocks with a "Package name: name" line on dnf 5
+func parseVersionlockList(output string) []string {
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.Contains(line, " = ") {
+			continue
+		}
+		if name, found := strings.CutPrefix(line, "Package name:"); found {
+			names = append(names, strings.TrimSpace(name))
+			continue
+		}
+		name, _ := splitRpmNEVRA(line)
+		names = append(names, name)
+	}
+	return uniqueSorted(names)
+}
+
+// versionlock runs a dnf/yum versionlock subcommand
+func versionlock(pm *PackageManager, action string, names []string) error {
+	if err := executeNative(pm.Bin, append([]string{"versionlock", action}, names...)...); err != nil {
+		if pm.Name == "yum" {
+			return fmt.Errorf("%v (versionlock needs the yum-plugin-versionlock package)", err)
+		}
+		return fmt.Errorf("%v (on dnf 4, versionlock needs the python3-dnf-plugin-versionlock package)", err)
+	}
+	return nil
+}
+
+// holdApk pins packages to their installed version in the apk world file
+func holdApk(names []string) error {
+	constraints, err := apkHoldConstraints(names)
+	if err != nil {
+		return err
+	}
+	return executeNative("apk", append([]string{"add"}, constraints...)...)
+}
+
+// apkHoldConstraints returns name=version constraints pinning packages to
+// their installed version
+func apkHoldConstraints(names []string) ([]string, error) {
+	constraints := make([]string, 0, len(names))
+	for _, name := range names {
+		version, err := apkInstalledVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, name+"="+version)
+	}
+	return constraints, nil
+}
+
+// apkInstalledVersion returns the installed version of an Alpine package
+func apkInstalledVersion(name string) (string, error) {
+	// Output: "curl-8.5.0-r0 x86_64 {curl} (MIT) [installed]"
+	output, err := runCommandOutput("apk", "list", "--installed", name)
+	if err == nil {
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			if pkg, version := splitApkNameVersion(fields[0]); pkg == name {
+				return version, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("package '%s' is not installed", name)
+}
+
+// setPacmanIgnored adds packages to or removes them from IgnorePkg in pacman.conf
+func setPacmanIgnored(names []string, ignore bool) error {
+	content, err := readFileContent(pacmanConf)
+	if err != nil {
+		return err
+	}
+
+	newContent := editPacmanIgnorePkg(content, names, ignore)
+	if newContent == content {
+		fmt.Println("Nothing to change in " + pacmanConf)
+		return nil
+	}
+	if err := writeFileContent(pacmanConf, newContent, 0644); err != nil {
+		return err
+	}
+
+	if ignore {
+		fmt.Printf("Added %s to IgnorePkg in %s\n", strings.Join(names, ", "), pacmanConf)
+	} else {
+		fmt.Printf("Removed %s from IgnorePkg in %s\n", strings.Join(names, ", "), pacmanConf)
+	}
+	return nil
+}
+
+// pacmanIgnoredPackages returns the packages listed in IgnorePkg lines of pacman.conf content
+func pacmanIgnoredPackages(content string) []string {
+	var names []string
+	for _, line := range strings.Split(content, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if found && strings.TrimSpace(key) == "IgnorePkg" {
+			names = append(names, strings.Fields(value)...)
+		}
+	}
+	return names
+}
+
+// editPacmanIgnorePkg adds names to or removes them from the IgnorePkg setting
+// of the [options] section. A new IgnorePkg line is placed after the commented
+// default, or at the start of the section if there is none.
+func editPacmanIgnorePkg(content string, names []string, ignore bool) string {
+	ignored := pacmanIgnoredPackages(content)
+	isIgnored := map[string]bool{}
+	for _, name := range ignored {
+		isIgnored[name] = true
+	}
+
+	// Compute the new IgnorePkg list
+	updated := ignored
+	if ignore {
+		for _, name := range names {
+			if !isIgnored[name] {
+				updated = append(updated, name)
+				isIgnored[name] = true
+			}
+		}
+	} else {
+		remove := map[string]bool{}
+		for _, name := range names {
+			remove[name] = true
+		}
+		updated = nil
+		for _, name := range ignored {
+			if !remove[name] {
+				updated = append(updated, name)
+			}
+		}
+	}
+	if strings.Join(updated, " ") == strings.Join(ignored, " ") {
+		return content
+	}
+
+	newLine := "IgnorePkg = " + strings.Join(updated, " ")
+	lines := strings.Split(content, "\n")
+	result := make([]string, 0, len(lines)+1)
+	written := false
+	insertAt, commentedAt := -1, -1
+	inOptions := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inOptions = trimmed == "[options]"
+			if inOptions {
+				insertAt = len(result) + 1
+			}
+		}
+
+		key, _, found := strings.Cut(trimmed, "=")
+		if found && strings.TrimSpace(key) == "IgnorePkg" {
+			// All existing entries are merged into a single line
+			if !written && len(updated) > 0 {
+				result = append(result, newLine)
+			}
+			written = true
+			continue
+		}
+		if inOptions && strings.HasPrefix(strings.ReplaceAll(trimmed, " ", ""), "#IgnorePkg") {
+			commentedAt = len(result) + 1
+		}
+		result = append(result, line)
+	}
+
+	if written || len(updated) == 0 {
+		return strings.Join(result, "\n")
+	}
+	if commentedAt >= 0 {
+		insertAt = commentedAt
+	}
+	if insertAt < 0 {
+		// No [options] section, which pacman requires; add one at the top
+		return "[options]\n" + newLine + "\n" + content
+	}
+	result = append(result[:insertAt], append([]string{newLine}, result[insertAt:]...)...)
+	return strings.Join(result, "\n")
+}
+
+func init() {
+	rootCmd.AddCommand(holdCmd)
+
+	holdCmd.Flags().Bool("list", false, "List the packages that are currently held")
+}
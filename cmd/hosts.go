@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags running a command on other machines over SSH
+var (
+	hostFlags     []string
+	hostsFileFlag string
+	parallelFlag  int
+)
+
+// remoteRun is set when the command runs on other machines instead of here
+var remoteRun bool
+
+// hostFlagNames are the flags that only concern the machine running the
+// command over SSH, they are not passed on to the hosts
+var hostFlagNames = []string{"host", "hosts", "parallel"}
+
+// hostColors tell the output of the hosts apart
+var hostColors = []string{colorGreen, colorCyan, colorMagenta, colorYellow, colorBlue}
+
+// remoteHosts returns the hosts given with --host and in the --hosts file
+func remoteHosts() ([]string, error) {
+	hosts := append([]string{}, hostFlags...)
+	if hostsFileFlag != "" {
+		content, err := readFileContent(hostsFileFlag)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, parseHostsFile(content)...)
+	}
+	if len(hosts) > 0 && parallelFlag < 1 {
+		return nil, fmt.Errorf("invalid --parallel %d: at least one host must run at a time", parallelFlag)
+	}
+	return uniqueSorted(hosts), nil
+}
+
+// parseHostsFile returns the hosts of a hosts file: one SSH destination per
+// line, e.g. "web1" or "admin@db.example.com", with # comments
+func parseHostsFile(content string) []string {
+	var hosts []string
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if fields := strings.Fields(line); len(fields) > 0 {
+			hosts = append(hosts, fields[0])
+		}
+	}
+	return hosts
+}
+
+// stripHostFlags returns the command line without the flags selecting the
+// hosts, for the pkgs running on them
+func stripHostFlags(args []string) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(result, args[i:]...)
+		}
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !strings.HasPrefix(arg, "--") || !slices.Contains(hostFlagNames, name) {
+			result = append(result, arg)
+			continue
+		}
+		if !hasValue {
+			// The value is the next argument
+			i++
+		}
+	}
+	return result
+}
+
+// safeShellWord matches arguments the remote shell takes as they are
+var safeShellWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes an argument for the shell ssh runs the command with
+func shellQuote(arg string) string {
+	if safeShellWord.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// hostResult is the outcome of a command on one host
+type hostResult struct {
+	host string
+	err  error
+}
+
+// runOnHosts runs the command line with pkgs on each host over SSH, at most
+// --parallel at a time, prefixing the output with the host name. Nobody can
+// answer prompts on several hosts at once, so commands that change the
+// system need --yes, --dry-run or --assume-no.
+func runOnHosts(cmd *cobra.Command, hosts []string) error {
+	args := stripHostFlags(os.Args[1:])
+	if commandNeedsRoot(cmd, args) && !IsYesMode() && !IsDryRun() && !IsAssumeNo() {
+		return fmt.Errorf("prompts cannot be answered on remote hosts, add --yes, --dry-run or --assume-no")
+	}
+	// Yes mode may come from the environment or the configuration of this machine
+	if IsYesMode() && !yesFlag {
+		args = append([]string{"--yes"}, args...)
+	}
+	quoted := make([]string, 0, len(args)+1)
+	quoted = append(quoted, "pkgs")
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	remoteCommand := strings.Join(quoted, " ")
+
+	width := 0
+	for _, host := range hosts {
+		width = max(width, len(host))
+	}
+
+	var output sync.Mutex
+	results := make([]hostResult, len(hosts))
+	slots := make(chan struct{}, parallelFlag)
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			prefix := colorize(fmt.Sprintf("%-*s", width, host), hostColors[i%len(hostColors)]) + " | "
+			results[i] = hostResult{host: host, err: runOnHost(host, remoteCommand, prefix, &output)}
+		}()
+	}
+	wg.Wait()
+
+	return printHostResults(results)
+}
+
+// runOnHost runs a command on a host over SSH, with its output behind the
+// prefix
+func runOnHost(host, remoteCommand, prefix string, output *sync.Mutex) error {
+	// BatchMode fails instead of asking for a password nobody can type
+	ssh := newCommand("ssh", "-o", "BatchMode=yes", "--", host, remoteCommand)
+	stdout := &prefixWriter{w: os.Stdout, prefix: prefix, mu: output}
+	stderr := &prefixWriter{w: os.Stderr, prefix: prefix, mu: output}
+	ssh.Stdout, ssh.Stderr = stdout, stderr
+	err := runChild(ssh)
+	stdout.flush()
+	stderr.flush()
+	return err
+}
+
+// prefixWriter writes whole lines behind a prefix, so that the lines of
+// several hosts do not mix
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(data), nil
+		}
+		p.writeLine(p.buf[:i])
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// flush writes the last line if it has no newline
+func (p *prefixWriter) flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(p.buf)
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "%s%s\n", p.prefix, line)
+}
+
+// printHostResults summarizes the outcome on each host, and fails if the
+// command failed on any of them
+func printHostResults(results []hostResult) error {
+	fmt.Println()
+	rows := make([][]string, 0, len(results))
+	failed := 0
+	for _, r := range results {
+		status := colorize("ok", colorGreen)
+		if r.err != nil {
+			status = colorize("failed: "+r.err.Error(), colorYellow)
+			failed++
+		}
+		rows = append(rows, []string{r.host, status})
+	}
+	printTable([]string{"HOST", "RESULT"}, rows)
+
+	if failed > 0 {
+		return withExitCode(ExitPartialFailure, fmt.Errorf("the command failed on %d of %d host(s)", failed, len(results)))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// HTTPConfig configures pkgs' own downloads of keys, repository files and
+// metadata. The package managers keep their own proxy settings.
+type HTTPConfig struct {
+	// Proxy is the proxy URL, instead of the http_proxy/https_proxy variables
+	Proxy string `yaml:"proxy"`
+	// CACert is a PEM bundle of certificate authorities trusted in addition
+	// to the system ones, e.g. for a TLS-intercepting proxy
+	CACert string `yaml:"ca-cert"`
+	// ClientCert and ClientKey are a PEM certificate and key to authenticate
+	// to repositories requiring it
+	ClientCert string `yaml:"client-cert"`
+	ClientKey  string `yaml:"client-key"`
+	// InsecureSkipVerify disables the verification of server certificates,
+	// nil when not set so that a later file can turn it back off
+	InsecureSkipVerify *bool `yaml:"insecure-skip-verify"`
+}
+
+// HTTP flags, overriding the configuration
+var (
+	proxyFlag              string
+	caCertFlag             string
+	insecureSkipVerifyFlag bool
+)
+
+// httpTransport is shared by all of pkgs' HTTP clients. It is set up on
+// first use, once the flags and the configuration are read.
+var httpTransport http.RoundTripper = &configuredTransport{}
+
+// httpClient downloads files without a time limit, large downloads take time
+var httpClient = &http.Client{Transport: httpTransport}
+
+// configuredTransport sets up an http.Transport from the HTTP configuration
+type configuredTransport struct {
+	once      sync.Once
+	transport *http.Transport
+	err       error
+}
+
+func (t *configuredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(func() {
+		t.transport, t.err = newHTTPTransport()
+	})
+	if t.err != nil {
+		return nil, t.err
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// httpConfig returns the HTTP configuration with the flags applied
+func httpConfig() (HTTPConfig, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return HTTPConfig{}, err
+	}
+	settings := config.HTTP
+	if proxyFlag != "" {
+		settings.Proxy = proxyFlag
+	}
+	if caCertFlag != "" {
+		settings.CACert = caCertFlag
+	}
+	if insecureSkipVerifyFlag {
+		settings.InsecureSkipVerify = &insecureSkipVerifyFlag
+	}
+	return settings, nil
+}
+
+// newHTTPTransport creates the transport for the HTTP configuration
+func newHTTPTransport() (*http.Transport, error) {
+	settings, err := httpConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if settings.Proxy != "" {
+		proxyURL, err := url.Parse(settings.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL '%s'", settings.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: settings.InsecureSkipVerify != nil && *settings.InsecureSkipVerify}
+	if settings.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(settings.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", settings.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if settings.ClientCert != "" || settings.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(settings.ClientCert, settings.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
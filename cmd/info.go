@@ -1,34 +1,398 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 )
 
+// PackageInfo holds package metadata in a normalized form
+type PackageInfo struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Arch        string `json:"arch,omitempty"`
+	Size        string `json:"size,omitempty"`
+	License     string `json:"license,omitempty"`
+	Homepage    string `json:"homepage,omitempty"`
+	Description string `json:"description,omitempty"`
+	Repo        string `json:"repo,omitempty"`
+}
+
 // infoCmd represents the info command
 var infoCmd = &cobra.Command{
 	Use:     "info [packages...]",
 	Aliases: []string{"show"},
 	Short:   "Show package information",
-	Long:    `Display detailed information about one or more packages using the native package manager.`,
+	Long: `Display detailed information about one or more packages using the native package manager.
+
+The native output is parsed into a common set of fields (name, version, arch,
+size, license, homepage, description, repo) that look the same on every system.
+Use --json for machine-readable output or --raw to see the package manager's
+own output unchanged.`,
 	Example: `  pkgs info nginx
-  pkgs info vim git`,
-	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+  pkgs info vim git
+  pkgs info --json nginx
+  pkgs info --raw nginx`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completePackageNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
+		}
+
+		args, err := translatePackageNames(pm, args)
+		if err != nil {
+			return err
+		}
+
+		// Raw mode streams the native output as-is
+		if raw, _ := cmd.Flags().GetBool("raw"); raw {
+			fmt.Printf("Using package manager: %s\n", pm.Name)
+			return ExecuteCommand(pm, "info", args)
 		}
 
-		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "info", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		var infos []PackageInfo
+		failed := 0
+		for _, name := range args {
+			info, err := getPackageInfo(pm, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed++
+				continue
+			}
+			infos = append(infos, *info)
 		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := printJSON(infos); err != nil {
+				return err
+			}
+		} else {
+			for i, info := range infos {
+				if i > 0 {
+					fmt.Println()
+				}
+				printPackageInfo(info)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d packages could not be found", failed, len(args))
+		}
+		return nil
 	},
 }
 
+// printPackageInfo prints the fields of a package as aligned "Key: value" lines
+func printPackageInfo(info PackageInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fields := [][2]string{
+		{"Name", info.Name},
+		{"Version", info.Version},
+		{"Arch", info.Arch},
+		{"Size", info.Size},
+		{"License", info.License},
+		{"Homepage", info.Homepage},
+		{"Repo", info.Repo},
+		{"Description", info.Description},
+	}
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		// Continuation lines are tab-prefixed so they stay in the value column
+		fmt.Fprintf(w, "%s:\t%s\n", field[0], strings.ReplaceAll(field[1], "\n", "\n\t"))
+	}
+	w.Flush()
+}
+
+// getPackageInfo queries the native package manager and parses its output
+func getPackageInfo(pm *PackageManager, name string) (*PackageInfo, error) {
+	switch pm.Type {
+	case "debian":
+		return getPackageInfoApt(pm, name)
+	case "redhat":
+		return getPackageInfoDnfYum(pm, name)
+	case "alpine":
+		return getPackageInfoApk(name)
+	case "arch":
+		return getPackageInfoPacman(name)
+	case "macos":
+		return getPackageInfoBrew(name)
+	default:
+		return nil, unsupportedError("package info is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// parseFieldBlocks parses "Key: value" style output into blocks separated by
+// blank lines. Indented lines continue the previous value, which covers the
+// multi-line descriptions of apt, dnf and pacman.
+func parseFieldBlocks(output string) []map[string]string {
+	var blocks []map[string]string
+	current := map[string]string{}
+	lastKey := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = map[string]string{}
+			}
+			lastKey = ""
+			continue
+		}
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && lastKey != "" {
+			cont := strings.TrimSpace(line)
+			// dnf prefixes continuation lines with ": "
+			if after, found := strings.CutPrefix(cont, ":"); found {
+				cont = strings.TrimSpace(after)
+			}
+			if cont == "." {
+				cont = ""
+			}
+			current[lastKey] = strings.TrimSpace(current[lastKey] + "\n" + cont)
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		lastKey = strings.TrimSpace(key)
+		current[lastKey] = strings.TrimSpace(value)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+// formatSize renders a byte count in a human-readable form
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// getPackageInfoApt parses "apt show" (or "apt-cache show" for apt-get)
+func getPackageInfoApt(pm *PackageManager, name string) (*PackageInfo, error) {
+	bin, args := "apt", []string{"show", name}
+	if pm.Name == "apt-get" {
+		bin, args = "apt-cache", []string{"show", name}
+	}
+
+	output, err := runCommandOutput(bin, args...)
+	blocks := parseFieldBlocks(output)
+	if err != nil || len(blocks) == 0 {
+		return nil, fmt.Errorf("package '%s' not found", name)
+	}
+
+	fields := blocks[0]
+	info := &PackageInfo{
+		Name:        fields["Package"],
+		Version:     fields["Version"],
+		Arch:        fields["Architecture"],
+		Homepage:    fields["Homepage"],
+		Description: fields["Description"],
+		License:     debianLicense(fields["Package"]),
+	}
+	// apt show omits the architecture, dpkg knows it for installed packages
+	if info.Arch == "" {
+		if arch, err := runCommandOutput("dpkg-query", "-W", "-f=${Architecture}", info.Name); err == nil {
+			info.Arch = strings.TrimSpace(arch)
+		}
+	}
+	if kb, err := strconv.ParseInt(strings.Fields(fields["Installed-Size"] + " 0")[0], 10, 64); err == nil && kb > 0 {
+		info.Size = formatSize(kb * 1024)
+	} else {
+		info.Size = fields["Installed-Size"]
+	}
+	// APT-Sources looks like "http://archive.ubuntu.com/ubuntu jammy/main amd64 Packages"
+	if sources := strings.Fields(fields["APT-Sources"]); len(sources) > 1 {
+		info.Repo = sources[1]
+	}
+	return info, nil
+}
+
+// debianLicense reads the first license from an installed package's
+// machine-readable copyright file
+func debianLicense(name string) string {
+	content, err := readSystemFile("/usr/share/doc/" + name + "/copyright")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if license, found := strings.CutPrefix(line, "License:"); found && strings.TrimSpace(license) != "" {
+			return strings.TrimSpace(license)
+		}
+	}
+	return ""
+}
+
+// getPackageInfoDnfYum parses "dnf info" / "yum info"
+func getPackageInfoDnfYum(pm *PackageManager, name string) (*PackageInfo, error) {
+	output, err := runCommandOutput(pm.Bin, "info", name)
+	var fields map[string]string
+	for _, block := range parseFieldBlocks(output) {
+		if block["Name"] != "" {
+			fields = block
+			break
+		}
+	}
+	if err != nil || fields == nil {
+		return nil, fmt.Errorf("package '%s' not found", name)
+	}
+
+	info := &PackageInfo{
+		Name:        fields["Name"],
+		Version:     fields["Version"],
+		Arch:        fields["Architecture"],
+		Size:        fields["Size"],
+		License:     fields["License"],
+		Homepage:    fields["URL"],
+		Description: fields["Description"],
+		Repo:        fields["Repository"],
+	}
+	if fields["Release"] != "" {
+		info.Version += "-" + fields["Release"]
+	}
+	if info.Arch == "" {
+		info.Arch = fields["Arch"]
+	}
+	if info.Size == "" {
+		info.Size = fields["Installed size"]
+	}
+	if fields["From repo"] != "" {
+		info.Repo = fields["From repo"]
+	}
+	return info, nil
+}
+
+// getPackageInfoApk parses "apk info -a", which prints one block per field:
+//
+//	nginx-1.24.0-r7 description:
+//	HTTP and reverse proxy server
+func getPackageInfoApk(name string) (*PackageInfo, error) {
+	output, err := runCommandOutput("apk", "info", "-a", name)
+	if err != nil || strings.TrimSpace(output) == "" {
+		return nil, fmt.Errorf("package '%s' not found", name)
+	}
+
+	info := &PackageInfo{Name: name}
+	field := ""
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			field = ""
+			continue
+		}
+		if strings.HasSuffix(line, ":") && strings.Contains(line, " ") && field == "" {
+			nameVersion, label, _ := strings.Cut(strings.TrimSuffix(line, ":"), " ")
+			_, info.Version = splitApkNameVersion(nameVersion)
+			field = label
+			continue
+		}
+
+		switch field {
+		case "description":
+			info.Description = line
+		case "webpage":
+			info.Homepage = line
+		case "installed size":
+			info.Size = line
+		case "license":
+			info.License = line
+		}
+	}
+	return info, nil
+}
+
+// getPackageInfoPacman parses "pacman -Qi", falling back to "pacman -Si" for
+// packages that are not installed
+func getPackageInfoPacman(name string) (*PackageInfo, error) {
+	output, err := runCommandOutput("pacman", "-Qi", name)
+	if err != nil {
+		output, err = runCommandOutput("pacman", "-Si", name)
+	}
+	blocks := parseFieldBlocks(output)
+	if err != nil || len(blocks) == 0 {
+		return nil, fmt.Errorf("package '%s' not found", name)
+	}
+
+	fields := blocks[0]
+	info := &PackageInfo{
+		Name:        fields["Name"],
+		Version:     fields["Version"],
+		Arch:        fields["Architecture"],
+		Size:        fields["Installed Size"],
+		License:     fields["Licenses"],
+		Homepage:    fields["URL"],
+		Description: fields["Description"],
+		Repo:        fields["Repository"],
+	}
+	if info.Repo == "" {
+		info.Repo = "local"
+	}
+	return info, nil
+}
+
+// getPackageInfoBrew parses "brew info --json=v2"
+func getPackageInfoBrew(name string) (*PackageInfo, error) {
+	output, err := runCommandOutput("brew", "info", "--json=v2", name)
+	if err != nil {
+		return nil, fmt.Errorf("package '%s' not found", name)
+	}
+
+	var data struct {
+		Formulae []struct {
+			Name     string `json:"name"`
+			Desc     string `json:"desc"`
+			Homepage string `json:"homepage"`
+			License  string `json:"license"`
+			Tap      string `json:"tap"`
+			Versions struct {
+				Stable string `json:"stable"`
+			} `json:"versions"`
+		} `json:"formulae"`
+		Casks []struct {
+			Token    string `json:"token"`
+			Desc     string `json:"desc"`
+			Homepage string `json:"homepage"`
+			Tap      string `json:"tap"`
+			Version  string `json:"version"`
+		} `json:"casks"`
+	}
+	if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse brew output: %v", err)
+	}
+
+	if len(data.Formulae) > 0 {
+		f := data.Formulae[0]
+		return &PackageInfo{Name: f.Name, Version: f.Versions.Stable, License: f.License, Homepage: f.Homepage, Description: f.Desc, Repo: f.Tap}, nil
+	}
+	if len(data.Casks) > 0 {
+		c := data.Casks[0]
+		return &PackageInfo{Name: c.Token, Version: c.Version, Homepage: c.Homepage, Description: c.Desc, Repo: c.Tap}, nil
+	}
+	return nil, fmt.Errorf("package '%s' not found", name)
+}
+
 func init() {
 	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().Bool("json", false, "Output package information as JSON")
+	infoCmd.Flags().Bool("raw", false, "Show the native package manager output unchanged")
 }
@@ -11,24 +11,114 @@ var installCmd = &cobra.Command{
 	Use:     "install [packages...]",
 	Aliases: []string{"i", "in", "add"},
 	Short:   "Install packages",
-	Long:    `Install one or more packages on the system using the native package manager.`,
+	Long: `Install one or more packages on the system using the native package manager.
+Package groups are installed with an "@" prefix, see 'pkgs groups'. Package
+files on disk are installed like with 'pkgs install-local'.
+
+A specific version is installed with name@version or name=version. A version
+prefix such as nginx@1.24 selects the newest available 1.24 release:
+
+  apt:    nginx=1.24.0-1, or nginx=1.24* for a prefix
+  dnf:    nginx-1.24.0-1.fc39, or nginx-1.24* for a prefix
+  apk:    nginx=1.24.0-r0, or nginx~1.24 for a prefix
+  pacman: an exact version from the package cache, like 'pkgs downgrade'
+  brew:   the versioned formula, e.g. python@3.11
+
+See 'pkgs downgrade --list package' for the available versions.
+
+An architecture is selected with name:arch or name.arch, e.g. libc6:i386 or
+glibc.i686, and translated to name:arch for apt and name.arch for dnf. apk,
+pacman and brew only install packages for the native architecture.
+
+--from-repo installs packages from a repository that is normally disabled or
+has a lower priority, without changing the configuration:
+
+  apt:    apt install -t release (e.g. bookworm-backports)
+  dnf:    dnf install --enablerepo=repo (e.g. epel-testing)
+  apk:    apk add name@tag, for a repository tagged in /etc/apk/repositories
+  pacman: pacman -S repo/name
+  brew:   brew install user/tap/name`,
 	Example: `  pkgs install nginx
-  pkgs install vim git curl`,
-	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+  pkgs install vim git curl
+  pkgs install @development-tools
+  pkgs install nginx@1.24
+  pkgs install libc6:i386
+  pkgs install --from-repo bookworm-backports golang
+  pkgs install ./foo_1.0_amd64.deb`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completePackageNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
+		}
+
+		args, err := translatePackageNames(pm, args)
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			fmt.Println("Nothing to install, the packages are not needed on this system.")
+			return nil
+		}
+		args, err = translateArchQualifiers(pm, args)
+		if err != nil {
+			return err
+		}
+		args, err = translateGroupArgs(pm, args)
+		if err != nil {
+			return err
+		}
+		args, err = translateVersionPins(pm, args)
+		if err != nil {
+			return err
 		}
 
 		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "install", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		if repo, _ := cmd.Flags().GetString("from-repo"); repo != "" {
+			if hasLocalPackageFiles(pm, args) {
+				return fmt.Errorf("--from-repo cannot be used with package files")
+			}
+			return installFromRepo(pm, repo, args)
 		}
+		if hasLocalPackageFiles(pm, args) {
+			return installLocal(pm, args)
+		}
+		return ExecuteCommand(pm, "install", args)
 	},
 }
 
+// installFromRepo installs packages from the given repository for this one
+// transaction, leaving the repository configuration unchanged
+func installFromRepo(pm *PackageManager, repo string, args []string) error {
+	var fullCmd []string
+	switch pm.Type {
+	case "debian":
+		fullCmd = []string{"install", "-t", repo}
+	case "redhat":
+		fullCmd = []string{"install", "--enablerepo=" + repo}
+	case "alpine":
+		return ExecuteCommand(pm, "install", qualifyPackages(args, "", "@"+repo))
+	case "arch", "macos":
+		return ExecuteCommand(pm, "install", qualifyPackages(args, repo+"/", ""))
+	default:
+		return unsupportedError("installing from a specific repository is not supported for package manager '%s'", pm.Name)
+	}
+	addYesFlagIfNeeded(pm, &fullCmd)
+	return executeNative(pm.Bin, append(fullCmd, args...)...)
+}
+
+// qualifyPackages adds a repository prefix or suffix to every package name
+func qualifyPackages(args []string, prefix, suffix string) []string {
+	qualified := make([]string, 0, len(args))
+	for _, arg := range args {
+		qualified = append(qualified, prefix+arg+suffix)
+	}
+	return qualified
+}
+
 func init() {
 	rootCmd.AddCommand(installCmd)
+
+	installCmd.Flags().String("from-repo", "", "Install from this repository (apt release, dnf repo id, apk tag, pacman repo or Homebrew tap)")
 }
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// localPackageExtensions maps package manager types to the file names they can install
+var localPackageExtensions = map[string][]string{
+	"debian": {".deb"},
+	"redhat": {".rpm"},
+	"alpine": {".apk"},
+	"arch":   {".pkg.tar.zst", ".pkg.tar.xz", ".pkg.tar.gz", ".pkg.tar"},
+	"macos":  {".rb"},
+}
+
+// installLocalCmd represents the install-local command
+var installLocalCmd = &cobra.Command{
+	Use:   "install-local [files...]",
+	Short: "Install packages from local package files",
+	Long: `Install packages from package files on disk. Dependencies are resolved
+from the configured repositories. 'pkgs install' does the same automatically
+when an argument is an existing package file.
+
+For apt-based systems (Debian/Ubuntu):
+  apt install ./package.deb
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf install ./package.rpm
+
+For Alpine Linux:
+  apk add --allow-untrusted ./package.apk
+
+For Arch Linux:
+  pacman -U ./package.pkg.tar.zst
+
+For Homebrew (macOS):
+  brew install --formula ./formula.rb`,
+	Example: `  pkgs install-local ./google-chrome-stable_current_amd64.deb
+  pkgs install ./foo-1.0-1.x86_64.rpm`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		for _, arg := range args {
+			if !fileExists(arg) {
+				return fmt.Errorf("package file %s does not exist", arg)
+			}
+			if !isLocalPackageFile(pm, arg) {
+				return fmt.Errorf("%s is not a package file for %s (expected %s)",
+					arg, pm.Name, strings.Join(localPackageExtensions[pm.Type], ", "))
+			}
+		}
+
+		fmt.Printf("Using package manager: %s\n", pm.Name)
+		return installLocal(pm, args)
+	},
+}
+
+// isLocalPackageFile reports whether arg names an existing package file the
+// package manager can install
+func isLocalPackageFile(pm *PackageManager, arg string) bool {
+	for _, ext := range localPackageExtensions[pm.Type] {
+		if strings.HasSuffix(arg, ext) {
+			return fileExists(arg)
+		}
+	}
+	return false
+}
+
+// hasLocalPackageFiles reports whether any argument is a local package file
+func hasLocalPackageFiles(pm *PackageManager, args []string) bool {
+	for _, arg := range args {
+		if isLocalPackageFile(pm, arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// installLocal installs package files, possibly mixed with repository packages
+// where the package manager allows it
+func installLocal(pm *PackageManager, args []string) error {
+	// Package managers only treat arguments as files when they contain a path
+	resolved := make([]string, 0, len(args))
+	local := 0
+	for _, arg := range args {
+		if isLocalPackageFile(pm, arg) {
+			abs, err := filepath.Abs(arg)
+			if err != nil {
+				return fmt.Errorf("invalid path %s: %v", arg, err)
+			}
+			arg = abs
+			local++
+		}
+		resolved = append(resolved, arg)
+	}
+
+	var fullCmd []string
+	switch pm.Type {
+	case "debian", "redhat":
+		fullCmd = []string{"install"}
+	case "alpine":
+		// Locally built packages are usually not signed with a trusted key
+		fullCmd = []string{"add", "--allow-untrusted"}
+	case "arch":
+		if local != len(args) {
+			return fmt.Errorf("pacman cannot install package files and repository packages together, run 'pkgs install' separately for the repository packages")
+		}
+		fullCmd = []string{"-U"}
+	case "macos":
+		if local != len(args) {
+			return fmt.Errorf("Homebrew cannot install formula files and other packages together, run 'pkgs install' separately for the other packages")
+		}
+		fullCmd = []string{"install", "--formula"}
+	default:
+		return unsupportedError("installing package files is not supported for package manager '%s'", pm.Name)
+	}
+
+	addYesFlagIfNeeded(pm, &fullCmd)
+	return executeNative(pm.Bin, append(fullCmd, resolved...)...)
+}
+
+func init() {
+	rootCmd.AddCommand(installLocalCmd)
+}
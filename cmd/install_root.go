@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// rootFlag is the directory of another system pkgs manages, e.g. a mounted
+// installation or an image being built
+var rootFlag string
+
+// rootDir is the absolute --root once it was checked, the native tools then
+// work on it and the files pkgs edits are the ones under it
+var rootDir string
+
+// rootCommands work on another root: the commands running the package
+// manager, those editing the repository and key files it reads, and the
+// report of their changes
+var rootCommands = []string{
+	"add-key", "add-repo", "autoremove", "changes", "clean", "depends",
+	"disable-repo", "enable-repo", "files", "groups", "info", "install",
+	"installed", "list", "list-repos", "orphans", "outdated", "owns", "raw",
+	"reinstall", "remove", "remove-repo", "search", "update", "upgrade",
+	"which", "why",
+}
+
+// startRoot checks the directory given with --root and that the command can
+// work on it
+func startRoot(command string) error {
+	if rootFlag == "" || remoteRun {
+		return nil
+	}
+	if IsUserMode() {
+		return fmt.Errorf("--root cannot be combined with --user")
+	}
+	if containerFlag != "" {
+		return fmt.Errorf("--root cannot be combined with --container")
+	}
+	if !slices.Contains(rootCommands, command) {
+		return unsupportedError("'%s' is not supported with --root, only commands running the package manager or editing repositories and keys are", command)
+	}
+	dir, err := filepath.Abs(rootFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --root %s: %v", rootFlag, err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("--root %s is not a directory", rootFlag)
+	}
+	if pm := detectPackageManager(); pm != nil && rootArgs(pm.Bin) == nil {
+		return unsupportedError("--root is not supported for package manager '%s'", pm.Name)
+	}
+	rootDir = dir
+	return nil
+}
+
+// rootArgs returns the options pointing a native tool at --root, nil for the
+// tools that cannot work on another root
+func rootArgs(name string) []string {
+	switch name {
+	case "apt", "apt-get":
+		// dpkg is run in the root, where the maintainer scripts must run too
+		return []string{"-o", "Dir=" + rootDir, "-o", "DPkg::Chroot-Directory=" + rootDir}
+	case "apt-cache", "apt-mark":
+		return []string{"-o", "Dir=" + rootDir}
+	case "dpkg":
+		return []string{"--root=" + rootDir}
+	case "dpkg-query":
+		return []string{"--admindir=" + filepath.Join(rootDir, "var/lib/dpkg")}
+	case "dnf", "yum":
+		return []string{"--installroot=" + rootDir}
+	case "rpm", "apk":
+		return []string{"--root", rootDir}
+	case "pacman":
+		return []string{"--sysroot", rootDir}
+	case "pacman-key":
+		return []string{"--config", filepath.Join(rootDir, "etc/pacman.conf"), "--gpgdir", filepath.Join(rootDir, "etc/pacman.d/gnupg")}
+	}
+	return nil
+}
+
+// systemPath returns where a file of the managed system is, under --root if
+// given. Files keep referring to each other by their paths in the root, e.g.
+// signed-by and gpgkey, so only the reads and writes of pkgs are mapped.
+// Paths already under the root and pkgs' temporary files are left alone.
+func systemPath(path string) string {
+	if rootDir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if path == rootDir || strings.HasPrefix(path, rootDir+"/") || strings.HasPrefix(path, os.TempDir()+"/") {
+		return path
+	}
+	return filepath.Join(rootDir, path)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InstalledPackage is a package installed on the system
+type InstalledPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch,omitempty"`
+}
+
+// listInstalled queries the native package database for all installed packages
+func listInstalled(pm *PackageManager) ([]InstalledPackage, error) {
+	packages := []InstalledPackage{}
+	switch pm.Type {
+	case "debian":
+		output, err := runCommandOutput("dpkg-query", "-W", "-f", "${db:Status-Abbrev} ${Package} ${Version} ${Architecture}\n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed packages: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			// Only "ii" and "hi" are fully installed, removed packages keep "rc"
+			fields := strings.Fields(line)
+			if len(fields) != 4 || len(fields[0]) < 2 || fields[0][1] != 'i' {
+				continue
+			}
+			packages = append(packages, InstalledPackage{Name: fields[1], Version: fields[2], Arch: fields[3]})
+		}
+	case "redhat":
+		output, err := runCommandOutput("rpm", "-qa", "--qf", "%{NAME} %|EPOCH?{%{EPOCH}:}:{}|%{VERSION}-%{RELEASE} %{ARCH}\n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed packages: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			// gpg-pubkey entries are imported keys, not packages
+			if len(fields) != 3 || fields[0] == "gpg-pubkey" {
+				continue
+			}
+			packages = append(packages, InstalledPackage{Name: fields[0], Version: fields[1], Arch: fields[2]})
+		}
+	case "alpine":
+		// Output: "curl-8.5.0-r0 x86_64 {curl} (MIT) [installed]"
+		output, err := runCommandOutput("apk", "list", "--installed")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed packages: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			name, version := splitApkNameVersion(fields[0])
+			packages = append(packages, InstalledPackage{Name: name, Version: version, Arch: fields[1]})
+		}
+	case "arch":
+		output, err := runCommandOutput("pacman", "-Q")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed packages: %w", err)
+		}
+		for _, p := range parseNameVersionLines(output) {
+			packages = append(packages, InstalledPackage{Name: p.Name, Version: p.Version})
+		}
+	case "macos":
+		// Output: "wget 1.21.4 1.24.5", with one version per installed keg
+		for _, kind := range []string{"--formula", "--cask"} {
+			output, err := runCommandOutput("brew", "list", kind, "--versions")
+			if err != nil {
+				return nil, fmt.Errorf("failed to list installed packages: %w", err)
+			}
+			for _, line := range strings.Split(output, "\n") {
+				fields := strings.Fields(line)
+				if len(fields) < 2 {
+					continue
+				}
+				packages = append(packages, InstalledPackage{Name: fields[0], Version: fields[len(fields)-1]})
+			}
+		}
+	default:
+		return nil, unsupportedError("listing installed packages is not supported for package manager '%s'", pm.Name)
+	}
+	return packages, nil
+}
+
+// explicitPackages returns the names of the packages that were installed on request
+// rather than as a dependency
+func explicitPackages(pm *PackageManager) ([]string, error) {
+	var output string
+	var err error
+	switch pm.Name {
+	case "apt", "apt-get":
+		output, err = runCommandOutput("apt-mark", "showmanual")
+	case "dnf":
+		output, err = runCommandOutput("dnf", "-q", "repoquery", "--userinstalled", "--qf", "%{name}\n")
+	case "apk":
+		output, err = readSystemFile(apkWorldFile)
+		var names []string
+		for _, entry := range strings.Fields(output) {
+			// Entries may carry a version constraint or a repository tag
+			if i := strings.IndexAny(entry, "=<>~@"); i > 0 {
+				entry = entry[:i]
+			}
+			names = append(names, entry)
+		}
+		return names, err
+	case "pacman":
+		output, err = runCommandOutput("pacman", "-Qeq")
+	case "brew":
+		output, err = runCommandOutput("brew", "list", "--installed-on-request")
+	default:
+		return nil, unsupportedError("the install reason is not tracked for package manager '%s'", pm.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list explicitly installed packages: %w", err)
+	}
+	return strings.Fields(output), nil
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// maxKeySize bounds the size of downloaded keys, real keys are a few KB
+const maxKeySize = 1 << 20
+
+// fetchKey downloads a key into memory
+func fetchKey(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download key: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download key: bad status: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download key: %v", err)
+	}
+	if len(data) > maxKeySize {
+		return nil, fmt.Errorf("failed to download key: %s is larger than %d bytes", url, maxKeySize)
+	}
+	return data, nil
+}
+
+// parseOpenPGPKeys reads the primary keys of an armored or binary OpenPGP
+// key file
+func parseOpenPGPKeys(data []byte) ([]RepoKey, error) {
+	var entities openpgp.EntityList
+	var err error
+	if bytes.Contains(data, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("not a valid OpenPGP key: %v", err)
+	}
+
+	now := time.Now()
+	keys := make([]RepoKey, 0, len(entities))
+	for _, e := range entities {
+		key := RepoKey{
+			ID:          strings.ToUpper(e.PrimaryKey.KeyIdString()),
+			Fingerprint: strings.ToUpper(hex.EncodeToString(e.PrimaryKey.Fingerprint)),
+			Created:     e.PrimaryKey.CreationTime.UTC().Format("2006-01-02"),
+			Expires:     "never",
+			Expired:     e.Revoked(now),
+		}
+		if identity := e.PrimaryIdentity(); identity != nil {
+			key.Owner = identity.Name
+		}
+		if sig, _ := e.PrimarySelfSignature(); sig != nil {
+			if sig.KeyLifetimeSecs != nil && *sig.KeyLifetimeSecs != 0 {
+				expires := e.PrimaryKey.CreationTime.Add(time.Duration(*sig.KeyLifetimeSecs) * time.Second)
+				key.Expires = expires.UTC().Format("2006-01-02")
+			}
+			key.Expired = key.Expired || e.PrimaryKey.KeyExpired(sig, now)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no OpenPGP key found")
+	}
+	return keys, nil
+}
+
+// parseRSAPublicKey reads an apk key, a PEM encoded public key. Its
+// fingerprint is the SHA-256 digest of the DER encoding.
+func parseRSAPublicKey(data []byte) ([]RepoKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("not a PEM encoded public key")
+	}
+	if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		return nil, fmt.Errorf("not a valid public key: %v", err)
+	}
+	digest := sha256.Sum256(block.Bytes)
+	return []RepoKey{{Fingerprint: "SHA256:" + hex.EncodeToString(digest[:])}}, nil
+}
+
+// printKeyDetails shows what is known about the keys of a key file, so that
+// they can be compared with the ones the vendor publishes
+func printKeyDetails(keys []RepoKey) {
+	for _, k := range keys {
+		if k.ID != "" {
+			fmt.Printf("Key ID:      %s\n", k.ID)
+		}
+		fmt.Printf("Fingerprint: %s\n", k.Fingerprint)
+		if k.Owner != "" {
+			fmt.Printf("User ID:     %s\n", k.Owner)
+		}
+		if k.Created != "" {
+			expires := k.Expires
+			if k.Expired {
+				expires = colorize(expires+" (expired)", colorYellow)
+			}
+			fmt.Printf("Created:     %s, expires: %s\n", k.Created, expires)
+		}
+	}
+}
+
+// keyPin is what a downloaded key is expected to be, as given with
+// --sha256 and --fingerprint
+type keyPin struct {
+	SHA256      string
+	Fingerprint string
+	KeyID       string // fingerprint or key ID requested from a keyserver
+}
+
+// isSet reports whether the key is pinned well enough to be installed
+// without asking. Key IDs can collide and are not enough on their own.
+func (p keyPin) isSet() bool {
+	return p.SHA256 != "" || p.Fingerprint != ""
+}
+
+// verify checks a downloaded key file and its keys against the pin
+func (p keyPin) verify(data []byte, keys []RepoKey) error {
+	if p.SHA256 != "" {
+		digest := sha256.Sum256(data)
+		want := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(p.SHA256)), "sha256:")
+		if got := hex.EncodeToString(digest[:]); got != want {
+			return fmt.Errorf("SHA-256 digest is %s, expected %s", got, want)
+		}
+	}
+	if p.KeyID != "" {
+		for _, k := range keys {
+			if !strings.HasSuffix(normalizeFingerprint(k.Fingerprint), p.KeyID) {
+				return fmt.Errorf("the keyserver returned key %s instead of %s", k.Fingerprint, p.KeyID)
+			}
+		}
+	}
+	if p.Fingerprint != "" {
+		want := normalizeFingerprint(p.Fingerprint)
+		// Every key must match, or further keys could be slipped in with the
+		// expected one
+		for _, k := range keys {
+			if got := normalizeFingerprint(k.Fingerprint); got != want {
+				return fmt.Errorf("key fingerprint is %s, expected %s", got, want)
+			}
+		}
+	}
+	return nil
+}
+
+// keyserverURL returns the HKPS lookup URL of a key. The keyserver may be
+// given as a host name or as an hkps:// or https:// URL.
+func keyserverURL(server, id string) (string, error) {
+	id = normalizeFingerprint(id)
+	if len(id) < 16 {
+		return "", fmt.Errorf("key ID %s is too short, use the 16 digit key ID or the fingerprint", id)
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", fmt.Errorf("invalid key ID or fingerprint %s", id)
+	}
+
+	host := strings.TrimSuffix(server, "/")
+	if strings.Contains(host, "://") {
+		scheme, rest, _ := strings.Cut(host, "://")
+		if scheme != "hkps" && scheme != "https" {
+			return "", fmt.Errorf("keyserver %s does not use HKPS, keys must be fetched over TLS", server)
+		}
+		host = rest
+	}
+	if host == "" {
+		return "", fmt.Errorf("no keyserver given")
+	}
+	return fmt.Sprintf("https://%s/pks/lookup?op=get&options=mr&search=0x%s", host, id), nil
+}
+
+// normalizeFingerprint drops the spaces, 0x and SHA256: prefixes and case
+// differences of the ways fingerprints are written
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+	fingerprint = strings.TrimPrefix(fingerprint, "0X")
+	return strings.TrimPrefix(fingerprint, "SHA256:")
+}
+
+// trustKey downloads a key, shows its details and asks the user to trust it,
+// unless it matches pin. parse is parseOpenPGPKeys or parseRSAPublicKey,
+// depending on the format the package manager expects.
+func trustKey(url string, parse func([]byte) ([]RepoKey, error), pin keyPin) ([]byte, []RepoKey, error) {
+	data, err := fetchKey(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, err := parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("refusing key from %s: %v", url, err)
+	}
+	if err := pin.verify(data, keys); err != nil {
+		return nil, nil, fmt.Errorf("refusing key from %s: %v", url, err)
+	}
+	if pin.isSet() {
+		printKeyDetails(keys)
+		fmt.Println("Key matches the expected digest or fingerprint.")
+		return data, keys, nil
+	}
+	if err := confirmKeys(keys); err != nil {
+		return nil, nil, err
+	}
+	return data, keys, nil
+}
+
+// confirmKeys shows the details of keys and asks the user to trust them,
+// unless -y was given
+func confirmKeys(keys []RepoKey) error {
+	printKeyDetails(keys)
+	for _, k := range keys {
+		if k.Expired {
+			fmt.Printf("Warning: key %s has expired or was revoked\n", k.Fingerprint)
+		}
+	}
+	if !askForConfirmation("Trust packages signed with this key?") {
+		return errCancelled
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// KeyCheck is the expiry status of a trusted key as written by
+// 'pkgs keys-check --json'
+type KeyCheck struct {
+	Key         string   `json:"key"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+	Expires     string   `json:"expires,omitempty"`
+	Status      string   `json:"status"`
+	Repos       []string `json:"repos"`
+	File        string   `json:"file,omitempty"`
+	Detail      string   `json:"detail,omitempty"`
+}
+
+// keysCheckCmd represents the keys-check command
+var keysCheckCmd = &cobra.Command{
+	Use:   "keys-check",
+	Short: "Check the trusted repository keys for expiry",
+	Long: `Check every trusted repository key for expiry, and show the repositories
+signed with it. Expired keys are a common cause of updates failing suddenly
+with signature errors; keys expiring within --days are reported so that they
+can be refreshed in time, usually by fetching the key again from the vendor.
+
+For apt-based systems (Debian/Ubuntu):
+  Checks the keys in /etc/apt/keyrings/, /etc/apt/trusted.gpg.d/ and
+  /etc/apt/trusted.gpg, and the key files referenced with signed-by
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  Checks the keys in /etc/pki/rpm-gpg/ and the file:// gpgkey files
+
+For Arch Linux:
+  Checks the keys of the pacman keyring, which sign all repositories
+
+pkgs exits with an error if any key has expired or cannot be read.`,
+	Example: `  pkgs keys-check
+  pkgs keys-check --days 90
+  pkgs keys-check --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+		days, _ := cmd.Flags().GetInt("days")
+		if days < 0 {
+			return fmt.Errorf("--days must not be negative")
+		}
+
+		var checks []KeyCheck
+		var err error
+		switch pm.Type {
+		case "debian", "redhat":
+			checks, err = checkKeyFiles(pm, days)
+		case "arch":
+			checks, err = checkKeysPacman(pm, days)
+		case "alpine":
+			return unsupportedError("apk keys are RSA keys without an expiry date")
+		default:
+			return unsupportedError("checking keys is not supported for package manager '%s'", pm.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		problems := 0
+		for _, c := range checks {
+			if c.Status == "expired" || c.Status == "error" {
+				problems++
+			}
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if checks == nil {
+				checks = []KeyCheck{}
+			}
+			if err := printJSON(checks); err != nil {
+				return err
+			}
+		} else {
+			if len(checks) == 0 {
+				fmt.Println("No trusted keys found.")
+				return nil
+			}
+			rows := make([][]string, 0, len(checks))
+			for _, c := range checks {
+				status := colorize(c.Status, colorYellow)
+				if c.Status == "ok" {
+					status = colorize(c.Status, colorGreen)
+				}
+				repos := strings.Join(c.Repos, ", ")
+				if c.Detail != "" {
+					repos = c.Detail
+				}
+				rows = append(rows, []string{status, c.Key, c.Owner, c.Expires, repos})
+			}
+			printTable([]string{"STATUS", "KEY", "OWNER", "EXPIRES", "REPOSITORIES"}, rows)
+		}
+
+		if problems > 0 {
+			return fmt.Errorf("%d of %d keys have expired or cannot be read", problems, len(checks))
+		}
+		return nil
+	},
+}
+
+// keyStatus classifies a key as "ok", "expiring" within days or "expired"
+func keyStatus(k RepoKey, days int) string {
+	if k.Expired {
+		return "expired"
+	}
+	expires, err := time.Parse("2006-01-02", k.Expires)
+	if err != nil {
+		// Keys that never expire
+		return "ok"
+	}
+	if expires.Before(time.Now().AddDate(0, 0, days)) {
+		return "expiring"
+	}
+	return "ok"
+}
+
+// keyFilesToCheck returns the key files of apt or dnf/yum systems
+func keyFilesToCheck(pm *PackageManager, entries []repoEntry) []string {
+	if pm.Type == "debian" {
+		return aptKeyFiles()
+	}
+	files, _ := filepath.Glob("/etc/pki/rpm-gpg/*")
+	for _, e := range entries {
+		if e.Key != "" && fileExists(e.Key) {
+			files = append(files, e.Key)
+		}
+	}
+	return uniqueSorted(files)
+}
+
+// checkKeyFiles checks the keys of the key files apt or dnf/yum trust
+func checkKeyFiles(pm *PackageManager, days int) ([]KeyCheck, error) {
+	entries, err := listRepos(pm)
+	if entries == nil && err != nil {
+		return nil, err
+	}
+
+	var checks []KeyCheck
+	for _, file := range keyFilesToCheck(pm, entries) {
+		repos := keyFileRepos(pm, file, entries)
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+		data, readErr := os.ReadFile(file)
+		var keys []RepoKey
+		if readErr == nil {
+			keys, readErr = parseOpenPGPKeys(data)
+		}
+		if readErr != nil {
+			checks = append(checks, KeyCheck{Key: name, Status: "error", Repos: repos, File: file, Detail: readErr.Error()})
+			continue
+		}
+		for _, k := range keys {
+			checks = append(checks, KeyCheck{
+				Key:         name,
+				Fingerprint: k.Fingerprint,
+				Owner:       k.Owner,
+				Expires:     k.Expires,
+				Status:      keyStatus(k, days),
+				Repos:       repos,
+				File:        file,
+			})
+		}
+	}
+	return checks, nil
+}
+
+// keyFileRepos returns the enabled repositories signed with a key file. On
+// apt systems the keyrings in trusted.gpg.d and trusted.gpg sign every
+// repository without signed-by.
+func keyFileRepos(pm *PackageManager, file string, entries []repoEntry) []string {
+	global := pm.Type == "debian" && (file == "/etc/apt/trusted.gpg" || filepath.Dir(file) == "/etc/apt/trusted.gpg.d")
+	repos := []string{}
+	for _, e := range entries {
+		if !e.Enabled {
+			continue
+		}
+		if e.Key == file || (global && e.Key == "") {
+			repos = append(repos, e.ID)
+		}
+	}
+	return uniqueSorted(repos)
+}
+
+// checkKeysPacman checks the keys of the pacman keyring. They sign the
+// packages of every repository, so all are listed for each key.
+func checkKeysPacman(pm *PackageManager, days int) ([]KeyCheck, error) {
+	keys, err := listKeysPacman()
+	if err != nil {
+		return nil, err
+	}
+	entries, _ := listRepos(pm)
+	repos := []string{}
+	for _, e := range entries {
+		if e.Enabled {
+			repos = append(repos, e.ID)
+		}
+	}
+	repos = uniqueSorted(repos)
+
+	checks := make([]KeyCheck, 0, len(keys))
+	for _, k := range keys {
+		checks = append(checks, KeyCheck{
+			Key:         k.ID,
+			Fingerprint: k.Fingerprint,
+			Owner:       k.Owner,
+			Expires:     k.Expires,
+			Status:      keyStatus(k, days),
+			Repos:       repos,
+		})
+	}
+	return checks, nil
+}
+
+func init() {
+	rootCmd.AddCommand(keysCheckCmd)
+
+	keysCheckCmd.Flags().Int("days", 30, "Report keys expiring within this many days")
+	keysCheckCmd.Flags().Bool("json", false, "Print the results as JSON")
+}
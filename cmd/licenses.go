@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// PackageLicense is the declared license of an installed package
+type PackageLicense struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	License string `json:"license"`
+}
+
+// licensesCmd represents the licenses command
+var licensesCmd = &cobra.Command{
+	Use:   "licenses",
+	Short: "List the licenses of installed packages",
+	Long: `List the declared license of every installed package, read from the package
+metadata (see 'pkgs sbom' for the sources used by each package manager).
+Packages without machine-readable license information are shown as "unknown".
+
+With --deny only packages using one of the given licenses are listed and pkgs
+exits with an error if there are any. A denied license matches license names
+that equal it or extend it with a "+" or "-" suffix, ignoring case, so
+"GPL-3.0" matches "GPL-3.0-or-later" and "GPL-3.0+". Use --deny unknown to
+reject packages without license information.`,
+	Example: `  pkgs licenses
+  pkgs licenses --deny GPL-3.0,AGPL-3.0
+  pkgs licenses --deny GPL-3 --deny GPL-3.0 --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		installed, err := listInstalled(pm)
+		if err != nil {
+			return err
+		}
+		licenses, err := packageLicenses(pm, installed)
+		if err != nil {
+			return err
+		}
+		deny, _ := cmd.Flags().GetStringSlice("deny")
+
+		results := []PackageLicense{}
+		for _, p := range installed {
+			license := licenses[p.Name]
+			if license == "" {
+				license = "unknown"
+			}
+			if len(deny) > 0 && !licenseDenied(license, deny) {
+				continue
+			}
+			results = append(results, PackageLicense{Name: p.Name, Version: p.Version, License: license})
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := printJSON(results); err != nil {
+				return err
+			}
+		} else if len(results) == 0 {
+			if len(deny) > 0 {
+				fmt.Println("No installed package uses a denied license.")
+			} else {
+				fmt.Println("No installed packages found.")
+			}
+		} else {
+			defer startPager()()
+			rows := make([][]string, 0, len(results))
+			for _, r := range results {
+				rows = append(rows, []string{r.Name, r.Version, r.License})
+			}
+			printTable([]string{"PACKAGE", "VERSION", "LICENSE"}, rows)
+		}
+
+		if len(deny) > 0 && len(results) > 0 {
+			return fmt.Errorf("%d package(s) use a denied license", len(results))
+		}
+		return nil
+	},
+}
+
+// licenseDenied reports whether a license expression names one of the denied licenses
+func licenseDenied(expression string, deny []string) bool {
+	tokens := strings.FieldsFunc(expression, func(r rune) bool {
+		return r == ' ' || r == '(' || r == ')' || r == ',' || r == '|' || r == '/'
+	})
+	for _, token := range tokens {
+		for _, denied := range deny {
+			denied = strings.TrimSpace(denied)
+			if len(denied) == 0 || len(token) < len(denied) || !strings.EqualFold(token[:len(denied)], denied) {
+				continue
+			}
+			if len(token) == len(denied) || token[len(denied)] == '+' || token[len(denied)] == '-' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(licensesCmd)
+
+	licensesCmd.Flags().StringSlice("deny", nil, "Only list packages using these licenses and fail if there are any")
+	licensesCmd.Flags().Bool("json", false, "Output results as JSON")
+}
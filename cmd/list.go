@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installed packages, or those that can be upgraded",
+	Long: `List the installed packages with their version and architecture, read from
+the native package database.
+
+With --upgradable the packages for which a newer version is available are
+listed instead, like 'pkgs outdated'.`,
+	Example: `  pkgs list
+  pkgs list --upgradable
+  pkgs list --upgradable --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if upgradable, _ := cmd.Flags().GetBool("upgradable"); upgradable {
+			return outdatedCmd.RunE(cmd, args)
+		}
+
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		packages, err := listInstalled(pm)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			return printJSON(packages)
+		}
+
+		defer startPager()()
+
+		if len(packages) == 0 {
+			fmt.Println("No packages installed.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(packages))
+		for _, p := range packages {
+			rows = append(rows, []string{p.Name, p.Version, p.Arch})
+		}
+		printTable([]string{"NAME", "VERSION", "ARCH"}, rows)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().Bool("upgradable", false, "Only list packages that can be upgraded")
+	listCmd.Flags().Bool("json", false, "Output results as JSON")
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// pacmanKeyring is the GnuPG home of pacman-key
+const pacmanKeyring = "/etc/pacman.d/gnupg"
+
+// RepoKey is a trusted repository key as written by 'pkgs list-keys --json'
+type RepoKey struct {
+	Name        string `json:"name"`
+	ID          string `json:"id,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Created     string `json:"created,omitempty"`
+	Expires     string `json:"expires,omitempty"`
+	Expired     bool   `json:"expired"`
+	File        string `json:"file,omitempty"`
+}
+
+// listKeysCmd represents the list-keys command
+var listKeysCmd = &cobra.Command{
+	Use:   "list-keys",
+	Short: "List the trusted repository keys",
+	Long: `List the keys the package manager trusts to sign repositories, with their
+key ID, owner and expiry date where available.
+
+For apt-based systems (Debian/Ubuntu):
+  Lists the keys in /etc/apt/keyrings/, /etc/apt/trusted.gpg.d/ and
+  /etc/apt/trusted.gpg, and the key files referenced with signed-by.
+  Key details are read with gpg.
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  Lists the gpg-pubkey packages imported into the rpm database
+
+For Alpine Linux:
+  Lists the keys in /etc/apk/keys/
+
+For Arch Linux:
+  Lists the keys of the pacman keyring in /etc/pacman.d/gnupg`,
+	Example: `  pkgs list-keys
+  pkgs list-keys --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		keys, err := listKeys(pm)
+		if keys == nil && err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if keys == nil {
+				keys = []RepoKey{}
+			}
+			if jsonErr := printJSON(keys); jsonErr != nil {
+				return jsonErr
+			}
+			return err
+		}
+
+		defer startPager()()
+		rows := make([][]string, 0, len(keys))
+		for _, k := range keys {
+			expires := k.Expires
+			if k.Expired {
+				expires = colorize(expires+" (expired)", colorYellow)
+			}
+			rows = append(rows, []string{k.Name, k.ID, k.Owner, expires, k.File})
+		}
+		printTable([]string{"NAME", "KEY ID", "OWNER", "EXPIRES", "FILE"}, rows)
+		return err
+	},
+}
+
+// listKeys collects the trusted keys of the package manager. On partial
+// failures the readable keys are returned together with the error.
+func listKeys(pm *PackageManager) ([]RepoKey, error) {
+	switch pm.Type {
+	case "debian":
+		return listKeysApt()
+	case "redhat":
+		return listKeysRpm()
+	case "alpine":
+		return listKeysApk()
+	case "arch":
+		return listKeysPacman()
+	default:
+		return nil, unsupportedError("listing keys is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// aptKeyFiles returns the keyring files apt trusts, in /etc/apt/keyrings/,
+// trusted.gpg.d and trusted.gpg, and those referenced by signed-by
+func aptKeyFiles() []string {
+	var files []string
+	for _, pattern := range []string{"/etc/apt/keyrings/*", "/etc/apt/trusted.gpg.d/*"} {
+		matches, _ := filepath.Glob(pattern)
+		files = append(files, matches...)
+	}
+	if fileExists("/etc/apt/trusted.gpg") {
+		files = append(files, "/etc/apt/trusted.gpg")
+	}
+
+	sourceFiles, _ := aptSourceFiles()
+	for _, file := range sourceFiles {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, src := range parseAptSources(file, string(content)) {
+			for _, key := range strings.Split(src.Options["signed-by"], ",") {
+				if key = strings.TrimSpace(key); strings.HasPrefix(key, "/") && fileExists(key) {
+					files = append(files, key)
+				}
+			}
+		}
+	}
+	return uniqueSorted(files)
+}
+
+// listKeysApt lists the keys of the keyring files apt trusts
+func listKeysApt() ([]RepoKey, error) {
+	var keys []RepoKey
+	if _, err := exec.LookPath("gpg"); err != nil {
+		// Without gpg only the files can be listed
+		fmt.Fprintln(os.Stderr, "Warning: gpg is not installed, key details are not shown")
+		for _, file := range aptKeyFiles() {
+			keys = append(keys, RepoKey{Name: strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), File: file})
+		}
+		return keys, nil
+	}
+
+	var scanErrs fileErrors
+	for _, file := range aptKeyFiles() {
+		fileKeys, err := gpgShowKeys(file)
+		if err != nil {
+			scanErrs.add(err)
+			continue
+		}
+		keys = append(keys, fileKeys...)
+	}
+	return keys, scanErrs.err()
+}
+
+// gpgShowKeys reads the keys of a keyring or key file with gpg, without
+// importing them anywhere
+func gpgShowKeys(file string) ([]RepoKey, error) {
+	home, err := os.MkdirTemp("", "pkgs-gpg-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(home)
+
+	output, err := runCommandOutput("gpg", "--batch", "--no-options", "--homedir", home, "--with-colons", "--show-keys", file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys from %s: %v", file, err)
+	}
+	keys := parseGPGColons(output)
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	for i := range keys {
+		keys[i].Name, keys[i].File = name, file
+	}
+	return keys, nil
+}
+
+// parseGPGColons parses the primary keys of gpg --with-colons output
+func parseGPGColons(output string) []RepoKey {
+	var keys []RepoKey
+	var current *RepoKey
+	inPrimary := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 10 {
+			continue
+		}
+		switch fields[0] {
+		case "pub":
+			keys = append(keys, RepoKey{ID: fields[4], Created: gpgDate(fields[5]), Expires: gpgDate(fields[6])})
+			current = &keys[len(keys)-1]
+			inPrimary = true
+			// Validity "e" marks expired keys, "r" revoked ones
+			current.Expired = fields[1] == "e" || fields[1] == "r"
+			if current.Expires == "" {
+				current.Expires = "never"
+			} else if expires, err := time.Parse("2006-01-02", current.Expires); err == nil && expires.Before(time.Now()) {
+				current.Expired = true
+			}
+		case "sub":
+			inPrimary = false
+		case "fpr":
+			if current != nil && inPrimary && current.Fingerprint == "" {
+				current.Fingerprint = fields[9]
+			}
+		case "uid":
+			if current != nil && current.Owner == "" {
+				current.Owner = strings.ReplaceAll(fields[9], `\x3a`, ":")
+			}
+		}
+	}
+	return keys
+}
+
+// gpgDate converts a timestamp of gpg --with-colons output to a date
+func gpgDate(field string) string {
+	seconds, err := strconv.ParseInt(field, 10, 64)
+	if err != nil || seconds == 0 {
+		return ""
+	}
+	return time.Unix(seconds, 0).UTC().Format("2006-01-02")
+}
+
+// listKeysRpm lists the gpg-pubkey packages of the rpm database. Their
+// version is the key ID and their release the creation time in hex.
+func listKeysRpm() ([]RepoKey, error) {
+	output, err := runCommandOutput("rpm", "-q", "gpg-pubkey", "--qf", "%{NAME}-%{VERSION}-%{RELEASE}\t%{VERSION}\t%{RELEASE}\t%{PACKAGER}\n")
+	if err != nil {
+		// rpm fails when no key is installed
+		if strings.Contains(output, "not installed") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list keys: %v", err)
+	}
+
+	var keys []RepoKey
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 4 {
+			continue
+		}
+		key := RepoKey{Name: fields[0], ID: strings.ToUpper(fields[1]), Owner: fields[3]}
+		if created, err := strconv.ParseInt(fields[2], 16, 64); err == nil {
+			key.Created = time.Unix(created, 0).UTC().Format("2006-01-02")
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// listKeysApk lists the keys in /etc/apk/keys/
+func listKeysApk() ([]RepoKey, error) {
+	files, err := filepath.Glob("/etc/apk/keys/*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %v", err)
+	}
+	sort.Strings(files)
+
+	keys := make([]RepoKey, 0, len(files))
+	for _, file := range files {
+		// Keys are named after their owner and an ID, e.g.
+		// alpine-devel@lists.alpinelinux.org-6165ee59.rsa.pub
+		name := strings.TrimSuffix(filepath.Base(file), ".rsa.pub")
+		key := RepoKey{Name: filepath.Base(file), File: file}
+		if owner, id, found := cutLast(name, "-"); found && strings.Contains(owner, "@") {
+			key.Owner, key.ID = owner, id
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// listKeysPacman lists the keys of the pacman keyring
+func listKeysPacman() ([]RepoKey, error) {
+	output, err := runCommandOutput("gpg", "--batch", "--homedir", pacmanKeyring, "--with-colons", "--list-keys")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the keys of %s: %v", pacmanKeyring, err)
+	}
+	keys := parseGPGColons(output)
+	for i := range keys {
+		keys[i].Name = keys[i].ID
+	}
+	return keys, nil
+}
+
+func init() {
+	rootCmd.AddCommand(listKeysCmd)
+
+	listKeysCmd.Flags().Bool("json", false, "Print the keys as JSON")
+}
@@ -1,25 +1,63 @@
 package cmd
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+// repoEntry describes one configured repository
+type repoEntry struct {
+	ID         string
+	Name       string
+	Enabled    bool
+	Status     string
+	URL        string
+	File       string
+	Key        string // local key file the repository is signed with, if any
+	Priority   string
+	Type       string   // kind of repository, e.g. "deb" or "rpm-md"
+	URLs       []string // all base URLs, mirror lists and metalinks
+	Suites     []string
+	Components []string
+	GPGKeys    []string // keys as configured, files or URLs
+}
+
+// Repository is a configured repository as written by 'pkgs list-repos --json'
+type Repository struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name,omitempty"`
+	Type       string   `json:"type"`
+	Enabled    bool     `json:"enabled"`
+	URLs       []string `json:"urls"`
+	Suites     []string `json:"suites,omitempty"`
+	Components []string `json:"components,omitempty"`
+	SourceFile string   `json:"source_file,omitempty"`
+	GPGKeys    []string `json:"gpg_keys,omitempty"`
+	Priority   *int     `json:"priority,omitempty"`
+}
+
 // listReposCmd represents the list-repos command
 var listReposCmd = &cobra.Command{
 	Use:   "list-repos",
 	Short: "List all repositories in the system",
 	Long: `List all repositories in the system package manager with their status (enabled/disabled).
 
+The repositories are shown as a table with the same columns on every system:
+status, repository ID/name, URL and the file that defines the repository.
+On apt and dnf/yum systems the priority set with 'pkgs repo-priority' is
+shown as well. With --json the repositories are written as JSON objects with
+the fields id, name, type, enabled, urls, suites, components, source_file,
+gpg_keys and priority, for inventory and compliance tooling.
+
 For apt-based systems (Debian/Ubuntu):
-  Lists repositories from /etc/apt/sources.list and /etc/apt/sources.list.d/
+  Lists repositories from /etc/apt/sources.list and the one-line .list and
+  deb822 .sources files in /etc/apt/sources.list.d/
 
 For dnf/yum-based systems (Fedora/RHEL/CentOS):
   Lists repositories from /etc/yum.repos.d/
@@ -27,302 +65,379 @@ For dnf/yum-based systems (Fedora/RHEL/CentOS):
 For Alpine Linux:
   Lists repositories from /etc/apk/repositories
 
+For Arch Linux:
+  Lists repositories from /etc/pacman.conf
+
 For Homebrew (macOS):
   Lists all taps`,
 	Example: `  # List all repositories
-  pkgs list-repos`,
-	Run: func(cmd *cobra.Command, args []string) {
+  pkgs list-repos
+
+  # List the enabled repositories with jq
+  pkgs list-repos --json | jq -r '.[] | select(.enabled) | .id'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
-		// List repositories based on package manager
-		switch pm.Type {
-		case "debian":
-			if err := listReposApt(); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-		case "redhat":
-			if err := listReposDnfYum(); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-		case "alpine":
-			if err := listReposAlpine(); err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-		case "arch":
-			if err := listReposPacman(); err != nil {
-				fmt.Printf("Error: %v\n", err)
+		entries, err := listRepos(pm)
+		if entries == nil && err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			repos := make([]Repository, 0, len(entries))
+			for _, e := range entries {
+				repos = append(repos, e.toRepository())
 			}
-		case "macos":
-			if err := listReposHomebrew(); err != nil {
-				fmt.Printf("Error: %v\n", err)
+			if jsonErr := printJSON(repos); jsonErr != nil {
+				return jsonErr
 			}
-		default:
-			fmt.Println("Listing repositories is not supported for this package manager.")
+			return err
 		}
+
+		// Long listings go through the pager when attached to a terminal
+		defer startPager()()
+
+		// Entries are still printed on partial failures, the error follows them
+		printRepoTable(entries)
+		return err
 	},
 }
 
-// listReposApt lists repositories for apt-based systems
-func listReposApt() error {
-	fmt.Println("APT Repositories:")
-	fmt.Println("=================")
-
-	// Check main sources.list file
-	mainSourcesFile := "/etc/apt/sources.list"
-	if _, err := os.Stat(mainSourcesFile); err == nil {
-		content, err := os.ReadFile(mainSourcesFile)
-		if err != nil {
-			return fmt.Errorf("failed to read sources.list: %v", err)
-		}
+// listRepos collects the configured repositories of the package manager. On
+// partial failures the readable entries are returned together with the error.
+func listRepos(pm *PackageManager) ([]repoEntry, error) {
+	switch pm.Type {
+	case "debian":
+		return listReposApt()
+	case "redhat":
+		return listReposDnfYum()
+	case "alpine":
+		return listReposAlpine()
+	case "arch":
+		return listReposPacman()
+	case "macos":
+		return listReposHomebrew()
+	default:
+		return nil, unsupportedError("listing repositories is not supported for package manager '%s'", pm.Name)
+	}
+}
 
-		fmt.Println("\nFrom /etc/apt/sources.list:")
-		lines := strings.Split(string(content), "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "# deb") && !strings.HasPrefix(line, "#deb") {
-				continue
-			}
+// printRepoTable renders repositories as an aligned table
+func printRepoTable(entries []repoEntry) {
+	if len(entries) == 0 {
+		fmt.Println("No repositories found.")
+		return
+	}
 
-			status := "Enabled"
-			statusColor := colorGreen
-			if strings.HasPrefix(line, "#") {
-				status = "Disabled"
-				statusColor = colorYellow
-				// Remove comment for display
-				line = strings.TrimPrefix(strings.TrimPrefix(line, "# "), "#")
-			}
+	// Only some package managers have priorities
+	withPriority := false
+	for _, e := range entries {
+		withPriority = withPriority || e.Priority != ""
+	}
 
-			if strings.HasPrefix(line, "deb ") || strings.HasPrefix(line, "deb-src ") {
-				fmt.Printf("  [%s] %s\n", colorize(status, statusColor), line)
-			}
+	rows := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		statusColor := colorYellow
+		if e.Enabled {
+			statusColor = colorGreen
+		}
+		name := e.ID
+		if e.Name != "" && e.Name != e.ID {
+			name = fmt.Sprintf("%s (%s)", e.ID, e.Name)
+		}
+		row := []string{colorize(e.Status, statusColor), name, e.URL, e.File}
+		if withPriority {
+			row = append(row[:2], append([]string{e.Priority}, row[2:]...)...)
 		}
+		rows = append(rows, row)
 	}
+	if withPriority {
+		printTable([]string{"STATUS", "ID/NAME", "PRIORITY", "URL", "SOURCE FILE"}, rows)
+		return
+	}
+	printTable([]string{"STATUS", "ID/NAME", "URL", "SOURCE FILE"}, rows)
+}
 
-	// Check sources.list.d directory
-	sourcesDir := "/etc/apt/sources.list.d"
-	if _, err := os.Stat(sourcesDir); err == nil {
-		files, err := filepath.Glob(filepath.Join(sourcesDir, "*.list"))
-		if err != nil {
-			return fmt.Errorf("failed to list repository files: %v", err)
+// toRepository converts an entry to its JSON form
+func (e repoEntry) toRepository() Repository {
+	repo := Repository{
+		ID:         e.ID,
+		Name:       e.Name,
+		Type:       e.Type,
+		Enabled:    e.Enabled,
+		URLs:       e.URLs,
+		Suites:     e.Suites,
+		Components: e.Components,
+		SourceFile: e.File,
+		GPGKeys:    e.GPGKeys,
+	}
+	if repo.URLs == nil {
+		repo.URLs = []string{}
+		if e.URL != "" {
+			repo.URLs = []string{e.URL}
+		}
+	}
+	if repo.GPGKeys == nil && e.Key != "" {
+		repo.GPGKeys = []string{e.Key}
+	}
+	// Priorities are shown as e.g. "500 (default)"
+	if fields := strings.Fields(e.Priority); len(fields) > 0 {
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			repo.Priority = &n
 		}
+	}
+	return repo
+}
 
-		for _, file := range files {
-			content, err := os.ReadFile(file)
-			if err != nil {
-				fmt.Printf("Warning: failed to read %s: %v\n", file, err)
-				continue
-			}
+// statusLabel returns the display label for an enabled flag
+func statusLabel(enabled bool) string {
+	if enabled {
+		return "Enabled"
+	}
+	return "Disabled"
+}
 
-			fmt.Printf("\nFrom %s:\n", file)
-			lines := strings.Split(string(content), "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if line == "" || strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "# deb") && !strings.HasPrefix(line, "#deb") {
-					continue
-				}
+// listReposApt lists repositories for apt-based systems
+func listReposApt() ([]repoEntry, error) {
+	// Unreadable files are collected and reported after everything else was listed
+	var scanErrs fileErrors
 
-				status := "Enabled"
-				statusColor := colorGreen
-				if strings.HasPrefix(line, "#") {
-					status = "Disabled"
-					statusColor = colorYellow
-					// Remove comment for display
-					line = strings.TrimPrefix(strings.TrimPrefix(line, "# "), "#")
-				}
+	files, err := aptSourceFiles()
+	if err != nil {
+		return nil, err
+	}
 
-				if strings.HasPrefix(line, "deb ") || strings.HasPrefix(line, "deb-src ") {
-					fmt.Printf("  [%s] %s\n", colorize(status, statusColor), line)
-				}
+	pins := aptPinPriorities()
+
+	var entries []repoEntry
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				scanErrs.add(err)
+			}
+			continue
+		}
+
+		for _, src := range parseAptSources(file, string(content)) {
+			priority := "500 (default)"
+			if n, ok := pins[uriHost(src.URIs[0])]; ok {
+				priority = strconv.Itoa(n)
 			}
+			entries = append(entries, repoEntry{
+				ID:         aptSourceID(file),
+				Enabled:    src.Enabled,
+				Status:     statusLabel(src.Enabled),
+				URL:        src.describe(),
+				File:       file,
+				Key:        src.Options["signed-by"],
+				Priority:   priority,
+				Type:       strings.Join(src.Types, " "),
+				URLs:       src.URIs,
+				Suites:     src.Suites,
+				Components: src.Components,
+			})
 		}
 	}
 
-	return nil
+	return entries, scanErrs.err()
 }
 
 // listReposDnfYum lists repositories for dnf/yum-based systems
-func listReposDnfYum() error {
-	fmt.Println("DNF/YUM Repositories:")
-	fmt.Println("=====================")
-
+func listReposDnfYum() ([]repoEntry, error) {
 	repoDir := "/etc/yum.repos.d"
-	if _, err := os.Stat(repoDir); err != nil {
-		return fmt.Errorf("repository directory %s does not exist", repoDir)
+	if _, err := os.Stat(systemPath(repoDir)); err != nil {
+		return nil, fmt.Errorf("repository directory %s does not exist", repoDir)
 	}
 
-	files, err := filepath.Glob(filepath.Join(repoDir, "*.repo"))
+	files, err := filepath.Glob(systemPath(filepath.Join(repoDir, "*.repo")))
 	if err != nil {
-		return fmt.Errorf("failed to list repository files: %v", err)
+		return nil, fmt.Errorf("failed to list repository files: %v", err)
 	}
 
-	if len(files) == 0 {
-		fmt.Println("No repository files found.")
-		return nil
-	}
+	namePattern := regexp.MustCompile(`(?m)^name\s*=\s*(.*)$`)
+	urlPattern := regexp.MustCompile(`(?m)^(?:baseurl|metalink|mirrorlist)\s*=\s*(\S+)`)
+	keyPattern := regexp.MustCompile(`(?m)^gpgkey\s*=\s*file://(\S+)`)
+	priorityPattern := regexp.MustCompile(`(?m)^priority\s*=\s*(\d+)`)
+	typePattern := regexp.MustCompile(`(?m)^type\s*=\s*(\S+)`)
+	urlsPattern := regexp.MustCompile(`(?m)^(?:baseurl|metalink|mirrorlist)\s*=\s*(.*)$`)
+	keysPattern := regexp.MustCompile(`(?m)^gpgkey\s*=\s*(.*)$`)
 
+	var scanErrs fileErrors
+	var entries []repoEntry
 	for _, file := range files {
 		content, err := os.ReadFile(file)
 		if err != nil {
-			fmt.Printf("Warning: failed to read %s: %v\n", file, err)
+			scanErrs.add(err)
 			continue
 		}
 
-		fmt.Printf("\nFrom %s\n", file)
-
-		// Extract all repository sections
-		repoSections := extractAllRepoSections(string(content))
-
-		for _, section := range repoSections {
-			// Extract name if available
-			namePattern := regexp.MustCompile(`(?m)^name\s*=\s*(.*)$`)
-			nameMatch := namePattern.FindStringSubmatch(section.content)
-			repoName := section.id
-			if len(nameMatch) > 1 {
-				repoName = fmt.Sprintf("%s (%s)", nameMatch[1], section.id)
+		for _, section := range extractAllRepoSections(string(content)) {
+			entry := repoEntry{ID: section.id, File: file}
+			if match := namePattern.FindStringSubmatch(section.content); len(match) > 1 {
+				entry.Name = strings.TrimSpace(match[1])
+			}
+			if match := urlPattern.FindStringSubmatch(section.content); len(match) > 1 {
+				entry.URL = match[1]
+			}
+			if match := keyPattern.FindStringSubmatch(section.content); len(match) > 1 {
+				entry.Key = match[1]
+			}
+			entry.Priority = "99 (default)"
+			if match := priorityPattern.FindStringSubmatch(section.content); len(match) > 1 {
+				entry.Priority = match[1]
+			}
+			entry.Type = "rpm-md"
+			if match := typePattern.FindStringSubmatch(section.content); len(match) > 1 {
+				entry.Type = match[1]
+			}
+			// Lists of URLs and keys are separated by spaces or commas
+			for _, match := range urlsPattern.FindAllStringSubmatch(section.content, -1) {
+				entry.URLs = append(entry.URLs, splitList(match[1])...)
+			}
+			for _, match := range keysPattern.FindAllStringSubmatch(section.content, -1) {
+				entry.GPGKeys = append(entry.GPGKeys, splitList(match[1])...)
 			}
 
-			// Check if enabled
-			status := "Unknown"
-			statusColor := colorGrey
+			// Check if enabled; the default is enabled if not specified
 			if strings.Contains(section.content, "enabled=0") {
-				status = "Disabled"
-				statusColor = colorYellow
+				entry.Status = "Disabled"
 			} else if strings.Contains(section.content, "enabled=1") {
-				status = "Enabled"
-				statusColor = colorGreen
+				entry.Enabled, entry.Status = true, "Enabled"
 			} else {
-				// Default is enabled if not specified
-				status = "Enabled (default)"
-				statusColor = colorGreen
+				entry.Enabled, entry.Status = true, "Enabled (default)"
 			}
-
-			fmt.Printf("  [%s] %s\n", colorize(status, statusColor), repoName)
+			entries = append(entries, entry)
 		}
 	}
 
-	return nil
+	return entries, scanErrs.err()
 }
 
 // listReposAlpine lists repositories for Alpine Linux
-func listReposAlpine() error {
-	fmt.Println("Alpine Repositories:")
-	fmt.Println("===================")
-
+func listReposAlpine() ([]repoEntry, error) {
 	repoFile := "/etc/apk/repositories"
-	if _, err := os.Stat(repoFile); err != nil {
-		return fmt.Errorf("repository file %s does not exist", repoFile)
-	}
-
-	content, err := os.ReadFile(repoFile)
+	content, err := readFileContent(repoFile)
 	if err != nil {
-		return fmt.Errorf("failed to read repositories file: %v", err)
+		return nil, err
 	}
 
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
+	var entries []repoEntry
+	name := ""
+	for _, line := range strings.Split(content, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" || (strings.HasPrefix(line, "#") && !strings.Contains(line, "://")) {
+		if line == "" {
+			continue
+		}
+
+		// A comment without a URL names the repository that follows it, as written by add-repo
+		if strings.HasPrefix(line, "#") && !strings.Contains(line, "://") {
+			name = strings.TrimSpace(strings.TrimPrefix(line, "#"))
 			continue
 		}
 
-		status := "Enabled"
-		statusColor := colorGreen
-		if strings.HasPrefix(line, "#") {
-			status = "Disabled"
-			statusColor = colorYellow
-			// Remove comment for display
-			line = strings.TrimPrefix(strings.TrimPrefix(line, "# "), "#")
+		enabled := !strings.HasPrefix(line, "#")
+		url := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		id := name
+		if id == "" {
+			// Use the last two path segments, e.g. "edge/testing"
+			parts := strings.Split(strings.TrimSuffix(url, "/"), "/")
+			id = strings.Join(parts[max(0, len(parts)-2):], "/")
 		}
+		name = ""
 
-		fmt.Printf("  [%s] %s\n", colorize(status, statusColor), line)
+		entries = append(entries, repoEntry{ID: id, Enabled: enabled, Status: statusLabel(enabled), URL: url, File: repoFile, Type: "apk"})
 	}
 
-	return nil
+	return entries, nil
 }
 
-// listReposPacman lists repositories for Arch Linux
-func listReposPacman() error {
-	fmt.Println("Pacman Repositories:")
-	fmt.Println("===================")
-
-	repoFile := "/etc/pacman.conf"
-	if _, err := os.Stat(repoFile); err != nil {
-		return fmt.Errorf("repository file %s does not exist", repoFile)
-	}
-
-	content, err := os.ReadFile(repoFile)
+// listReposPacman lists repositories for Arch Linux, including the sections
+// that are commented out
+func listReposPacman() ([]repoEntry, error) {
+	content, err := readFileContent(pacmanConf)
 	if err != nil {
-		return fmt.Errorf("failed to read pacman.conf: %v", err)
+		return nil, err
 	}
 
-	lines := strings.Split(string(content), "\n")
-	inRepo := false
-	repoName := ""
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+	lines := strings.Split(content, "\n")
+	var entries []repoEntry
+	for _, section := range parsePacmanSections(lines) {
+		if section.Name == "options" {
 			continue
 		}
+		entry := repoEntry{ID: section.Name, Enabled: section.Enabled, Status: statusLabel(section.Enabled), File: pacmanConf, Type: "pacman"}
 
-		// Check for repository section
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			repoName = line[1 : len(line)-1]
-			if repoName != "options" {
-				inRepo = true
-				status := "Enabled"
-				statusColor := colorGreen
-				fmt.Printf("  [%s] %s\n", colorize(status, statusColor), repoName)
-			} else {
-				inRepo = false
+		// The first Server or Include line of a section is shown as its URL,
+		// all servers including those of included mirror lists are collected
+		for _, line := range lines[section.Header+1 : section.End] {
+			key, value, found := strings.Cut(strings.TrimLeft(strings.TrimSpace(line), "#"), "=")
+			if !found {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			switch strings.TrimSpace(key) {
+			case "Server":
+				if entry.URL == "" {
+					entry.URL = value
+				}
+				entry.URLs = append(entry.URLs, value)
+			case "Include":
+				if entry.URL == "" {
+					entry.URL = "Include: " + value
+				}
+				entry.URLs = append(entry.URLs, pacmanMirrorServers(value)...)
 			}
-		} else if inRepo && strings.HasPrefix(line, "Include") {
-			fmt.Printf("    Include: %s\n", strings.TrimPrefix(line, "Include = "))
-		} else if inRepo && strings.HasPrefix(line, "Server") {
-			fmt.Printf("    Server: %s\n", strings.TrimPrefix(line, "Server = "))
 		}
+		entries = append(entries, entry)
 	}
 
-	return nil
+	return entries, nil
 }
 
-// listReposHomebrew lists taps for Homebrew
-func listReposHomebrew() error {
-	fmt.Println("Homebrew Taps:")
-	fmt.Println("==============")
-
-	// Create a temporary buffer to capture output
-	var outBuf bytes.Buffer
-
-	// Create brew command
-	cmd := exec.Command("brew", "tap")
-	cmd.Stdout = &outBuf
+// pacmanMirrorServers returns the enabled servers of a mirror list
+func pacmanMirrorServers(path string) []string {
+	content, err := os.ReadFile(systemPath(path))
+	if err != nil {
+		return nil
+	}
+	var servers []string
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if found && strings.TrimSpace(key) == "Server" {
+			servers = append(servers, strings.TrimSpace(value))
+		}
+	}
+	return servers
+}
 
-	// Run command
-	err := cmd.Run()
+// listReposHomebrew lists taps for Homebrew
+func listReposHomebrew() ([]repoEntry, error) {
+	output, err := runCommandOutput("brew", "tap")
 	if err != nil {
-		return fmt.Errorf("failed to list Homebrew taps: %v", err)
+		return nil, fmt.Errorf("failed to list Homebrew taps: %v", err)
 	}
 
-	// Display taps
-	output := outBuf.String()
-	taps := strings.Split(strings.TrimSpace(output), "\n")
-	for _, tap := range taps {
+	var entries []repoEntry
+	for _, tap := range strings.Split(strings.TrimSpace(output), "\n") {
 		if tap != "" {
-			statusColor := colorGreen
-			fmt.Printf("  [%s] %s\n", colorize("Enabled", statusColor), tap)
+			entries = append(entries, repoEntry{ID: tap, Enabled: true, Status: "Enabled", Type: "tap"})
 		}
 	}
 
-	return nil
+	return entries, nil
+}
+
+// splitList splits a list of values separated by spaces or commas
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
 }
 
 func init() {
 	rootCmd.AddCommand(listReposCmd)
+
+	listReposCmd.Flags().Bool("json", false, "Output results as JSON")
 }
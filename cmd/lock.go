@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// noLockFlag disables the instance lock, see acquireLock
+var noLockFlag bool
+
+// lockFile returns the lock shared by all pkgs processes of a host, or of a
+// user when pkgs does not run as root
+func lockFile() string {
+	if _, err := os.Stat("/run"); err == nil && os.Geteuid() == 0 {
+		return "/run/pkgs.lock"
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("pkgs-%d.lock", os.Geteuid()))
+}
+
+// heldLock stays open while pkgs runs, the lock is released on exit
+var heldLock *os.File
+
+// acquireLock takes the advisory instance lock, so that concurrent pkgs
+// commands that change the system queue instead of racing on repository
+// files. It waits for the other process until the timeout expires.
+func acquireLock() error {
+	path := lockFile()
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock %s: %v (use --no-lock to run without it)", path, err)
+	}
+
+	locked, err := tryLock(file)
+	if err == nil && !locked {
+		// The lock is pkgs' own, it is not under --root
+		owner, _ := os.ReadFile(path)
+		if pid, convErr := strconv.Atoi(strings.TrimSpace(string(owner))); convErr == nil {
+			fmt.Fprintf(os.Stderr, "Waiting for another pkgs process (pid %d) to finish...\n", pid)
+		} else {
+			fmt.Fprintln(os.Stderr, "Waiting for another pkgs process to finish...")
+		}
+		for err == nil && !locked {
+			select {
+			case <-runContext.Done():
+				file.Close()
+				return fmt.Errorf("gave up waiting for lock %s", path)
+			case <-time.After(200 * time.Millisecond):
+			}
+			locked, err = tryLock(file)
+		}
+	}
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to lock %s: %v", path, err)
+	}
+
+	// Record the owner for the message of waiting processes
+	file.Truncate(0)
+	file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	heldLock = file
+	return nil
+}
+
+// releaseLock releases the instance lock, for commands that only hold it for
+// a while
+func releaseLock() {
+	if heldLock != nil {
+		heldLock.Close()
+		heldLock = nil
+	}
+}
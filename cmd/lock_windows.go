@@ -0,0 +1,8 @@
+//go:build windows
+
+package cmd
+
+import "os"
+
+// tryLock always succeeds on Windows, where pkgs does not lock
+func tryLock(file *os.File) (bool, error) { return true, nil }
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// apkWorldFile lists the packages explicitly installed on Alpine Linux
+const apkWorldFile = "/etc/apk/world"
+
+// markCmd represents the mark command
+var markCmd = &cobra.Command{
+	Use:   "mark auto|manual [packages...]",
+	Short: "Mark packages as automatically or manually installed",
+	Long: `Mark packages as installed automatically (as a dependency) or manually
+(explicitly). Packages marked auto are removed by 'pkgs autoremove' once
+nothing depends on them anymore; packages marked manual are kept.
+
+For apt-based systems (Debian/Ubuntu):
+  apt-mark auto|manual package
+
+For dnf-based systems (Fedora/RHEL/CentOS):
+  dnf mark remove|install package (dnf mark dependency|user on dnf 5)
+
+For yum-based systems:
+  yumdb set reason dep|user package
+
+For Alpine Linux:
+  manual runs apk add package; auto removes the package from /etc/apk/world,
+  so apk removes it on its next run once nothing depends on it
+
+For Arch Linux:
+  pacman -D --asdeps|--asexplicit package
+
+For Homebrew (macOS):
+  brew tab --no-installed-on-request|--installed-on-request package`,
+	Example: `  pkgs mark auto libfoo
+  pkgs mark manual nginx`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		mode, names := args[0], args[1:]
+		if mode != "auto" && mode != "manual" {
+			return fmt.Errorf("unknown mark '%s' (usage: pkgs mark auto|manual package...)", mode)
+		}
+		auto := mode == "auto"
+
+		switch pm.Name {
+		case "apt", "apt-get":
+			return executeConfirmed("apt-mark", append([]string{mode}, names...)...)
+		case "dnf":
+			action := "install"
+			if auto {
+				action = "remove"
+			}
+			if isDnf5() {
+				action = "user"
+				if auto {
+					action = "dependency"
+				}
+			}
+			return executeConfirmed("dnf", append([]string{"mark", action}, names...)...)
+		case "yum":
+			reason := "user"
+			if auto {
+				reason = "dep"
+			}
+			return executeConfirmed("yumdb", append([]string{"set", "reason", reason}, names...)...)
+		case "apk":
+			if auto {
+				return removeFromApkWorld(names)
+			}
+			return executeConfirmed("apk", append([]string{"add"}, names...)...)
+		case "pacman":
+			flag := "--asexplicit"
+			if auto {
+				flag = "--asdeps"
+			}
+			return executeConfirmed("pacman", append([]string{"-D", flag}, names...)...)
+		case "brew":
+			flag := "--installed-on-request"
+			if auto {
+				flag = "--no-installed-on-request"
+			}
+			return executeConfirmed("brew", append([]string{"tab", flag}, names...)...)
+		default:
+			return unsupportedError("marking packages is not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+// isDnf5 reports whether the dnf binary is dnf 5, whose subcommands differ from dnf 4
+func isDnf5() bool {
+	output, err := runCommandOutput("dnf", "--version")
+	return err == nil && strings.Contains(output, "dnf5")
+}
+
+// removeFromApkWorld drops packages from the apk world file without uninstalling them
+func removeFromApkWorld(names []string) error {
+	content, err := readFileContent(apkWorldFile)
+	if err != nil {
+		return err
+	}
+
+	remove := map[string]bool{}
+	for _, name := range names {
+		remove[name] = true
+	}
+
+	var kept []string
+	removed := 0
+	for _, entry := range strings.Fields(content) {
+		// Entries may carry a version constraint or a repository tag
+		name := entry
+		if i := strings.IndexAny(entry, "=<>~@"); i > 0 {
+			name = entry[:i]
+		}
+		if remove[name] {
+			removed++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	if removed == 0 {
+		fmt.Println("The packages are already marked as automatically installed.")
+		return nil
+	}
+	if err := confirmPlan(planStep{"write", apkWorldFile}); err != nil {
+		return err
+	}
+	if err := writeFileContent(apkWorldFile, strings.Join(kept, "\n")+"\n", 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d package(s) from %s\n", removed, apkWorldFile)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(markCmd)
+}
@@ -0,0 +1,580 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// mirrorBackupDir keeps the configuration files as they were before the first
+// 'pkgs mirrors --select-fastest', in the same layout as below /
+const mirrorBackupDir = "/var/lib/pkgs/mirrors-backup"
+
+// mirrorClient is used to benchmark mirrors
+var mirrorClient = &http.Client{Transport: httpTransport, Timeout: 10 * time.Second}
+
+// mirrorResult is the outcome of benchmarking one mirror
+type mirrorResult struct {
+	URL     string
+	Time    time.Duration
+	Err     error
+	Current bool
+}
+
+// mirrorsCmd represents the mirrors command
+var mirrorsCmd = &cobra.Command{
+	Use:   "mirrors",
+	Short: "Benchmark mirrors and select the fastest ones",
+	Long: `Benchmark the configured and known mirrors by downloading a small index
+file from each of them, and show them from fastest to slowest. With
+--select-fastest the configuration is rewritten to use the best ones; the
+original files are kept in ` + mirrorBackupDir + ` and --restore puts
+them back.
+
+For apt-based systems (Debian/Ubuntu):
+  The main archive in the sources files is benchmarked against the mirrors
+  from mirrors.ubuntu.com on Ubuntu. Debian's deb.debian.org is already a CDN,
+  so give other Debian mirrors with --candidate. Security repositories are
+  never changed.
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf benchmarks its mirrors itself; --select-fastest sets fastestmirror=True
+  in /etc/dnf/dnf.conf.
+
+For Alpine Linux:
+  The mirrors from mirrors.alpinelinux.org are benchmarked and the fastest
+  replaces the mirror in /etc/apk/repositories.
+
+For Arch Linux:
+  All servers in /etc/pacman.d/mirrorlist, commented out or not, are
+  benchmarked and the fastest ones (--count) are enabled.`,
+	Example: `  # Show the mirrors from fastest to slowest
+  pkgs mirrors
+
+  # Switch to the fastest mirror
+  pkgs mirrors --select-fastest
+
+  # Also compare other mirrors
+  pkgs mirrors --candidate http://ftp.de.debian.org/debian
+
+  # Keep the 3 fastest servers on Arch Linux
+  pkgs mirrors --select-fastest --count 3
+
+  # Go back to the configuration before the first selection
+  pkgs mirrors --restore`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		selectFastest, _ := cmd.Flags().GetBool("select-fastest")
+		restore, _ := cmd.Flags().GetBool("restore")
+		candidates, _ := cmd.Flags().GetStringSlice("candidate")
+		count, _ := cmd.Flags().GetInt("count")
+		if count < 1 {
+			return fmt.Errorf("--count must be at least 1")
+		}
+
+		if restore {
+			return restoreMirrorConfig()
+		}
+
+		switch pm.Type {
+		case "debian":
+			return selectMirrorApt(candidates, selectFastest)
+		case "redhat":
+			if !selectFastest {
+				fmt.Println("dnf benchmarks its mirrors itself when fastestmirror is enabled.")
+				fmt.Println("Use 'pkgs mirrors --select-fastest' to enable it.")
+				return nil
+			}
+			return enableFastestMirrorDnf(pm)
+		case "alpine":
+			return selectMirrorAlpine(candidates, selectFastest)
+		case "arch":
+			return selectMirrorsPacman(candidates, selectFastest, count)
+		default:
+			return unsupportedError("selecting mirrors is not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+// aptMirrorPattern matches the URIs of the main Debian and Ubuntu archives
+var aptMirrorPattern = regexp.MustCompile(`^https?://[^/]+(/.*)?/(debian|ubuntu)/?$`)
+
+// selectMirrorApt benchmarks mirrors of the main archive and optionally
+// switches the sources files to the fastest one
+func selectMirrorApt(candidates []string, selectFastest bool) error {
+	files, err := aptSourceFiles()
+	if err != nil {
+		return err
+	}
+
+	// Collect the configured archive URIs, with a suite to probe them
+	current := map[string]string{}
+	var currentFiles []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, src := range parseAptSources(file, string(content)) {
+			for _, uri := range src.URIs {
+				uri = strings.TrimSuffix(uri, "/")
+				if !src.Enabled || !aptMirrorPattern.MatchString(uri) || uriHost(uri) == "security.ubuntu.com" {
+					continue
+				}
+				if _, seen := current[uri]; !seen {
+					current[uri] = src.Suites[0]
+					currentFiles = append(currentFiles, file)
+				}
+			}
+		}
+	}
+	if len(current) == 0 {
+		return fmt.Errorf("no Debian or Ubuntu archive found in the apt sources")
+	}
+
+	urls := make([]string, 0, len(current))
+	for uri := range current {
+		urls = append(urls, uri)
+	}
+	sort.Strings(urls)
+	suite := current[urls[0]]
+	if distro, _ := distroInfo(); distro == "ubuntu" {
+		urls = append(urls, fetchMirrorList("http://mirrors.ubuntu.com/mirrors.txt")...)
+	}
+	urls = append(urls, trimSlashes(candidates)...)
+
+	results := benchmarkMirrors(urls, current, func(base string) string {
+		return base + "/dists/" + suite + "/InRelease"
+	})
+	printMirrorResults(results)
+	if !selectFastest {
+		return nil
+	}
+
+	best := results[0]
+	if best.Err != nil {
+		return fmt.Errorf("no mirror could be reached")
+	}
+	if best.Current && len(current) == 1 {
+		fmt.Printf("Already using the fastest mirror %s\n", best.URL)
+		return nil
+	}
+
+	var changed []string
+	contents, newContents := map[string]string{}, map[string]string{}
+	for _, file := range uniqueSorted(currentFiles) {
+		content, err := readFileContent(file)
+		if err != nil {
+			return err
+		}
+		newContent := content
+		for uri := range current {
+			// Only whole URIs, so that ".../debian" does not match ".../debian-security"
+			pattern := regexp.MustCompile(`(?m)(^|[ \t])` + regexp.QuoteMeta(uri) + `/?([ \t]|$)`)
+			newContent = pattern.ReplaceAllStringFunc(newContent, func(match string) string {
+				return strings.Replace(match, strings.TrimSpace(match), best.URL, 1)
+			})
+		}
+		if newContent != content {
+			changed = append(changed, file)
+			contents[file], newContents[file] = content, newContent
+		}
+	}
+	if err := confirmPlan(mirrorPlan(changed...)...); err != nil {
+		return err
+	}
+	for _, file := range changed {
+		if err := writeMirrorConfig(file, contents[file], newContents[file]); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Switched to %s\n", best.URL)
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// enableFastestMirrorDnf lets dnf pick the fastest mirror itself
+func enableFastestMirrorDnf(pm *PackageManager) error {
+	if pm.Name != "dnf" {
+		return unsupportedError("selecting mirrors is only supported with dnf, install the yum fastestmirror plugin instead")
+	}
+	confFile := "/etc/dnf/dnf.conf"
+	content, err := readFileContent(confFile)
+	if err != nil {
+		return err
+	}
+	if err := rewriteMirrorConfig(confFile, content, setRepoOption(content, "main", "fastestmirror", "True")); err != nil {
+		return err
+	}
+	fmt.Printf("Enabled fastestmirror in %s\n", confFile)
+	return ExecuteCommand(pm, "update", nil)
+}
+
+// selectMirrorAlpine benchmarks the Alpine mirrors and optionally switches
+// /etc/apk/repositories to the fastest one
+func selectMirrorAlpine(candidates []string, selectFastest bool) error {
+	repoFile := "/etc/apk/repositories"
+	content, err := readFileContent(repoFile)
+	if err != nil {
+		return err
+	}
+
+	// Repository lines look like https://dl-cdn.alpinelinux.org/alpine/v3.19/main,
+	// the mirror is everything before the release and the repository
+	current := map[string]string{}
+	var probe string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || !strings.Contains(line, "://") {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(line, "/"), "/")
+		if len(parts) < 5 {
+			continue
+		}
+		base := strings.Join(parts[:len(parts)-2], "/")
+		if probe == "" {
+			probe = strings.Join(parts[len(parts)-2:], "/")
+		}
+		current[base] = ""
+	}
+	if len(current) == 0 {
+		return fmt.Errorf("no mirror found in %s", repoFile)
+	}
+
+	arch, err := runCommandOutput("apk", "--print-arch")
+	if err != nil {
+		return fmt.Errorf("failed to determine the architecture: %v", err)
+	}
+
+	urls := make([]string, 0, len(current))
+	for base := range current {
+		urls = append(urls, base)
+	}
+	urls = append(urls, fetchMirrorList("https://mirrors.alpinelinux.org/mirrors.txt")...)
+	urls = append(urls, trimSlashes(candidates)...)
+
+	results := benchmarkMirrors(urls, current, func(base string) string {
+		return base + "/" + probe + "/" + strings.TrimSpace(arch) + "/APKINDEX.tar.gz"
+	})
+	printMirrorResults(results)
+	if !selectFastest {
+		return nil
+	}
+
+	best := results[0]
+	if best.Err != nil {
+		return fmt.Errorf("no mirror could be reached")
+	}
+	if best.Current && len(current) == 1 {
+		fmt.Printf("Already using the fastest mirror %s\n", best.URL)
+		return nil
+	}
+
+	newContent := content
+	for base := range current {
+		newContent = strings.ReplaceAll(newContent, base+"/", best.URL+"/")
+	}
+	if err := rewriteMirrorConfig(repoFile, content, newContent); err != nil {
+		return err
+	}
+	fmt.Printf("Switched to %s\n", best.URL)
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// selectMirrorsPacman benchmarks the servers of the pacman mirrorlist and
+// optionally enables the count fastest ones
+func selectMirrorsPacman(candidates []string, selectFastest bool, count int) error {
+	mirrorList := "/etc/pacman.d/mirrorlist"
+	content, err := readFileContent(mirrorList)
+	if err != nil {
+		return err
+	}
+
+	serverPattern := regexp.MustCompile(`^#?\s*Server\s*=\s*(\S+)`)
+	current := map[string]string{}
+	var urls []string
+	for _, line := range strings.Split(content, "\n") {
+		match := serverPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		urls = append(urls, match[1])
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			current[match[1]] = ""
+		}
+	}
+	urls = append(urls, candidates...)
+	if len(urls) == 0 {
+		return fmt.Errorf("no servers found in %s", mirrorList)
+	}
+
+	arch, err := runCommandOutput("uname", "-m")
+	if err != nil {
+		return fmt.Errorf("failed to determine the architecture: %v", err)
+	}
+	results := benchmarkMirrors(urls, current, func(server string) string {
+		server = strings.ReplaceAll(server, "$repo", "core")
+		server = strings.ReplaceAll(server, "$arch", strings.TrimSpace(arch))
+		return server + "/core.db"
+	})
+	printMirrorResults(results)
+	if !selectFastest {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Selected by 'pkgs mirrors --select-fastest' on %s\n", time.Now().Format("2006-01-02"))
+	enabled := 0
+	for _, r := range results {
+		if r.Err == nil && enabled < count {
+			fmt.Fprintf(&b, "Server = %s\n", r.URL)
+			enabled++
+		}
+	}
+	if enabled == 0 {
+		return fmt.Errorf("no mirror could be reached")
+	}
+	// The previous servers stay available as comments
+	b.WriteString("\n")
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		if serverPattern.MatchString(strings.TrimSpace(line)) && !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			line = "#" + line
+		}
+		b.WriteString(line + "\n")
+	}
+
+	if err := rewriteMirrorConfig(mirrorList, content, b.String()); err != nil {
+		return err
+	}
+	fmt.Printf("Enabled the %d fastest mirror(s) in %s\n", enabled, mirrorList)
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// fetchMirrorList downloads a list of mirror URLs, one per line. Failures
+// only mean that fewer mirrors are compared.
+func fetchMirrorList(listURL string) []string {
+	resp, err := mirrorClient.Get(listURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch the mirror list %s: %v\n", listURL, err)
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch the mirror list %s: %s\n", listURL, resp.Status)
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil
+	}
+
+	var urls []string
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if u, err := url.Parse(line); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			urls = append(urls, strings.TrimSuffix(line, "/"))
+		}
+	}
+	return urls
+}
+
+// trimSlashes removes trailing slashes from mirror URLs
+func trimSlashes(urls []string) []string {
+	result := make([]string, len(urls))
+	for i, u := range urls {
+		result[i] = strings.TrimSuffix(u, "/")
+	}
+	return result
+}
+
+// benchmarkMirrors downloads the probe file of every mirror in parallel and
+// returns the results from fastest to slowest, unreachable mirrors last
+func benchmarkMirrors(urls []string, current map[string]string, probe func(string) string) []mirrorResult {
+	urls = uniqueSorted(urls)
+	fmt.Fprintf(os.Stderr, "Benchmarking %d mirror(s)...\n", len(urls))
+
+	results := make([]mirrorResult, len(urls))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				_, isCurrent := current[urls[i]]
+				results[i] = mirrorResult{URL: urls[i], Current: isCurrent}
+				results[i].Time, results[i].Err = timeDownload(probe(urls[i]))
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Err == nil) != (results[j].Err == nil) {
+			return results[i].Err == nil
+		}
+		return results[i].Time < results[j].Time
+	})
+	return results
+}
+
+// timeDownload measures how long downloading a file takes
+func timeDownload(fileURL string) (time.Duration, error) {
+	start := time.Now()
+	resp, err := mirrorClient.Get(fileURL)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s", resp.Status)
+	}
+	// Index files can be large, a few megabytes are enough to compare mirrors
+	if _, err := io.CopyN(io.Discard, resp.Body, 4<<20); err != nil && err != io.EOF {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// printMirrorResults renders the benchmark as a table
+func printMirrorResults(results []mirrorResult) {
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		current := ""
+		if r.Current {
+			current = "*"
+		}
+		timing := fmt.Sprintf("%d ms", r.Time.Milliseconds())
+		if r.Err != nil {
+			timing = colorize("failed", colorYellow)
+		}
+		rows = append(rows, []string{current, timing, r.URL})
+	}
+	printTable([]string{"CURRENT", "TIME", "MIRROR"}, rows)
+}
+
+// rewriteMirrorConfig writes the new content of a configuration file once
+// the user confirmed it, see writeMirrorConfig
+func rewriteMirrorConfig(file, oldContent, newContent string) error {
+	if oldContent == newContent {
+		return nil
+	}
+	if err := confirmPlan(mirrorPlan(file)...); err != nil {
+		return err
+	}
+	return writeMirrorConfig(file, oldContent, newContent)
+}
+
+// mirrorPlan lists the configuration files a mirror selection writes, with
+// the backups of the originals
+func mirrorPlan(files ...string) []planStep {
+	var steps []planStep
+	for _, file := range files {
+		if backup := filepath.Join(mirrorBackupDir, file); !fileExists(backup) {
+			steps = append(steps, planStep{"write", backup})
+		}
+		steps = append(steps, planStep{"write", file})
+	}
+	return steps
+}
+
+// writeMirrorConfig writes the new content of a configuration file, after
+// keeping the original in mirrorBackupDir unless an earlier selection did so
+func writeMirrorConfig(file, oldContent, newContent string) error {
+	if oldContent == newContent {
+		return nil
+	}
+	backup := filepath.Join(mirrorBackupDir, file)
+	if !fileExists(backup) {
+		if err := ensureDirExists(filepath.Dir(backup)); err != nil {
+			return err
+		}
+		if err := writeFileContent(backup, oldContent, 0644); err != nil {
+			return err
+		}
+	}
+	if err := writeFileContent(file, newContent, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s (original kept in %s)\n", file, backup)
+	return nil
+}
+
+// restoreMirrorConfig puts back the files kept by rewriteMirrorConfig
+func restoreMirrorConfig() error {
+	var backups []string
+	err := filepath.WalkDir(mirrorBackupDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		backups = append(backups, path)
+		return nil
+	})
+	if os.IsNotExist(err) || (err == nil && len(backups) == 0) {
+		fmt.Println("No mirror configuration to restore.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore the mirror configuration: %v", err)
+	}
+
+	steps := make([]planStep, 0, len(backups)+1)
+	for _, path := range backups {
+		steps = append(steps, planStep{"write", "/" + strings.TrimPrefix(path, mirrorBackupDir+"/")})
+	}
+	steps = append(steps, planStep{"remove", mirrorBackupDir})
+	if err := confirmPlan(steps...); err != nil {
+		return err
+	}
+	for _, path := range backups {
+		file := "/" + strings.TrimPrefix(path, mirrorBackupDir+"/")
+		content, err := readFileContent(path)
+		if err != nil {
+			return fmt.Errorf("failed to restore the mirror configuration: %v", err)
+		}
+		if err := writeFileContent(file, content, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Restored %s\n", file)
+	}
+	if IsDryRun() {
+		fmt.Printf("Would remove %s\n", mirrorBackupDir)
+	} else if err := os.RemoveAll(mirrorBackupDir); err != nil {
+		return fmt.Errorf("failed to remove %s: %v", mirrorBackupDir, err)
+	}
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorsCmd)
+
+	mirrorsCmd.Flags().Bool("select-fastest", false, "Rewrite the configuration to use the fastest mirrors")
+	mirrorsCmd.Flags().Bool("restore", false, "Restore the configuration from before the first selection")
+	mirrorsCmd.Flags().StringSlice("candidate", nil, "Additional mirror URL to compare (can be repeated)")
+	mirrorsCmd.Flags().Int("count", 5, "Number of mirrors to enable on Arch Linux")
+	mirrorsCmd.MarkFlagsMutuallyExclusive("select-fastest", "restore")
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// OrphanPackage is an automatically installed package that nothing requires anymore
+type OrphanPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// orphansCmd represents the orphans command
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "List unneeded packages without removing them",
+	Long: `List packages that were installed as dependencies and are no longer
+required by anything. These are the packages 'pkgs autoremove' would remove,
+so the list can be reviewed before cleaning up.
+
+For apt-based systems (Debian/Ubuntu):
+  apt-get -s autoremove
+
+For dnf-based systems (Fedora/RHEL/CentOS):
+  dnf repoquery --unneeded
+
+For yum-based systems:
+  package-cleanup --leaves
+
+For Arch Linux:
+  pacman -Qdt
+
+For Homebrew (macOS):
+  brew autoremove --dry-run
+
+apk removes packages that are no longer needed on every run, so Alpine
+systems do not accumulate orphans.`,
+	Example: `  pkgs orphans
+  pkgs orphans --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		orphans, err := listOrphans(pm)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			return printJSON(orphans)
+		}
+
+		defer startPager()()
+
+		if len(orphans) == 0 {
+			fmt.Println("No orphaned packages found.")
+			return nil
+		}
+		rows := make([][]string, 0, len(orphans))
+		for _, o := range orphans {
+			rows = append(rows, []string{o.Name, o.Version})
+		}
+		printTable([]string{"NAME", "VERSION"}, rows)
+		return nil
+	},
+}
+
+// listOrphans queries the native package manager for unneeded packages
+func listOrphans(pm *PackageManager) ([]OrphanPackage, error) {
+	orphans := []OrphanPackage{}
+	switch pm.Name {
+	case "apt", "apt-get":
+		// Output: "Remv libfoo1 [1.2-3]"
+		output, err := runCommandOutput("apt-get", "-s", "autoremove")
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate autoremove: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || fields[0] != "Remv" {
+				continue
+			}
+			orphan := OrphanPackage{Name: fields[1]}
+			if len(fields) > 2 {
+				orphan.Version = strings.Trim(fields[2], "[]")
+			}
+			orphans = append(orphans, orphan)
+		}
+	case "dnf":
+		output, err := runCommandOutput("dnf", "-q", "repoquery", "--unneeded", "--qf", "%{name} %{version}-%{release}\n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to query unneeded packages: %w", err)
+		}
+		orphans = append(orphans, parseNameVersionLines(output)...)
+	case "yum":
+		// Output: "libfoo-1.2-3.el7.x86_64"
+		output, err := runCommandOutput("package-cleanup", "-q", "--leaves")
+		if err != nil {
+			return nil, fmt.Errorf("failed to query leaf packages (is yum-utils installed?): %w", err)
+		}
+		for _, nevra := range strings.Fields(output) {
+			name, version := splitRpmNEVRA(nevra)
+			orphans = append(orphans, OrphanPackage{Name: name, Version: version})
+		}
+	case "apk":
+		// apk drops packages that are not needed by the world on every commit
+	case "pacman":
+		// pacman exits with 1 when there are no orphans
+		output, err := runCommandOutput("pacman", "-Qdt")
+		if err != nil && strings.TrimSpace(output) != "" {
+			return nil, fmt.Errorf("failed to query orphaned packages: %w", err)
+		}
+		orphans = append(orphans, parseNameVersionLines(output)...)
+	case "brew":
+		// Output: "==> Would autoremove 2 unneeded formulae:" followed by one name per line
+		output, err := runCommandOutput("brew", "autoremove", "--dry-run")
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate autoremove: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "==>") {
+				orphans = append(orphans, OrphanPackage{Name: line})
+			}
+		}
+	default:
+		return nil, unsupportedError("listing orphaned packages is not supported for package manager '%s'", pm.Name)
+	}
+	return orphans, nil
+}
+
+// parseNameVersionLines parses "name version" lines
+func parseNameVersionLines(output string) []OrphanPackage {
+	var result []OrphanPackage
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			result = append(result, OrphanPackage{Name: fields[0], Version: fields[1]})
+		}
+	}
+	return result
+}
+
+func init() {
+	rootCmd.AddCommand(orphansCmd)
+
+	orphansCmd.Flags().Bool("json", false, "Output results as JSON")
+}
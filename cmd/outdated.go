@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// OutdatedPackage is an installed package with a newer version available
+type OutdatedPackage struct {
+	Name      string `json:"name"`
+	Current   string `json:"current,omitempty"`
+	Candidate string `json:"candidate"`
+	Repo      string `json:"repo,omitempty"`
+}
+
+// outdatedCmd represents the outdated command
+var outdatedCmd = &cobra.Command{
+	Use:     "outdated",
+	Aliases: []string{"upgradable", "list-upgradable"},
+	Short:   "List installed packages that can be upgraded",
+	Long: `List installed packages for which a newer version is available.
+
+The package lists are not refreshed, run 'pkgs update' first to see the latest
+versions. The native output is normalized into name/current/candidate columns:
+
+For apt-based systems (Debian/Ubuntu):
+  apt list --upgradable
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf check-update
+
+For Alpine Linux:
+  apk version -l '<'
+
+For Arch Linux:
+  pacman -Qu
+
+For Homebrew (macOS):
+  brew outdated`,
+	Example: `  pkgs outdated
+  pkgs list --upgradable
+  pkgs outdated --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		packages, err := listOutdated(pm)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			return printJSON(packages)
+		}
+
+		defer startPager()()
+
+		if len(packages) == 0 {
+			fmt.Println("All packages are up to date.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(packages))
+		for _, p := range packages {
+			rows = append(rows, []string{p.Name, p.Current, p.Candidate, p.Repo})
+		}
+		printTable([]string{"NAME", "CURRENT", "CANDIDATE", "REPO"}, rows)
+		return nil
+	},
+}
+
+// listOutdated runs the native upgradable query and parses its output
+func listOutdated(pm *PackageManager) ([]OutdatedPackage, error) {
+	switch pm.Name {
+	case "apt":
+		output, err := runCommandOutput("apt", "list", "--upgradable")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list upgradable packages: %w", err)
+		}
+		return parseAptUpgradable(output), nil
+	case "apt-get":
+		// apt-get has no list command, a simulated upgrade shows the same information
+		output, err := runCommandOutput("apt-get", "-s", "upgrade")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list upgradable packages: %w", err)
+		}
+		return parseAptGetSimulation(output), nil
+	case "dnf", "yum":
+		output, err := runCommandOutput(pm.Bin, "-q", "check-update")
+		// check-update exits with 100 when updates are available
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 100) {
+			return nil, fmt.Errorf("failed to list upgradable packages: %w", err)
+		}
+		packages := parseDnfCheckUpdate(output)
+		fillRpmVersions(packages)
+		return packages, nil
+	case "apk":
+		output, err := runCommandOutput("apk", "version", "-l", "<")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list upgradable packages: %w", err)
+		}
+		return parseApkVersion(output), nil
+	case "pacman":
+		output, stderr, err := runCommandOutputs("pacman", "-Qu")
+		// pacman exits with 1 and no error output when there is nothing to upgrade
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && strings.TrimSpace(stderr) == "") {
+			return nil, fmt.Errorf("failed to list upgradable packages: %w", err)
+		}
+		return parsePacmanUpgradable(output), nil
+	case "brew":
+		output, err := runCommandOutput("brew", "outdated", "--verbose")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list upgradable packages: %w", err)
+		}
+		return parseBrewOutdated(output), nil
+	default:
+		return nil, unsupportedError("listing upgradable packages is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// parseAptUpgradable parses "apt list --upgradable" output:
+//
+//	nginx/jammy-updates 1.18.0-6ubuntu14.5 amd64 [upgradable from: 1.18.0-6ubuntu14.4]
+func parseAptUpgradable(output string) []OutdatedPackage {
+	packages := []OutdatedPackage{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(fields[0], "/") {
+			continue
+		}
+		name, repos, _ := strings.Cut(fields[0], "/")
+		// All suites providing the candidate are kept, e.g. "jammy-updates,jammy-security"
+		pkg := OutdatedPackage{Name: name, Candidate: fields[1], Repo: repos}
+		if _, from, found := strings.Cut(line, "upgradable from: "); found {
+			pkg.Current = strings.TrimSuffix(strings.TrimSpace(from), "]")
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// parseAptGetSimulation parses the "Inst" lines of "apt-get -s upgrade":
+//
+//	Inst nginx [1.18.0-6ubuntu14.4] (1.18.0-6ubuntu14.5 Ubuntu:22.04/jammy-updates [amd64])
+func parseAptGetSimulation(output string) []OutdatedPackage {
+	packages := []OutdatedPackage{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "Inst" {
+			continue
+		}
+		pkg := OutdatedPackage{Name: fields[1]}
+		rest := fields[2:]
+		if strings.HasPrefix(rest[0], "[") {
+			pkg.Current = strings.Trim(rest[0], "[]")
+			rest = rest[1:]
+		}
+		if len(rest) > 0 {
+			pkg.Candidate = strings.TrimPrefix(rest[0], "(")
+		}
+		if len(rest) > 1 {
+			pkg.Repo = rest[1]
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// parseDnfCheckUpdate parses "dnf check-update" output ("name.arch  version  repo"),
+// stopping at the list of obsoleted packages
+func parseDnfCheckUpdate(output string) []OutdatedPackage {
+	packages := []OutdatedPackage{}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Obsoleting") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		name := fields[0]
+		if i := strings.LastIndex(name, "."); i > 0 {
+			name = name[:i]
+		}
+		packages = append(packages, OutdatedPackage{Name: name, Candidate: fields[1], Repo: fields[2]})
+	}
+	return packages
+}
+
+// fillRpmVersions looks up the installed versions of packages in the rpm database
+func fillRpmVersions(packages []OutdatedPackage) {
+	if len(packages) == 0 {
+		return
+	}
+	args := []string{"-q", "--qf", "%{NAME} %{VERSION}-%{RELEASE}\n"}
+	for _, p := range packages {
+		args = append(args, p.Name)
+	}
+
+	// rpm exits non-zero if any package is missing, the others are still printed
+	output, _ := runCommandOutput("rpm", args...)
+	installed := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if name, version, found := strings.Cut(strings.TrimSpace(line), " "); found {
+			installed[name] = version
+		}
+	}
+	for i := range packages {
+		packages[i].Current = installed[packages[i].Name]
+	}
+}
+
+// parseApkVersion parses "apk version -l '<'" output ("name-version  < candidate")
+func parseApkVersion(output string) []OutdatedPackage {
+	packages := []OutdatedPackage{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "<" {
+			continue
+		}
+		name, current := splitApkNameVersion(fields[0])
+		packages = append(packages, OutdatedPackage{Name: name, Current: current, Candidate: fields[2]})
+	}
+	return packages
+}
+
+// parsePacmanUpgradable parses "pacman -Qu" output ("name current -> candidate")
+func parsePacmanUpgradable(output string) []OutdatedPackage {
+	packages := []OutdatedPackage{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[2] != "->" {
+			continue
+		}
+		packages = append(packages, OutdatedPackage{Name: fields[0], Current: fields[1], Candidate: fields[3]})
+	}
+	return packages
+}
+
+// parseBrewOutdated parses "brew outdated --verbose" output ("name (current) < candidate")
+func parseBrewOutdated(output string) []OutdatedPackage {
+	packages := []OutdatedPackage{}
+	for _, line := range strings.Split(output, "\n") {
+		nameCurrent, candidate, found := strings.Cut(line, " < ")
+		if !found {
+			continue
+		}
+		name, current, _ := strings.Cut(strings.TrimSpace(nameCurrent), " ")
+		packages = append(packages, OutdatedPackage{
+			Name:      name,
+			Current:   strings.Trim(current, "()"),
+			Candidate: strings.TrimSpace(candidate),
+		})
+	}
+	return packages
+}
+
+func init() {
+	rootCmd.AddCommand(outdatedCmd)
+
+	outdatedCmd.Flags().Bool("json", false, "Output results as JSON")
+}
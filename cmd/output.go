@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ansiEscape matches the color codes of colorize
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// printTable renders rows as aligned columns under a header line. Columns are
+// aligned on the visible text, so that cells may be colored.
+func printTable(headers []string, rows [][]string) {
+	lines := append([][]string{headers}, rows...)
+	var widths []int
+	for _, line := range lines {
+		// The last cell of a line is not padded
+		for i := 0; i < len(line)-1; i++ {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], visibleWidth(line[i]))
+		}
+	}
+	for _, line := range lines {
+		var b strings.Builder
+		for i, cell := range line {
+			b.WriteString(cell)
+			if i < len(line)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(cell)+2))
+			}
+		}
+		fmt.Println(b.String())
+	}
+}
+
+// visibleWidth returns the width of text on the terminal, without color codes
+func visibleWidth(text string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(text, ""))
+}
+
+// printJSON writes a value to stdout as indented JSON
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON output: %v", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// yesNo returns a short label for a boolean table column
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return ""
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// FileOwner is the package that owns an installed file
+type FileOwner struct {
+	Path    string `json:"path"`
+	Package string `json:"package"`
+	Version string `json:"version,omitempty"`
+}
+
+// ownsCmd represents the owns command
+var ownsCmd = &cobra.Command{
+	Use:   "owns [paths...]",
+	Short: "Show which installed package owns a file",
+	Long: `Show which installed package owns one or more files using the native package manager.
+
+For apt-based systems (Debian/Ubuntu):
+  dpkg -S path
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  rpm -qf path
+
+For Alpine Linux:
+  apk info --who-owns path
+
+For Arch Linux:
+  pacman -Qo path
+
+For Homebrew (macOS):
+  Homebrew has no file database; files linked from a formula's Cellar
+  directory are attributed to that formula.`,
+	Example: `  pkgs owns /usr/bin/curl
+  pkgs owns /etc/nginx/nginx.conf /usr/sbin/nginx
+  pkgs owns --json /usr/bin/curl`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		owners := []FileOwner{}
+		failed := 0
+		for _, arg := range args {
+			// Relative names would be treated as search patterns by dpkg
+			path, err := filepath.Abs(arg)
+			if err != nil {
+				return fmt.Errorf("invalid path %s: %v", arg, err)
+			}
+
+			found, err := findFileOwners(pm, path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed++
+				continue
+			}
+			owners = append(owners, found...)
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := printJSON(owners); err != nil {
+				return err
+			}
+		} else if len(owners) > 0 {
+			rows := make([][]string, 0, len(owners))
+			for _, o := range owners {
+				rows = append(rows, []string{o.Path, o.Package, o.Version})
+			}
+			printTable([]string{"PATH", "PACKAGE", "VERSION"}, rows)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d paths are not owned by any package", failed, len(args))
+		}
+		return nil
+	},
+}
+
+// findFileOwners returns the packages owning path. If the path itself is not
+// known to the package manager, its aliases on merged /usr systems are tried,
+// where e.g. /bin/ls may be recorded as /usr/bin/ls or the other way round.
+func findFileOwners(pm *PackageManager, path string) ([]FileOwner, error) {
+	owners, err := queryFileOwners(pm, path)
+	if err == nil || pm.Type == "macos" {
+		return owners, err
+	}
+
+	var aliases []string
+	if resolved, resolveErr := filepath.EvalSymlinks(path); resolveErr == nil && resolved != path {
+		aliases = append(aliases, resolved)
+	}
+	if strings.HasPrefix(path, "/usr/") {
+		aliases = append(aliases, strings.TrimPrefix(path, "/usr"))
+	}
+	for _, alias := range aliases {
+		if found, aliasErr := queryFileOwners(pm, alias); aliasErr == nil {
+			for i := range found {
+				found[i].Path = path
+			}
+			return found, nil
+		}
+	}
+	return nil, err
+}
+
+// queryFileOwners asks the native package manager which packages own path
+func queryFileOwners(pm *PackageManager, path string) ([]FileOwner, error) {
+	notOwned := fmt.Errorf("no package owns %s", path)
+
+	switch pm.Type {
+	case "debian":
+		// Output: "pkg1, pkg2: /path"
+		output, err := runCommandOutput("dpkg-query", "-S", path)
+		if err != nil {
+			return nil, notOwned
+		}
+		var owners []FileOwner
+		for _, line := range strings.Split(output, "\n") {
+			names, file, found := strings.Cut(line, ": ")
+			// Diversion lines do not name owning packages
+			if !found || file != path || strings.HasPrefix(names, "diversion ") {
+				continue
+			}
+			for _, name := range strings.Split(names, ", ") {
+				owners = append(owners, FileOwner{Path: path, Package: name, Version: dpkgVersion(name)})
+			}
+		}
+		if len(owners) == 0 {
+			return nil, notOwned
+		}
+		return owners, nil
+	case "redhat":
+		output, err := runCommandOutput("rpm", "-qf", "--qf", "%{NAME} %{VERSION}-%{RELEASE}\n", path)
+		if err != nil {
+			return nil, notOwned
+		}
+		var owners []FileOwner
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			name, version, _ := strings.Cut(line, " ")
+			owners = append(owners, FileOwner{Path: path, Package: name, Version: version})
+		}
+		return owners, nil
+	case "alpine":
+		// Output: "/bin/busybox is owned by busybox-1.36.1-r2"
+		output, err := runCommandOutput("apk", "info", "--who-owns", path)
+		_, nameVersion, found := strings.Cut(strings.TrimSpace(output), " is owned by ")
+		if err != nil || !found {
+			return nil, notOwned
+		}
+		name, version := splitApkNameVersion(nameVersion)
+		return []FileOwner{{Path: path, Package: name, Version: version}}, nil
+	case "arch":
+		// Output: "/usr/bin/ls is owned by coreutils 9.4-3"
+		output, err := runCommandOutput("pacman", "-Qo", path)
+		_, nameVersion, found := strings.Cut(strings.TrimSpace(output), " is owned by ")
+		if err != nil || !found {
+			return nil, notOwned
+		}
+		name, version, _ := strings.Cut(nameVersion, " ")
+		return []FileOwner{{Path: path, Package: name, Version: version}}, nil
+	case "macos":
+		return brewFileOwner(path)
+	default:
+		return nil, unsupportedError("finding file owners is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// dpkgVersion returns the installed version of a Debian package, or "" if unknown
+func dpkgVersion(name string) string {
+	output, err := runCommandOutput("dpkg-query", "-W", "-f", "${Version}", name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// brewFileOwner attributes a file to a formula by following its symlinks into
+// the Homebrew Cellar ("<cellar>/<formula>/<version>/...")
+func brewFileOwner(path string) ([]FileOwner, error) {
+	cellar, err := runCommandOutput("brew", "--cellar")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the Homebrew Cellar: %v", err)
+	}
+	cellar = strings.TrimSpace(cellar)
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %v", path, err)
+	}
+
+	rel, err := filepath.Rel(cellar, resolved)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("%s is not part of a Homebrew formula (Homebrew does not track other files)", path)
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	owner := FileOwner{Path: path, Package: parts[0]}
+	if len(parts) > 1 {
+		owner.Version = parts[1]
+	}
+	return []FileOwner{owner}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(ownsCmd)
+
+	ownsCmd.Flags().Bool("json", false, "Output results as JSON")
+}
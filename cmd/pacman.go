@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// isPacmanQuery reports whether pacman arguments only read the package
+// database (-Q*, -Si, -Ss, -Sl, -Sg) and therefore do not need root. The
+// operation is the first short option, long options such as --noconfirm or
+// --sysroot may come before it.
+func isPacmanQuery(args []string) bool {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+			continue
+		}
+		if strings.HasPrefix(arg, "-Q") {
+			return true
+		}
+		switch arg {
+		case "-Si", "-Sii", "-Ss", "-Sl", "-Sg":
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// pacmanHookPattern matches hook progress lines such as "(2/4) Updating linux initcpios..."
+var pacmanHookPattern = regexp.MustCompile(`^\(\s*\d+/\d+\)\s+(.*)$`)
+
+// pacmanSummary holds the parts of a pacman transaction output worth repeating
+type pacmanSummary struct {
+	Hooks          []string
+	Warnings       []string
+	RebootRequired bool
+}
+
+// parsePacmanTransaction extracts alpm hook activity and warnings from pacman output
+func parsePacmanTransaction(output string) pacmanSummary {
+	var summary pacmanSummary
+	inHooks := false
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, ":: Running pre-transaction hooks") || strings.HasPrefix(line, ":: Running post-transaction hooks") {
+			inHooks = true
+			continue
+		}
+		if strings.HasPrefix(line, "::") {
+			inHooks = false
+			continue
+		}
+
+		if strings.HasPrefix(line, "warning:") {
+			summary.Warnings = append(summary.Warnings, strings.TrimSpace(strings.TrimPrefix(line, "warning:")))
+			continue
+		}
+
+		if !inHooks {
+			continue
+		}
+		if match := pacmanHookPattern.FindStringSubmatch(line); match != nil {
+			hook := match[1]
+			summary.Hooks = append(summary.Hooks, hook)
+
+			// Hooks that rebuild the initramfs or module index only run for kernel updates
+			lower := strings.ToLower(hook)
+			if strings.Contains(lower, "initcpio") || strings.Contains(lower, "module dependencies") ||
+				strings.Contains(lower, "dracut") || strings.Contains(lower, "kernel") {
+				summary.RebootRequired = true
+			}
+		}
+	}
+	return summary
+}
+
+// printPacmanSummary prints a short recap of hooks and warnings after a transaction
+func printPacmanSummary(summary pacmanSummary) {
+	if len(summary.Hooks) == 0 && len(summary.Warnings) == 0 && !summary.RebootRequired {
+		return
+	}
+
+	fmt.Println("\nSummary:")
+	if len(summary.Hooks) > 0 {
+		fmt.Printf("  Hooks run: %d\n", len(summary.Hooks))
+		for _, hook := range summary.Hooks {
+			fmt.Printf("    - %s\n", hook)
+		}
+	}
+	if len(summary.Warnings) > 0 {
+		fmt.Println("  Warnings:")
+		for _, warning := range summary.Warnings {
+			fmt.Printf("    - %s\n", colorize(warning, colorYellow))
+		}
+	}
+	if summary.RebootRequired {
+		fmt.Println("  " + colorize("Kernel updated, reboot required", colorYellow))
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pacmanSection is a repository section of pacman.conf, spanning the lines
+// from Header up to End
+type pacmanSection struct {
+	Name    string
+	Enabled bool
+	Header  int
+	End     int
+}
+
+var (
+	// pacmanHeaderPattern matches section headers, also commented out ones
+	pacmanHeaderPattern = regexp.MustCompile(`^\s*(#\s*)?\[([^\]\s]+)\]\s*$`)
+	// pacmanOptionPattern matches commented out options such as "#Server = ..."
+	pacmanOptionPattern = regexp.MustCompile(`^\s*#\s*[A-Za-z]+\s*(=.*)?$`)
+)
+
+// parsePacmanSections finds the sections of pacman.conf. A section ends at
+// the next header; the comments and blank lines before it belong to the
+// next section. A commented out section ends at the first blank line.
+func parsePacmanSections(lines []string) []pacmanSection {
+	var sections []pacmanSection
+	for i := 0; i < len(lines); i++ {
+		match := pacmanHeaderPattern.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		section := pacmanSection{Name: match[2], Enabled: match[1] == "", Header: i}
+
+		end := i + 1
+		for end < len(lines) && !pacmanHeaderPattern.MatchString(lines[end]) {
+			if !section.Enabled && strings.TrimSpace(lines[end]) == "" {
+				break
+			}
+			end++
+		}
+		for end > i+1 {
+			line := strings.TrimSpace(lines[end-1])
+			if line != "" && (!section.Enabled || !strings.HasPrefix(line, "#")) {
+				break
+			}
+			end--
+		}
+		section.End = end
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// findPacmanSection returns the repository section called name
+func findPacmanSection(lines []string, name string) (pacmanSection, bool) {
+	for _, section := range parsePacmanSections(lines) {
+		if section.Name == name && name != "options" {
+			return section, true
+		}
+	}
+	return pacmanSection{}, false
+}
+
+// addRepoPacman adds a repository section to pacman.conf. A path is added as
+// an Include of a mirror list, anything else as a Server.
+func addRepoPacman(name, url string) error {
+	content, err := readFileContent(pacmanConf)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(content, "\n")
+	if section, found := findPacmanSection(lines, name); found {
+		if !section.Enabled {
+			return fmt.Errorf("repository %s already exists in %s but is disabled, use 'pkgs enable-repo %s'", name, pacmanConf, name)
+		}
+		fmt.Printf("Repository already exists in %s\n", pacmanConf)
+		return nil
+	}
+
+	option := "Server = " + url
+	if strings.HasPrefix(url, "/") {
+		option = "Include = " + url
+	}
+	newContent := strings.TrimRight(content, "\n") + fmt.Sprintf("\n\n[%s]\n%s\n", name, option)
+	if err := writeFileContent(pacmanConf, newContent, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Repository added to %s\n", pacmanConf)
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// setRepoEnabledPacman comments or uncomments a whole section of pacman.conf
+func setRepoEnabledPacman(name string, enable bool) error {
+	content, err := readFileContent(pacmanConf)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(content, "\n")
+	section, found := findPacmanSection(lines, name)
+	if !found {
+		return fmt.Errorf("repository %s not found in %s", name, pacmanConf)
+	}
+	if section.Enabled == enable {
+		fmt.Printf("Repository %s is already %s\n", name, strings.ToLower(statusLabel(enable)))
+		return nil
+	}
+
+	for i := section.Header; i < section.End; i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case enable && (i == section.Header || pacmanOptionPattern.MatchString(line)):
+			// Remove the comment sign together with the spaces after it
+			hash := strings.Index(line, "#")
+			lines[i] = line[:hash] + strings.TrimLeft(line[hash+1:], " \t")
+		case !enable && trimmed != "" && !strings.HasPrefix(trimmed, "#"):
+			lines[i] = "#" + line
+		}
+	}
+
+	if err := confirmPlan(planStep{"write", pacmanConf}); err != nil {
+		return err
+	}
+	if err := writeFileContent(pacmanConf, strings.Join(lines, "\n"), 0644); err != nil {
+		return err
+	}
+	verb := "disabled"
+	if enable {
+		verb = "enabled"
+	}
+	fmt.Printf("Successfully %s repository %s in %s\n", verb, name, pacmanConf)
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// removeRepoPacman deletes a section from pacman.conf
+func removeRepoPacman(name string) error {
+	content, err := readFileContent(pacmanConf)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(content, "\n")
+	section, found := findPacmanSection(lines, name)
+	if !found {
+		return fmt.Errorf("repository %s not found in %s", name, pacmanConf)
+	}
+
+	// Drop the blank line separating the section from the previous one as well
+	start := section.Header
+	if start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+		start--
+	}
+	lines = append(lines[:start], lines[section.End:]...)
+	if err := writeFileContent(pacmanConf, strings.Join(lines, "\n"), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed repository %s from %s\n", name, pacmanConf)
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
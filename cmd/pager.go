@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// pagerActive is set while standard output is piped through a pager, which
+// still renders colors even though stdout is no longer a terminal
+var pagerActive bool
+
+// startPager pipes standard output through $PKGS_PAGER, $PAGER or "less" when
+// stdout is a terminal, like git does for long output. The returned function
+// restores stdout and waits for the pager to exit; it must always be called.
+func startPager() func() {
+	if noPagerFlag || !isTerminal(os.Stdout.Fd()) {
+		return func() {}
+	}
+
+	pager := os.Getenv("PKGS_PAGER")
+	if pager == "" {
+		pager = os.Getenv("PAGER")
+	}
+	if pager == "" {
+		pager = "less"
+	}
+	fields := strings.Fields(pager)
+	if len(fields) == 0 || fields[0] == "cat" {
+		return func() {}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdin = r
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Quit if the output fits on one screen, keep colors and don't clear the screen
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return func() {}
+	}
+	r.Close()
+
+	original := os.Stdout
+	os.Stdout = w
+	pagerActive = true
+
+	return func() {
+		w.Close()
+		os.Stdout = original
+		pagerActive = false
+		cmd.Wait()
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// planStep is one change a command is about to make: a native command to
+// run or a file to write or remove
+type planStep struct {
+	action string
+	target string
+}
+
+// runStep is a plan step running a native command
+func runStep(name string, args ...string) planStep {
+	return planStep{action: "run", target: strings.TrimSpace(name + " " + strings.Join(args, " "))}
+}
+
+// nativeStep is a plan step running a pkgs command with the package manager
+func nativeStep(pm *PackageManager, command string, args ...string) planStep {
+	return runStep(pm.Bin, append(slices.Clone(pm.Commands[command]), args...)...)
+}
+
+// confirmPlan shows the backend and the changes a command is about to make,
+// and asks for confirmation unless in yes mode. A dry run shows the changes
+// as they are simulated instead.
+func confirmPlan(steps ...planStep) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	fmt.Printf("Using package manager: %s\n", detectedBackend)
+	if IsDryRun() {
+		return nil
+	}
+	rows := make([][]string, 0, len(steps))
+	for _, step := range steps {
+		rows = append(rows, []string{step.action, step.target})
+	}
+	printTable([]string{"ACTION", "TARGET"}, rows)
+	if !askForConfirmation("Do you want to continue?") {
+		return errCancelled
+	}
+	return nil
+}
+
+// executeConfirmed runs a native command once the user confirmed it
+func executeConfirmed(name string, args ...string) error {
+	if err := confirmPlan(runStep(name, args...)); err != nil {
+		return err
+	}
+	return executeNative(name, args...)
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// launchpadAPI is the Launchpad web service describing PPAs
+	launchpadAPI = "https://api.launchpad.net/1.0"
+	// ppaArchive is where Launchpad publishes the packages of PPAs
+	ppaArchive = "https://ppa.launchpadcontent.net"
+	// ppaKeyserver serves the signing keys of PPAs
+	ppaKeyserver = "https://keyserver.ubuntu.com"
+)
+
+// launchpadClient is used to query Launchpad and its keyserver
+var launchpadClient = &http.Client{Transport: httpTransport, Timeout: 30 * time.Second}
+
+// parsePPA splits a "ppa:user/name" repository name. As with
+// add-apt-repository, "ppa:user" is short for "ppa:user/ppa".
+func parsePPA(name string) (user, ppa string, ok bool) {
+	rest, found := strings.CutPrefix(name, "ppa:")
+	if !found {
+		return "", "", false
+	}
+	user, ppa, found = strings.Cut(rest, "/")
+	if !found {
+		ppa = "ppa"
+	}
+	if user == "" || ppa == "" || strings.Contains(ppa, "/") {
+		return "", "", false
+	}
+	return user, ppa, true
+}
+
+// ppaSourceName returns the name of the source and key files of a PPA, the
+// one add-apt-repository uses without the release suffix
+func ppaSourceName(user, ppa string) string {
+	return user + "-ubuntu-" + ppa
+}
+
+// ubuntuCodename returns the codename of the Ubuntu release the system is or
+// is based on
+func ubuntuCodename() (string, error) {
+	if codename := osReleaseValue("UBUNTU_CODENAME"); codename != "" {
+		return codename, nil
+	}
+	if distro, _ := distroInfo(); distro == "ubuntu" {
+		if codename := osReleaseValue("VERSION_CODENAME"); codename != "" {
+			return codename, nil
+		}
+	}
+	return "", fmt.Errorf("PPAs are only available for Ubuntu and its derivatives")
+}
+
+// ppaSigningKey looks up the fingerprint of the key signing a PPA on
+// Launchpad and fetches the key from the Ubuntu keyserver
+func ppaSigningKey(user, ppa string) (fingerprint, key string, err error) {
+	apiURL := fmt.Sprintf("%s/~%s/+archive/ubuntu/%s", launchpadAPI, user, ppa)
+	body, err := launchpadGet(apiURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to look up ppa:%s/%s on Launchpad: %v", user, ppa, err)
+	}
+	var archive struct {
+		SigningKeyFingerprint string `json:"signing_key_fingerprint"`
+	}
+	if err := json.Unmarshal(body, &archive); err != nil {
+		return "", "", fmt.Errorf("failed to parse the Launchpad response for ppa:%s/%s: %v", user, ppa, err)
+	}
+	if archive.SigningKeyFingerprint == "" {
+		return "", "", fmt.Errorf("ppa:%s/%s has no signing key yet, it may not have published any packages", user, ppa)
+	}
+
+	keyURL, err := keyserverURL(ppaKeyserver, archive.SigningKeyFingerprint)
+	if err != nil {
+		return "", "", err
+	}
+	body, err = launchpadGet(keyURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch key %s: %v", archive.SigningKeyFingerprint, err)
+	}
+	if !strings.Contains(string(body), "BEGIN PGP PUBLIC KEY BLOCK") {
+		return "", "", fmt.Errorf("the keyserver returned no key for %s", archive.SigningKeyFingerprint)
+	}
+	return archive.SigningKeyFingerprint, string(body), nil
+}
+
+// launchpadGet fetches a Launchpad or keyserver URL
+func launchpadGet(url string) ([]byte, error) {
+	resp, err := launchpadClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("not found")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad status: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// addPPA adds a PPA with its signing key, without add-apt-repository
+func addPPA(user, ppa string, deb822 bool) error {
+	codename, err := ubuntuCodename()
+	if err != nil {
+		return err
+	}
+	name := ppaSourceName(user, ppa)
+
+	fmt.Printf("Looking up ppa:%s/%s on Launchpad...\n", user, ppa)
+	fingerprint, key, err := ppaSigningKey(user, ppa)
+	if err != nil {
+		return err
+	}
+	keys, err := parseOpenPGPKeys([]byte(key))
+	if err != nil {
+		return fmt.Errorf("refusing key %s: %v", fingerprint, err)
+	}
+	if len(keys) != 1 || !strings.EqualFold(keys[0].Fingerprint, fingerprint) {
+		return fmt.Errorf("the keyserver returned a different key than %s", fingerprint)
+	}
+	if err := confirmKeys(keys); err != nil {
+		return err
+	}
+	if err := ensureDirExists("/etc/apt/keyrings"); err != nil {
+		return err
+	}
+	keyPath := aptKeyPath(name)
+	if err := writeFileContent(keyPath, key, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Added key %s to %s\n", fingerprint, keyPath)
+
+	repoLine := fmt.Sprintf("deb [signed-by=%s] %s/%s/%s/ubuntu %s main", keyPath, ppaArchive, user, ppa, codename)
+	return addRepoApt(name, repoLine, deb822)
+}
+
+// removePPA removes a PPA and its key, also when it was added by
+// add-apt-repository, which appends the release to the file name
+func removePPA(user, ppa string) error {
+	name := ppaSourceName(user, ppa)
+	if _, err := findAptSourceFile(name); err != nil {
+		codename, _ := ubuntuCodename()
+		if _, errRelease := findAptSourceFile(name + "-" + codename); codename == "" || errRelease != nil {
+			return fmt.Errorf("repository ppa:%s/%s not found in %s", user, ppa, aptSourcesDir)
+		}
+		name += "-" + codename
+	}
+	if err := removeRepoApt(name, true); err != nil {
+		return err
+	}
+
+	// The key is only removed above when the sources refer to it with signed-by
+	if keyPath := aptKeyPath(ppaSourceName(user, ppa)); fileExists(keyPath) {
+		if err := removeFile(keyPath); err != nil {
+			return fmt.Errorf("failed to remove %s: %v", keyPath, err)
+		}
+		fmt.Printf("Removed %s\n", keyPath)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// readOnlyCommands only query the system and run as the invoking user
+var readOnlyCommands = map[string]bool{
+	"audit":       true,
+	"changelog":   true,
+	"changes":     true,
+	"depends":     true,
+	"diff":        true,
+	"digest":      true,
+	"doctor":      true,
+	"files":       true,
+	"freeze":      true,
+	"groups":      true,
+	"history":     true,
+	"info":        true,
+	"keys-check":  true,
+	"licenses":    true,
+	"list":        true,
+	"list-keys":   true,
+	"list-repos":  true,
+	"orphans":     true,
+	"outdated":    true,
+	"owns":        true,
+	"repo-check":  true,
+	"repo-export": true,
+	"sbom":        true,
+	"search":      true,
+	"size":        true,
+	"stats":       true,
+	"verify":      true,
+	"which":       true,
+	"why":         true,
+	"completion":  true,
+	"repo-dedupe": true, // unless --comment or --delete
+	"mirrors":     true, // unless --select-fastest or --restore
+	"watch":       true, // only with --status
+	"hold":        true, // only with --list
+	"downgrade":   true, // only with --list
+	"download":    true, // unless pacman, or apt with --deps
+
+	// Shell completion requests, hidden commands of cobra
+	cobra.ShellCompRequestCmd:       true,
+	cobra.ShellCompNoDescRequestCmd: true,
+}
+
+// NeedsRoot reports whether the command line runs a command that changes
+// the system, so that main only escalates privileges when they are needed.
+// Unknown commands do not need root, cobra reports them.
+func NeedsRoot(args []string) bool {
+	cmd, rest, err := rootCmd.Find(args)
+	if err != nil || cmd == rootCmd {
+		return false
+	}
+	return commandNeedsRoot(cmd, rest)
+}
+
+// commandNeedsRoot reports whether a command needs root with the given
+// arguments
+func commandNeedsRoot(cmd *cobra.Command, rest []string) bool {
+	if hasFlag(rest, "help", "h") {
+		return false
+	}
+	// The hosts a command runs on over SSH gain root themselves, commands in
+	// a container run as its root
+	if hasFlag(rest, "host") || hasFlag(rest, "hosts") || hasFlag(rest, "container") {
+		return false
+	}
+	// User-level package managers never need root
+	if hasFlag(rest, "user") || IsUserMode() {
+		return false
+	}
+	// Dry runs write nothing, only dnf and apk simulations need root
+	if hasFlag(rest, "dry-run") {
+		pm := detectPackageManager()
+		return pm != nil && (pm.Type == "redhat" || pm.Type == "alpine")
+	}
+	if !readOnlyCommands[cmd.Name()] {
+		return true
+	}
+
+	switch cmd.Name() {
+	case "repo-dedupe":
+		return hasFlag(rest, "comment") || hasFlag(rest, "delete")
+	case "mirrors":
+		return hasFlag(rest, "select-fastest") || hasFlag(rest, "restore")
+	case "watch":
+		return !hasFlag(rest, "status")
+	case "hold", "downgrade":
+		return !hasFlag(rest, "list")
+	case "download":
+		// pacman -Sw only runs as root, and apt-get install --download-only
+		// takes the apt locks
+		pm := detectPackageManager()
+		return pm != nil && (pm.Type == "arch" || (pm.Type == "debian" && hasFlag(rest, "deps")))
+	}
+	return false
+}
+
+// hasFlag reports whether a boolean flag is set in the arguments, as --name,
+// --name=true or, for a shorthand, -s
+func hasFlag(args []string, name string, shorthand ...string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--"+name || (strings.HasPrefix(arg, "--"+name+"=") && arg != "--"+name+"=false") {
+			return true
+		}
+		for _, s := range shorthand {
+			if arg == "-"+s {
+				return true
+			}
+		}
+	}
+	return false
+}
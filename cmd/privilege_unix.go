@@ -0,0 +1,47 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// runAsInvokingUser makes a command run as the user who invoked sudo, run0
+// or pkexec, so that read-only queries do not need root privileges
+func runAsInvokingUser(cmd *exec.Cmd) {
+	if os.Geteuid() != 0 {
+		return
+	}
+
+	uid, gid, ok := invokingUser()
+	if !ok || uid == 0 {
+		return
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)},
+	}
+}
+
+// invokingUser returns the user and group of the user who elevated pkgs.
+// sudo and run0 set SUDO_UID and SUDO_GID, pkexec only sets PKEXEC_UID.
+func invokingUser() (uid, gid int, ok bool) {
+	if uid, err := strconv.Atoi(os.Getenv("SUDO_UID")); err == nil {
+		gid, err := strconv.Atoi(os.Getenv("SUDO_GID"))
+		return uid, gid, err == nil
+	}
+	uid, err := strconv.Atoi(os.Getenv("PKEXEC_UID"))
+	if err != nil {
+		return 0, 0, false
+	}
+	u, err := user.LookupId(strconv.Itoa(uid))
+	if err != nil {
+		return 0, 0, false
+	}
+	gid, err = strconv.Atoi(u.Gid)
+	return uid, gid, err == nil
+}
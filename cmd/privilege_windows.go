@@ -0,0 +1,8 @@
+//go:build windows
+
+package cmd
+
+import "os/exec"
+
+// runAsInvokingUser is a no-op on Windows, where pkgs never elevates
+func runAsInvokingUser(cmd *exec.Cmd) {}
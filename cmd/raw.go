@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// rawCmd represents the raw command
+var rawCmd = &cobra.Command{
+	Use:   "raw -- [native arguments...]",
+	Short: "Run the native package manager with arbitrary arguments",
+	Long: `Run the detected package manager with the given arguments, for operations
+the unified commands do not cover. The arguments are passed unchanged; use "--"
+so that pkgs does not interpret native flags.
+
+pkgs still takes care of running as root and of the -y flag, which adds the
+native non-interactive flag (-y for apt/dnf/yum, --noconfirm for pacman).`,
+	Example: `  pkgs raw -- list --manual-installed
+  pkgs raw -- -Qkk openssh
+  pkgs -y raw -- install --no-install-recommends nginx`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		fmt.Printf("Using package manager: %s\n", pm.Name)
+		fullCmd := append([]string{}, args...)
+		addYesFlagIfNeeded(pm, &fullCmd)
+		return executeNative(pm.Bin, fullCmd...)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rawCmd)
+}
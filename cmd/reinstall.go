@@ -14,18 +14,16 @@ var reinstallCmd = &cobra.Command{
 	Long:    `Reinstall one or more packages on the system using the native package manager.`,
 	Example: `  pkgs reinstall nginx
   pkgs reinstall vim git curl`,
-	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completePackageNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
 		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "reinstall", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
-		}
+		return ExecuteCommand(pm, "reinstall", args)
 	},
 }
 
@@ -11,21 +11,37 @@ var removeCmd = &cobra.Command{
 	Use:     "remove [packages...]",
 	Aliases: []string{"r", "rm", "uninstall", "del"},
 	Short:   "Remove packages",
-	Long:    `Remove one or more packages from the system using the native package manager.`,
+	Long: `Remove one or more packages from the system using the native package manager.
+Package groups are removed with an "@" prefix, see 'pkgs groups'.`,
 	Example: `  pkgs remove nginx
-  pkgs remove vim git curl`,
+  pkgs remove vim git curl
+  pkgs remove @development-tools`,
 	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
-		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "remove", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		args, err := translatePackageNames(pm, args)
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			fmt.Println("Nothing to remove, the packages are not used on this system.")
+			return nil
+		}
+		args, err = translateArchQualifiers(pm, args)
+		if err != nil {
+			return err
 		}
+		args, err = translateGroupArgs(pm, args)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Using package manager: %s\n", pm.Name)
+		return ExecuteCommand(pm, "remove", args)
 	},
 }
 
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// removeKeyCmd represents the remove-key command
+var removeKeyCmd = &cobra.Command{
+	Use:     "remove-key name|fingerprint",
+	Aliases: []string{"rm-key", "delete-key"},
+	Short:   "Remove a repository key from the system",
+	Long: `Remove a trusted repository key, after confirmation. The key is given by the
+name shown by 'pkgs list-keys', or by its fingerprint or key ID.
+
+Keys that a repository still refers to are kept unless --force is given, as
+the repository could no longer be used.
+
+For apt-based systems (Debian/Ubuntu):
+  Deletes the key file from /etc/apt/keyrings/ or /etc/apt/trusted.gpg.d/.
+  Keyrings holding other keys and keyrings installed by packages are kept.
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  Removes the gpg-pubkey package with 'rpm -e'
+
+For Alpine Linux:
+  Deletes the key from /etc/apk/keys/
+
+For Arch Linux:
+  Deletes the key from the pacman keyring with 'pacman-key --delete'`,
+	Example: `  pkgs remove-key nodesource
+  pkgs remove-key 6F71F525282841EEDAF851B42F59B5F99B1BE0B4
+  pkgs remove-key gpg-pubkey-105ef944-65ca83d1`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		keys, err := listKeys(pm)
+		if keys == nil && err != nil {
+			return err
+		}
+		var matches []RepoKey
+		for _, k := range keys {
+			if keyMatches(k, args[0]) {
+				matches = append(matches, k)
+			}
+		}
+		switch {
+		case len(matches) == 0:
+			return fmt.Errorf("no key matching '%s' found, see 'pkgs list-keys'", args[0])
+		case len(matches) > 1 && !sameKeyFile(matches):
+			var names []string
+			for _, k := range matches {
+				names = append(names, fmt.Sprintf("%s (%s)", k.Name, k.ID))
+			}
+			return fmt.Errorf("'%s' matches several keys: %s", args[0], strings.Join(names, ", "))
+		}
+		key := matches[0]
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if users := keyUsers(pm, key); len(users) > 0 {
+				return fmt.Errorf("key %s is used by %s, remove the repositories first or use --force", key.Name, strings.Join(users, ", "))
+			}
+		}
+
+		label := key.Name
+		if key.Owner != "" {
+			label += " (" + key.Owner + ")"
+		}
+		if !askForConfirmation(fmt.Sprintf("Remove key %s?", label)) {
+			return errCancelled
+		}
+
+		switch pm.Type {
+		case "debian":
+			return removeKeyApt(key)
+		case "redhat":
+			return executeNative("rpm", "-e", key.Name)
+		case "alpine":
+			return removeKeyFiles([]string{key.File})
+		case "arch":
+			return executeNative("pacman-key", "--delete", key.Fingerprint)
+		default:
+			return unsupportedError("removing keys is not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+// keyMatches reports whether a key is the one given by name, fingerprint or
+// key ID. Fingerprints may be written with spaces or a 0x prefix.
+func keyMatches(k RepoKey, query string) bool {
+	if query == k.Name || query == k.File || query == filepath.Base(k.File) {
+		return true
+	}
+	id := strings.ToUpper(strings.TrimPrefix(strings.ReplaceAll(query, " ", ""), "0x"))
+	if len(id) < 8 {
+		return false
+	}
+	if k.Fingerprint != "" && strings.HasSuffix(strings.ToUpper(k.Fingerprint), id) {
+		return true
+	}
+	// A fingerprint given for a key known only by its short ID
+	return k.ID != "" && strings.HasSuffix(id, strings.ToUpper(k.ID))
+}
+
+// sameKeyFile reports whether the keys are all from one key file
+func sameKeyFile(keys []RepoKey) bool {
+	for _, k := range keys {
+		if k.File == "" || k.File != keys[0].File {
+			return false
+		}
+	}
+	return true
+}
+
+// keyUsers returns the repositories that refer to a key
+func keyUsers(pm *PackageManager, key RepoKey) []string {
+	entries, _ := listRepos(pm)
+	var users []string
+	for _, e := range entries {
+		switch {
+		case e.Key == "":
+		case key.File != "" && e.Key == key.File:
+			users = append(users, e.ID)
+		case pm.Type == "redhat" && fileExists(e.Key):
+			// rpm keys are referenced by the file they were imported from
+			fileKeys, _ := gpgShowKeys(e.Key)
+			for _, k := range fileKeys {
+				if strings.HasSuffix(strings.ToUpper(k.ID), key.ID) {
+					users = append(users, e.ID)
+					break
+				}
+			}
+		}
+	}
+	return uniqueSorted(users)
+}
+
+// removeKeyApt deletes an apt key file, unless it holds other keys or was
+// installed by a package
+func removeKeyApt(key RepoKey) error {
+	if key.File == "/etc/apt/trusted.gpg" {
+		return fmt.Errorf("%s is the legacy keyring shared by all keys and is kept", key.File)
+	}
+	if dir := filepath.Dir(key.File); dir != "/etc/apt/keyrings" && dir != "/etc/apt/trusted.gpg.d" {
+		return fmt.Errorf("%s is not in /etc/apt/keyrings or /etc/apt/trusted.gpg.d and is kept", key.File)
+	}
+	if owner, err := runCommandOutput("dpkg", "-S", key.File); err == nil {
+		pkg, _, _ := strings.Cut(owner, ":")
+		return fmt.Errorf("%s belongs to the package %s and is kept, remove the package instead", key.File, pkg)
+	}
+	if fileKeys, err := gpgShowKeys(key.File); err == nil && len(fileKeys) > 1 {
+		return fmt.Errorf("%s holds %d keys and is kept", key.File, len(fileKeys))
+	}
+	if err := removeFile(key.File); err != nil {
+		return fmt.Errorf("failed to remove key %s: %v", key.File, err)
+	}
+	fmt.Printf("Removed key %s\n", key.File)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(removeKeyCmd)
+
+	removeKeyCmd.Flags().Bool("force", false, "Remove the key even if a repository still uses it")
+}
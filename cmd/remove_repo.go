@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// removeRepoCmd represents the remove-repo command
+var removeRepoCmd = &cobra.Command{
+	Use:     "remove-repo name",
+	Aliases: []string{"rm-repo", "delete-repo"},
+	Short:   "Remove a repository from the system",
+	Long: `Remove a repository from the system package manager, after confirmation.
+
+For apt-based systems (Debian/Ubuntu):
+  Deletes /etc/apt/sources.list.d/name.list or name.sources. PPAs given as
+  ppa:user/name are removed together with their key.
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  Deletes the repository file in /etc/yum.repos.d/, or only the repository's
+  section if the file defines other repositories as well. COPR projects given
+  as copr:owner/project are removed with 'dnf copr remove'.
+
+For Alpine Linux:
+  Removes the repository from /etc/apk/repositories
+
+For Arch Linux:
+  Removes the [name] section from /etc/pacman.conf
+
+For Homebrew (macOS):
+  brew untap name
+
+With --purge-key the key the repository is signed with (signed-by= for apt,
+a local gpgkey=file:// for dnf/yum) is deleted as well, unless another
+repository still uses it.`,
+	Example: `  pkgs remove-repo nodesource
+  pkgs remove-repo --purge-key docker-ce
+  pkgs remove-repo homebrew/cask-fonts`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+		name := args[0]
+		purgeKey, _ := cmd.Flags().GetBool("purge-key")
+
+		if !askForConfirmation(fmt.Sprintf("Remove repository '%s'?", name)) {
+			return errCancelled
+		}
+
+		switch pm.Type {
+		case "debian":
+			if user, ppa, ok := parsePPA(name); ok {
+				return removePPA(user, ppa)
+			}
+			return removeRepoApt(name, purgeKey)
+		case "redhat":
+			if owner, project, ok := parseCopr(name); ok {
+				return removeCopr(pm, owner, project)
+			}
+			return removeRepoDnfYum(name, purgeKey)
+		case "alpine":
+			if purgeKey {
+				return fmt.Errorf("apk keys are not tied to a repository, remove them from /etc/apk/keys manually")
+			}
+			return removeRepoAlpine(name)
+		case "arch":
+			if purgeKey {
+				return fmt.Errorf("pacman keys are not tied to a repository, remove them with 'pacman-key --delete'")
+			}
+			return removeRepoPacman(name)
+		case "macos":
+			return executeNative("brew", "untap", name)
+		default:
+			return unsupportedError("removing repositories is not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+// removeRepoApt deletes the sources file of an apt repository
+func removeRepoApt(name string, purgeKey bool) error {
+	repoPath, err := findAptSourceFile(name)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	if purgeKey {
+		entries, err := listReposApt()
+		if err != nil && entries == nil {
+			return err
+		}
+		keys = unusedKeys(entries, repoPath)
+	}
+
+	if err := removeFile(repoPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %v", repoPath, err)
+	}
+	fmt.Printf("Removed %s\n", repoPath)
+
+	if err := removeKeyFiles(keys); err != nil {
+		return err
+	}
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// unusedKeys returns the keys of the repositories in file that no repository
+// in another file uses
+func unusedKeys(entries []repoEntry, file string) []string {
+	used := map[string]bool{}
+	var keys []string
+	for _, e := range entries {
+		if e.Key == "" {
+			continue
+		}
+		if e.File == file {
+			keys = append(keys, e.Key)
+		} else {
+			used[e.Key] = true
+		}
+	}
+	var unused []string
+	for _, key := range uniqueSorted(keys) {
+		if used[key] {
+			fmt.Printf("Keeping %s, which other repositories use\n", key)
+			continue
+		}
+		unused = append(unused, key)
+	}
+	return unused
+}
+
+// removeKeyFiles deletes key files
+func removeKeyFiles(keys []string) error {
+	for _, key := range keys {
+		if err := removeFile(key); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to remove key %s: %v", key, err)
+		}
+		fmt.Printf("Removed key %s\n", key)
+	}
+	return nil
+}
+
+// removeRepoDnfYum removes a repository from /etc/yum.repos.d, deleting the
+// file if it defines no other repository
+func removeRepoDnfYum(name string, purgeKey bool) error {
+	config := getRepoConfig("redhat")
+
+	var scanErrs fileErrors
+	repoFile, found, err := findRepoFile(config.baseDir, config.fileExtension, name, &scanErrs)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if err := scanErrs.err(); err != nil {
+			return fmt.Errorf("no repository with ID '%s' found in the readable files of %s: %w", name, config.baseDir, err)
+		}
+		return fmt.Errorf("no repository with ID '%s' found in %s", name, config.baseDir)
+	}
+
+	content, err := readFileContent(repoFile)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	if purgeKey {
+		entries, err := listReposDnfYum()
+		if err != nil && entries == nil {
+			return err
+		}
+		// Only the keys of this repository are candidates, not those of its file neighbours
+		var own []repoEntry
+		for _, e := range entries {
+			if e.File != repoFile || e.ID == name {
+				own = append(own, e)
+			}
+		}
+		keys = unusedKeys(own, repoFile)
+	}
+
+	sections := extractAllRepoSections(content)
+	if len(sections) <= 1 {
+		if err := removeFile(repoFile); err != nil {
+			return fmt.Errorf("failed to remove %s: %v", repoFile, err)
+		}
+		fmt.Printf("Removed %s\n", repoFile)
+	} else {
+		// Keep everything before the first section, such as comments
+		header := regexp.MustCompile(`(?m)^\[`).FindStringIndex(content)
+		newContent := content[:header[0]]
+		for _, section := range sections {
+			if section.id != name {
+				newContent += section.content
+			}
+		}
+		if err := writeFileContent(repoFile, newContent, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Removed repository '%s' from %s\n", name, repoFile)
+	}
+
+	if err := removeKeyFiles(keys); err != nil {
+		return err
+	}
+	return scanErrs.err()
+}
+
+// removeRepoAlpine removes a repository from /etc/apk/repositories, together
+// with the comment naming it
+func removeRepoAlpine(name string) error {
+	repoFile := "/etc/apk/repositories"
+	content, err := readFileContent(repoFile)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(content, "\n")
+	var result []string
+	removed := false
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		// A "# name" comment written by add-repo names the following line
+		if strings.HasPrefix(line, "#") && !strings.Contains(line, "://") &&
+			strings.TrimSpace(strings.TrimPrefix(line, "#")) == name && i+1 < len(lines) {
+			i++
+			removed = true
+			continue
+		}
+		if line != "" && strings.Contains(line, "://") && strings.HasSuffix(strings.TrimSuffix(line, "/"), "/"+name) {
+			removed = true
+			continue
+		}
+		result = append(result, lines[i])
+	}
+
+	if !removed {
+		return fmt.Errorf("repository %s not found in %s", name, repoFile)
+	}
+	if err := writeFileContent(repoFile, strings.Join(result, "\n"), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed repository %s from %s\n", name, repoFile)
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(removeRepoCmd)
+
+	removeRepoCmd.Flags().Bool("purge-key", false, "Also delete the key the repository is signed with")
+}
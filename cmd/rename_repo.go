@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// renameRepoCmd represents the rename-repo command
+var renameRepoCmd = &cobra.Command{
+	Use:   "rename-repo old new",
+	Short: "Rename a repository",
+	Long: `Rename a repository together with the files named after it.
+
+For apt-based systems (Debian/Ubuntu):
+  Renames /etc/apt/sources.list.d/old.list or old.sources, the key
+  /etc/apt/keyrings/old.asc added with the repository and the pin written by
+  'pkgs repo-priority', and updates the signed-by references to the key
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  Renames the [old] section to [new], and the file /etc/yum.repos.d/old.repo
+  to new.repo if it holds no other repositories. A name=old option becomes
+  name=new as well.`,
+	Example: `  pkgs rename-repo nodesource-test nodesource
+  pkgs rename-repo docker docker-ce`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		oldName, newName := args[0], args[1]
+		if oldName == newName {
+			return fmt.Errorf("the new name is the same as the old one")
+		}
+		if strings.ContainsAny(newName, "/[] \t") {
+			return fmt.Errorf("invalid repository name '%s'", newName)
+		}
+
+		switch pm.Type {
+		case "debian":
+			return renameRepoApt(oldName, newName)
+		case "redhat":
+			return renameRepoDnfYum(oldName, newName)
+		case "arch":
+			return unsupportedError("pacman repository names must match the database name on the server and cannot be renamed")
+		default:
+			return unsupportedError("renaming repositories is not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+// renameRepoApt renames the source file of a repository, its key and its pin
+func renameRepoApt(oldName, newName string) error {
+	oldPath, err := findAptSourceFile(oldName)
+	if err != nil {
+		return err
+	}
+	if existing, err := findAptSourceFile(newName); err == nil {
+		return fmt.Errorf("repository %s already exists in %s", newName, existing)
+	}
+	newPath := filepath.Join(aptSourcesDir, newName+filepath.Ext(oldPath))
+
+	content, err := readFileContent(oldPath)
+	if err != nil {
+		return err
+	}
+
+	// The key is only renamed if this repository refers to it
+	oldKey, newKey := aptKeyPath(oldName), aptKeyPath(newName)
+	renameKey := strings.Contains(content, oldKey) && fileExists(oldKey)
+	if renameKey && fileExists(newKey) {
+		return fmt.Errorf("key %s already exists", newKey)
+	}
+	oldPref := filepath.Join(aptPreferencesDir, oldName+".pref")
+	newPref := filepath.Join(aptPreferencesDir, newName+".pref")
+	renamePref := fileExists(oldPref) && !fileExists(newPref)
+
+	steps := []planStep{{"rename", oldPath + " -> " + newPath}}
+	if renameKey {
+		steps = append(steps, planStep{"rename", oldKey + " -> " + newKey})
+	}
+	if renamePref {
+		steps = append(steps, planStep{"rename", oldPref + " -> " + newPref})
+	}
+	if err := confirmPlan(steps...); err != nil {
+		return err
+	}
+
+	if renameKey {
+		if err := renameFile(oldKey, newKey); err != nil {
+			return fmt.Errorf("failed to rename %s: %v", oldKey, err)
+		}
+		content = strings.ReplaceAll(content, oldKey, newKey)
+		fmt.Printf("Renamed %s to %s\n", oldKey, newKey)
+	}
+
+	if err := writeFileContent(newPath, content, 0644); err != nil {
+		return err
+	}
+	if err := removeFile(oldPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %v", oldPath, err)
+	}
+	fmt.Printf("Renamed %s to %s\n", oldPath, newPath)
+
+	if renamePref {
+		if err := renameFile(oldPref, newPref); err != nil {
+			return fmt.Errorf("failed to rename %s: %v", oldPref, err)
+		}
+		fmt.Printf("Renamed %s to %s\n", oldPref, newPref)
+	}
+	return nil
+}
+
+// renameRepoDnfYum renames the section of a repository, and its file if the
+// file is named after it and holds nothing else
+func renameRepoDnfYum(oldName, newName string) error {
+	config := getRepoConfig("redhat")
+
+	var scanErrs fileErrors
+	repoFile, found, err := findRepoFile(config.baseDir, config.fileExtension, oldName, &scanErrs)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if err := scanErrs.err(); err != nil {
+			return fmt.Errorf("no repository with ID '%s' found in the readable files of %s: %w", oldName, config.baseDir, err)
+		}
+		return fmt.Errorf("no repository with ID '%s' found in %s", oldName, config.baseDir)
+	}
+	var ignored fileErrors
+	if existing, found, _ := findRepoFile(config.baseDir, config.fileExtension, newName, &ignored); found {
+		return fmt.Errorf("repository %s already exists in %s", newName, existing)
+	}
+
+	content, err := readFileContent(repoFile)
+	if err != nil {
+		return err
+	}
+	header := regexp.MustCompile(`(?m)^\[` + regexp.QuoteMeta(oldName) + `\]`)
+	newContent := header.ReplaceAllLiteralString(content, "["+newName+"]")
+	// add-repo names repositories after their ID, such a name follows the ID
+	namePattern := regexp.MustCompile(`(?m)^name\s*=\s*` + regexp.QuoteMeta(oldName) + `\s*$`)
+	for _, section := range extractAllRepoSections(newContent) {
+		if section.id == newName && namePattern.MatchString(section.content) {
+			newContent = setRepoOption(newContent, newName, "name", newName)
+		}
+	}
+
+	newFile := repoFile
+	renameFile := filepath.Base(repoFile) == oldName+config.fileExtension && len(extractAllRepoSections(content)) == 1
+	if renameFile {
+		newFile = filepath.Join(config.baseDir, newName+config.fileExtension)
+		if fileExists(newFile) {
+			return fmt.Errorf("%s already exists", newFile)
+		}
+	}
+
+	step := planStep{"write", newFile}
+	if renameFile {
+		step = planStep{"rename", repoFile + " -> " + newFile}
+	}
+	if err := confirmPlan(step); err != nil {
+		return err
+	}
+	if err := writeFileContent(newFile, newContent, 0644); err != nil {
+		return err
+	}
+	if renameFile {
+		if err := removeFile(repoFile); err != nil {
+			return fmt.Errorf("failed to remove %s: %v", repoFile, err)
+		}
+		fmt.Printf("Renamed %s to %s\n", repoFile, newFile)
+	}
+	fmt.Printf("Renamed repository %s to %s in %s\n", oldName, newName, newFile)
+	return scanErrs.err()
+}
+
+func init() {
+	rootCmd.AddCommand(renameRepoCmd)
+}
@@ -0,0 +1,481 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// repoCheckClient is used to probe repositories
+var repoCheckClient = &http.Client{Transport: httpTransport, Timeout: 15 * time.Second}
+
+// RepoCheck is the outcome of checking one repository
+type RepoCheck struct {
+	Repo   string `json:"repo"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// repoProbe describes how to check a repository: the index file to fetch, the
+// architectures its Release file must list and the key files it refers to
+type repoProbe struct {
+	Repo     string
+	URL      string
+	Fallback string // tried if URL cannot be fetched, e.g. Release for InRelease
+	Arches   []string
+	Keys     []string
+	Skip     string // reason the repository cannot be checked
+}
+
+// repoCheckCmd represents the repo-check command
+var repoCheckCmd = &cobra.Command{
+	Use:   "repo-check",
+	Short: "Check that the enabled repositories are reachable and usable",
+	Long: `Check every enabled repository before it breaks the next update: its index
+must be reachable, it must provide packages for the system's architecture and
+the key files it refers to must exist.
+
+For apt-based systems (Debian/Ubuntu):
+  Fetches InRelease (or Release) and checks its Architectures field and the
+  signed-by key files
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  Fetches repodata/repomd.xml of the baseurl, or the metalink or mirrorlist,
+  and checks the file:// gpgkey files
+
+For Alpine Linux:
+  Fetches APKINDEX.tar.gz for the system's architecture
+
+For Arch Linux:
+  Fetches the repository database from the first servers of each section
+
+pkgs exits with an error if any repository has a problem.`,
+	Example: `  pkgs repo-check
+  pkgs repo-check --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		var probes []repoProbe
+		var err error
+		switch pm.Type {
+		case "debian":
+			probes, err = aptProbes()
+		case "redhat":
+			probes, err = dnfProbes()
+		case "alpine":
+			probes, err = apkProbes()
+		case "arch":
+			probes, err = pacmanProbes()
+		default:
+			return unsupportedError("checking repositories is not supported for package manager '%s'", pm.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(probes) == 0 {
+			fmt.Println("No enabled repositories found.")
+			return nil
+		}
+
+		checks := runRepoProbes(probes)
+		problems := 0
+		for _, c := range checks {
+			if c.Status == "error" {
+				problems++
+			}
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := printJSON(checks); err != nil {
+				return err
+			}
+		} else {
+			rows := make([][]string, 0, len(checks))
+			for _, c := range checks {
+				status := colorize(c.Status, colorYellow)
+				switch c.Status {
+				case "ok":
+					status = colorize(c.Status, colorGreen)
+				case "skipped":
+					status = colorize(c.Status, colorGrey)
+				}
+				rows = append(rows, []string{status, c.Repo, c.URL, c.Detail})
+			}
+			printTable([]string{"STATUS", "REPOSITORY", "URL", "DETAIL"}, rows)
+		}
+
+		if problems > 0 {
+			return fmt.Errorf("%d of %d repositories have problems", problems, len(checks))
+		}
+		return nil
+	},
+}
+
+// runRepoProbes checks the repositories in parallel, keeping their order
+func runRepoProbes(probes []repoProbe) []RepoCheck {
+	checks := make([]RepoCheck, len(probes))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				checks[i] = probes[i].check()
+			}
+		}()
+	}
+	for i := range probes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return checks
+}
+
+// check runs the checks of a probe
+func (p repoProbe) check() RepoCheck {
+	result := RepoCheck{Repo: p.Repo, URL: p.URL, Status: "ok"}
+	if p.Skip != "" {
+		result.Status, result.Detail = "skipped", p.Skip
+		return result
+	}
+
+	var problems []string
+	for _, key := range p.Keys {
+		if !fileExists(key) {
+			problems = append(problems, "key "+key+" does not exist")
+		}
+	}
+
+	body, err := fetchRepoIndex(p.URL)
+	if err != nil && p.Fallback != "" {
+		if fallbackBody, fallbackErr := fetchRepoIndex(p.Fallback); fallbackErr == nil {
+			body, err = fallbackBody, nil
+			result.URL = p.Fallback
+		}
+	}
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else if len(p.Arches) > 0 {
+		if available := releaseField(body, "Architectures"); available != "" {
+			provided := strings.Fields(available)
+			for _, arch := range p.Arches {
+				if !slices.Contains(provided, arch) {
+					problems = append(problems, fmt.Sprintf("no packages for %s (only %s)", arch, available))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Status, result.Detail = "error", strings.Join(problems, "; ")
+	}
+	return result
+}
+
+// fetchRepoIndex downloads the beginning of an index file, enough for the
+// fields of a Release file
+func fetchRepoIndex(indexURL string) (string, error) {
+	if path, local := strings.CutPrefix(indexURL, "file://"); local {
+		if !fileExists(path) {
+			return "", fmt.Errorf("%s does not exist", path)
+		}
+		content, err := os.ReadFile(path)
+		return string(content), err
+	}
+
+	resp, err := repoCheckClient.Get(indexURL)
+	if err != nil {
+		// The error of the request repeats the URL, which is shown anyway
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", filepath.Base(indexURL), resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", filepath.Base(indexURL), err)
+	}
+	return string(body), nil
+}
+
+// releaseField returns a field of an apt Release file
+func releaseField(content, name string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if key, value, found := strings.Cut(scanner.Text(), ":"); found && key == name {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// aptProbes returns a probe for every suite of the enabled apt entries
+func aptProbes() ([]repoProbe, error) {
+	files, err := aptSourceFiles()
+	if err != nil {
+		return nil, err
+	}
+	nativeArch, err := runCommandOutput("dpkg", "--print-architecture")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the architecture: %v", err)
+	}
+
+	seen := map[string]bool{}
+	var probes []repoProbe
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, src := range parseAptSources(file, string(content)) {
+			if !src.Enabled {
+				continue
+			}
+
+			// The arch option restricts the architectures apt downloads
+			arches := splitList(src.Options["arch"] + " " + src.Options["architectures"])
+			if len(arches) == 0 {
+				arches = []string{strings.TrimSpace(nativeArch)}
+			}
+			if !slices.Contains(src.Types, "deb") {
+				// Source packages have no architecture
+				arches = nil
+			}
+			var keys []string
+			// signed-by may also hold fingerprints or an inline key, only paths are checked
+			for _, key := range strings.Split(src.Options["signed-by"], ",") {
+				if key = strings.TrimSpace(key); strings.HasPrefix(key, "/") {
+					keys = append(keys, key)
+				}
+			}
+
+			for _, uri := range src.URIs {
+				for _, suite := range src.Suites {
+					probe := repoProbe{Repo: aptSourceID(file) + " " + suite, Arches: arches, Keys: keys}
+					base := strings.TrimSuffix(uri, "/") + "/dists/" + suite
+					if strings.HasSuffix(suite, "/") {
+						// Flat repositories keep their index next to the suite path
+						base = strings.TrimSuffix(uri, "/") + "/" + strings.TrimSuffix(suite, "/")
+						probe.Arches = nil
+					}
+					probe.URL, probe.Fallback = base+"/InRelease", base+"/Release"
+					if scheme, _, _ := strings.Cut(uri, ":"); scheme != "http" && scheme != "https" && scheme != "file" {
+						probe.Skip = "cannot check " + scheme + " repositories"
+					}
+					if seen[probe.URL] {
+						continue
+					}
+					seen[probe.URL] = true
+					probes = append(probes, probe)
+				}
+			}
+		}
+	}
+	return probes, nil
+}
+
+// dnfVariablePattern matches $name and ${name} variables of .repo files
+var dnfVariablePattern = regexp.MustCompile(`\$\{?(\w+)\}?`)
+
+// dnfVariables returns the values of the variables used in .repo files
+func dnfVariables() map[string]string {
+	vars := map[string]string{}
+	if arch, err := runCommandOutput("uname", "-m"); err == nil {
+		vars["arch"] = strings.TrimSpace(arch)
+		vars["basearch"] = vars["arch"]
+		if strings.HasPrefix(vars["arch"], "armv7") {
+			vars["basearch"] = "armhfp"
+		}
+	}
+	distro, version := distroInfo()
+	if distro != "fedora" {
+		// RHEL and its rebuilds use the major version
+		version, _, _ = strings.Cut(version, ".")
+	}
+	vars["releasever"] = version
+
+	// Custom variables are files named after the variable
+	for _, dir := range []string{"/etc/yum/vars", "/etc/dnf/vars"} {
+		files, _ := filepath.Glob(filepath.Join(dir, "*"))
+		for _, file := range files {
+			if content, err := readFileContent(file); err == nil {
+				vars[filepath.Base(file)] = strings.TrimSpace(content)
+			}
+		}
+	}
+	return vars
+}
+
+// dnfProbes returns a probe for every enabled repository of /etc/yum.repos.d/
+func dnfProbes() ([]repoProbe, error) {
+	files, err := filepath.Glob(filepath.Join(getRepoConfig("redhat").baseDir, "*.repo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %v", err)
+	}
+
+	vars := dnfVariables()
+	expand := func(value string) (string, bool) {
+		resolved := true
+		value = dnfVariablePattern.ReplaceAllStringFunc(value, func(v string) string {
+			name := dnfVariablePattern.FindStringSubmatch(v)[1]
+			if val, ok := vars[name]; ok {
+				return val
+			}
+			resolved = false
+			return v
+		})
+		return value, resolved
+	}
+
+	optionPattern := regexp.MustCompile(`(?m)^(baseurl|metalink|mirrorlist|gpgkey)\s*=\s*(.*)$`)
+	var scanErrs fileErrors
+	var probes []repoProbe
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			scanErrs.add(err)
+			continue
+		}
+		for _, section := range extractAllRepoSections(string(content)) {
+			if repoSectionDisabled(section.content) {
+				continue
+			}
+			probe := repoProbe{Repo: section.id}
+			for _, match := range optionPattern.FindAllStringSubmatch(section.content, -1) {
+				values := splitList(match[2])
+				if len(values) == 0 {
+					continue
+				}
+				switch match[1] {
+				case "gpgkey":
+					for _, key := range values {
+						if path, local := strings.CutPrefix(key, "file://"); local {
+							probe.Keys = append(probe.Keys, path)
+						}
+					}
+				case "baseurl":
+					if probe.URL == "" {
+						probe.URL = strings.TrimSuffix(values[0], "/") + "/repodata/repomd.xml"
+					}
+				default:
+					// A metalink or mirror list takes precedence over baseurl
+					probe.URL = values[0]
+				}
+			}
+			if probe.URL == "" {
+				probe.Skip = "no baseurl, metalink or mirrorlist"
+			} else if expanded, ok := expand(probe.URL); ok {
+				probe.URL = expanded
+			} else {
+				probe.Skip = "unknown variable in the URL"
+			}
+			probes = append(probes, probe)
+		}
+	}
+	return probes, scanErrs.err()
+}
+
+// repoSectionDisabled reports whether a .repo section sets enabled=0
+func repoSectionDisabled(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if repoDisabledLinePattern.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+// apkProbes returns a probe for every repository of /etc/apk/repositories
+func apkProbes() ([]repoProbe, error) {
+	defs, err := apkDefinitions()
+	if err != nil {
+		return nil, err
+	}
+	arch, err := runCommandOutput("apk", "--print-arch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the architecture: %v", err)
+	}
+
+	probes := make([]repoProbe, 0, len(defs))
+	for _, def := range defs {
+		// Tagged repositories are written as "@tag url"
+		repo := def.Label
+		if strings.HasPrefix(repo, "@") {
+			fields := strings.Fields(repo)
+			repo = fields[len(fields)-1]
+		}
+		indexURL := strings.TrimSuffix(repo, "/") + "/" + strings.TrimSpace(arch) + "/APKINDEX.tar.gz"
+		if strings.HasPrefix(repo, "/") {
+			indexURL = "file://" + indexURL
+		}
+		probes = append(probes, repoProbe{Repo: repo, URL: indexURL})
+	}
+	return probes, nil
+}
+
+// pacmanProbes returns a probe for every enabled section of pacman.conf,
+// checking the first server, or the second one if it cannot be reached
+func pacmanProbes() ([]repoProbe, error) {
+	entries, err := listReposPacman()
+	if err != nil {
+		return nil, err
+	}
+	arch, err := runCommandOutput("uname", "-m")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the architecture: %v", err)
+	}
+
+	var probes []repoProbe
+	for _, e := range entries {
+		if !e.Enabled {
+			continue
+		}
+		probe := repoProbe{Repo: e.ID}
+		var servers []string
+		for _, server := range e.URLs {
+			server = strings.ReplaceAll(server, "$repo", e.ID)
+			server = strings.ReplaceAll(server, "$arch", strings.TrimSpace(arch))
+			servers = append(servers, strings.TrimSuffix(server, "/")+"/"+e.ID+".db")
+		}
+		switch len(servers) {
+		case 0:
+			probe.Skip = "no servers"
+		case 1:
+			probe.URL = servers[0]
+		default:
+			probe.URL, probe.Fallback = servers[0], servers[1]
+		}
+		probes = append(probes, probe)
+	}
+	return probes, nil
+}
+
+func init() {
+	rootCmd.AddCommand(repoCheckCmd)
+
+	repoCheckCmd.Flags().Bool("json", false, "Print the results as JSON")
+}
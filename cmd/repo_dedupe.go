@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// repoDedupeCmd represents the repo-dedupe command
+var repoDedupeCmd = &cobra.Command{
+	Use:   "repo-dedupe",
+	Short: "Find and clean up duplicate repository definitions",
+	Long: `Find repository definitions that repeat an earlier one, the usual cause of
+"configured multiple times" warnings after years of ad-hoc additions.
+
+Definitions are compared in the order the package manager reads them, and the
+first one is kept:
+
+For apt-based systems (Debian/Ubuntu):
+  Entries of sources.list, the .list and the .sources files are duplicates
+  when every type, URI, suite and component they define is already defined
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  Repositories in /etc/yum.repos.d/ are duplicates when they use the same
+  baseurl, metalink or mirrorlist as an earlier one
+
+For Alpine Linux:
+  Lines of /etc/apk/repositories are duplicates when they repeat a URL
+
+Entries that share only part of their definition are reported as overlapping
+but left alone. Only enabled definitions are compared.
+
+Without flags the duplicates are only reported. --comment comments them out,
+or sets 'Enabled: no' and 'enabled=0' in .sources and .repo files, and
+--delete removes them, together with files that are left empty.`,
+	Example: `  pkgs repo-dedupe
+  pkgs repo-dedupe --comment
+  pkgs -y repo-dedupe --delete`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		comment, _ := cmd.Flags().GetBool("comment")
+		remove, _ := cmd.Flags().GetBool("delete")
+		if comment && remove {
+			return fmt.Errorf("--comment and --delete cannot be used together")
+		}
+
+		var defs []repoDefinition
+		var err error
+		switch pm.Type {
+		case "debian":
+			defs, err = aptDefinitions()
+		case "redhat":
+			defs, err = dnfDefinitions()
+		case "alpine":
+			defs, err = apkDefinitions()
+		default:
+			return unsupportedError("finding duplicate repositories is not supported for package manager '%s'", pm.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		duplicates, rows := findDuplicateDefinitions(defs)
+		if len(rows) == 0 {
+			fmt.Println("No duplicate repositories found.")
+			return nil
+		}
+		printTable([]string{"LOCATION", "ENTRY", "STATUS"}, rows)
+		if len(duplicates) == 0 || (!comment && !remove) {
+			if len(duplicates) > 0 {
+				fmt.Println("\nUse --comment or --delete to clean up the duplicates.")
+			}
+			return nil
+		}
+
+		action := "Comment out"
+		if remove {
+			action = "Delete"
+		}
+		if !askForConfirmation(fmt.Sprintf("%s %d duplicate(s)?", action, len(duplicates))) {
+			return errCancelled
+		}
+		return cleanupDefinitions(duplicates, remove)
+	},
+}
+
+// repoDefinition is one repository definition spanning the lines from Start
+// up to End of its file. Keys are the parts of the definition compared to
+// find duplicates.
+type repoDefinition struct {
+	File  string
+	Start int
+	End   int
+	Kind  string // "line", "deb822" or "repo"
+	Label string
+	Keys  []string
+}
+
+// location renders the definition's position as file:line
+func (d repoDefinition) location() string {
+	return fmt.Sprintf("%s:%d", d.File, d.Start+1)
+}
+
+// findDuplicateDefinitions returns the definitions whose keys all appeared in
+// earlier ones, and report rows for them and for partial overlaps
+func findDuplicateDefinitions(defs []repoDefinition) ([]repoDefinition, [][]string) {
+	seen := map[string]repoDefinition{}
+	var duplicates []repoDefinition
+	var rows [][]string
+	for _, def := range defs {
+		var first *repoDefinition
+		matched := 0
+		for _, key := range def.Keys {
+			if earlier, ok := seen[key]; ok {
+				matched++
+				if first == nil {
+					first = &earlier
+				}
+			}
+		}
+		switch {
+		case matched == 0:
+		case matched == len(def.Keys):
+			duplicates = append(duplicates, def)
+			rows = append(rows, []string{def.location(), def.Label, "duplicate of " + first.location()})
+		default:
+			rows = append(rows, []string{def.location(), def.Label, "overlaps " + first.location()})
+		}
+		for _, key := range def.Keys {
+			if _, ok := seen[key]; !ok {
+				seen[key] = def
+			}
+		}
+	}
+	return duplicates, rows
+}
+
+// aptDefinitions returns the enabled entries of the apt source files
+func aptDefinitions() ([]repoDefinition, error) {
+	files, err := aptSourceFiles()
+	if err != nil {
+		return nil, err
+	}
+	// apt reads the files of sources.list.d in order of their names
+	sort.Strings(files[1:])
+
+	var defs []repoDefinition
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+		lines := strings.Split(string(content), "\n")
+
+		if !strings.HasSuffix(file, ".sources") {
+			for i, line := range lines {
+				if src, ok := parseAptLine(line); ok && src.Enabled {
+					defs = append(defs, repoDefinition{File: file, Start: i, End: i + 1, Kind: "line", Label: src.describe(), Keys: aptSourceKeys(src)})
+				}
+			}
+			continue
+		}
+
+		// Stanzas are runs of non-blank lines
+		for start := 0; start < len(lines); start++ {
+			if strings.TrimSpace(lines[start]) == "" {
+				continue
+			}
+			end := start
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			sources := parseDeb822(strings.Join(lines[start:end], "\n"))
+			if len(sources) == 1 && sources[0].Enabled {
+				defs = append(defs, repoDefinition{File: file, Start: start, End: end, Kind: "deb822", Label: sources[0].describe(), Keys: aptSourceKeys(sources[0])})
+			}
+			start = end
+		}
+	}
+	return defs, nil
+}
+
+// aptSourceKeys returns every type, URI, suite and component combination an
+// entry defines
+func aptSourceKeys(src aptSource) []string {
+	components := src.Components
+	if len(components) == 0 {
+		// Flat repositories have no components
+		components = []string{""}
+	}
+	var keys []string
+	for _, typ := range src.Types {
+		for _, uri := range src.URIs {
+			for _, suite := range src.Suites {
+				for _, component := range components {
+					keys = append(keys, strings.Join([]string{typ, strings.TrimSuffix(uri, "/"), suite, component}, " "))
+				}
+			}
+		}
+	}
+	return keys
+}
+
+var (
+	// repoHeaderLinePattern matches the section headers of .repo files
+	repoHeaderLinePattern = regexp.MustCompile(`^\[(.+)\]\s*$`)
+	// repoURLLinePattern matches the options of .repo files that locate packages
+	repoURLLinePattern = regexp.MustCompile(`^(?:baseurl|metalink|mirrorlist)\s*=\s*(.*)$`)
+	// repoDisabledLinePattern matches options that disable a repository
+	repoDisabledLinePattern = regexp.MustCompile(`^enabled\s*=\s*(?i:0|false|no)\s*$`)
+)
+
+// dnfDefinitions returns the enabled repositories of /etc/yum.repos.d/
+func dnfDefinitions() ([]repoDefinition, error) {
+	files, err := filepath.Glob(filepath.Join(getRepoConfig("redhat").baseDir, "*.repo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %v", err)
+	}
+	sort.Strings(files)
+
+	var scanErrs fileErrors
+	var defs []repoDefinition
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			scanErrs.add(err)
+			continue
+		}
+		lines := strings.Split(string(content), "\n")
+
+		var current *repoDefinition
+		enabled := true
+		finish := func(end int) {
+			if current != nil && enabled && len(current.Keys) > 0 {
+				current.End = end
+				defs = append(defs, *current)
+			}
+		}
+		for i, line := range lines {
+			line = strings.TrimSpace(line)
+			if match := repoHeaderLinePattern.FindStringSubmatch(line); match != nil {
+				finish(i)
+				current = &repoDefinition{File: file, Start: i, Kind: "repo", Label: match[1]}
+				enabled = true
+				continue
+			}
+			if current == nil {
+				continue
+			}
+			if repoDisabledLinePattern.MatchString(line) {
+				enabled = false
+			}
+			if match := repoURLLinePattern.FindStringSubmatch(line); match != nil {
+				for _, u := range splitList(match[1]) {
+					current.Keys = append(current.Keys, strings.TrimSuffix(u, "/"))
+				}
+			}
+		}
+		finish(len(lines))
+	}
+	return defs, scanErrs.err()
+}
+
+// apkDefinitions returns the enabled lines of /etc/apk/repositories
+func apkDefinitions() ([]repoDefinition, error) {
+	repoFile := "/etc/apk/repositories"
+	content, err := readFileContent(repoFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []repoDefinition
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		defs = append(defs, repoDefinition{File: repoFile, Start: i, End: i + 1, Kind: "line", Label: line, Keys: []string{strings.TrimSuffix(line, "/")}})
+	}
+	return defs, nil
+}
+
+// cleanupDefinitions comments out or deletes definitions. Files without any
+// remaining definitions are deleted as well when deleting.
+func cleanupDefinitions(defs []repoDefinition, remove bool) error {
+	byFile := map[string][]repoDefinition{}
+	var files []string
+	for _, def := range defs {
+		if _, ok := byFile[def.File]; !ok {
+			files = append(files, def.File)
+		}
+		byFile[def.File] = append(byFile[def.File], def)
+	}
+
+	for _, file := range files {
+		content, err := readFileContent(file)
+		if err != nil {
+			return err
+		}
+		lines := strings.Split(content, "\n")
+
+		// Later definitions first, so that the line numbers of earlier ones stay valid
+		fileDefs := byFile[file]
+		sort.Slice(fileDefs, func(i, j int) bool { return fileDefs[i].Start > fileDefs[j].Start })
+		for _, def := range fileDefs {
+			var replacement []string
+			if !remove {
+				replacement = disableDefinition(def, lines[def.Start:def.End])
+			} else if def.Kind == "deb822" && def.End < len(lines) {
+				// Drop the blank line separating the stanza from the next one
+				def.End++
+			}
+			lines = append(lines[:def.Start], append(replacement, lines[def.End:]...)...)
+		}
+
+		newContent := strings.Join(lines, "\n")
+		if remove && !hasDefinitions(newContent) && file != "/etc/apt/sources.list" && file != "/etc/apk/repositories" {
+			if err := removeFile(file); err != nil {
+				return fmt.Errorf("failed to remove %s: %v", file, err)
+			}
+			fmt.Printf("Removed %s\n", file)
+			continue
+		}
+		if err := writeFileContent(file, newContent, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Updated %s\n", file)
+	}
+	fmt.Println("Run 'pkgs update' to update the package lists.")
+	return nil
+}
+
+// disableDefinition returns the lines of a definition turned off: one-line
+// entries are commented out, deb822 stanzas get 'Enabled: no' and .repo
+// sections 'enabled=0'
+func disableDefinition(def repoDefinition, lines []string) []string {
+	result := append([]string(nil), lines...)
+	switch def.Kind {
+	case "deb822":
+		for i, line := range result {
+			if name, _, found := strings.Cut(line, ":"); found && strings.EqualFold(strings.TrimSpace(name), "Enabled") {
+				result[i] = "Enabled: no"
+				return result
+			}
+		}
+		return append(result, "Enabled: no")
+	case "repo":
+		for i, line := range result {
+			if key, _, found := strings.Cut(line, "="); found && strings.TrimSpace(key) == "enabled" {
+				result[i] = "enabled=0"
+				return result
+			}
+		}
+		// Right after the section header
+		return append([]string{result[0], "enabled=0"}, result[1:]...)
+	default:
+		result[0] = "# " + result[0]
+		return result
+	}
+}
+
+// hasDefinitions reports whether content has any line besides comments
+func hasDefinitions(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(repoDedupeCmd)
+
+	repoDedupeCmd.Flags().Bool("comment", false, "Comment out or disable the duplicates")
+	repoDedupeCmd.Flags().Bool("delete", false, "Delete the duplicates")
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// repoExportMeta is the name of the tar entry holding the export metadata
+const repoExportMeta = ".pkgs-repos.yaml"
+
+// RepoExport is the repository configuration of a system, with the files
+// that define the repositories and the keys they are signed with
+type RepoExport struct {
+	Backend       string     `yaml:"backend"`
+	Distro        string     `yaml:"distro,omitempty"`
+	DistroVersion string     `yaml:"distro_version,omitempty"`
+	Generated     time.Time  `yaml:"generated"`
+	Files         []RepoFile `yaml:"files,omitempty"`
+	Taps          []string   `yaml:"taps,omitempty"`
+}
+
+// RepoFile is a configuration or key file. Text files are stored as they
+// are, binary files such as keyrings base64-encoded.
+type RepoFile struct {
+	Path    string `yaml:"path"`
+	Content string `yaml:"content,omitempty"`
+	Base64  string `yaml:"base64,omitempty"`
+}
+
+// repoExportCmd represents the repo-export command
+var repoExportCmd = &cobra.Command{
+	Use:   "repo-export",
+	Short: "Export the repository configuration and keys",
+	Long: `Capture every repository definition and the keys the repositories are
+signed with, so that 'pkgs repo-import' can restore them on another machine
+of the same family, e.g. when rebuilding servers or baking images.
+
+The export is a YAML document, or with --format tar an archive of the files
+at their paths below /.
+
+For apt-based systems (Debian/Ubuntu):
+  /etc/apt/sources.list, the .list and .sources files in
+  /etc/apt/sources.list.d/, the pinning preferences in /etc/apt/preferences.d/
+  and the signed-by keys
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  The .repo files in /etc/yum.repos.d/ and their local gpgkey files
+
+For Alpine Linux:
+  /etc/apk/repositories and the keys in /etc/apk/keys/
+
+For Arch Linux:
+  /etc/pacman.conf and the files it includes, such as
+  /etc/pacman.d/mirrorlist. The pacman keyring is not exported.
+
+For Homebrew (macOS):
+  The list of taps`,
+	Example: `  pkgs repo-export > repos.yaml
+  pkgs repo-export --format tar -o repos.tar`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "yaml" && format != "tar" {
+			return fmt.Errorf("unsupported export format '%s' (use yaml or tar)", format)
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if format == "tar" && (output == "" || output == "-") && isTerminal(os.Stdout.Fd()) {
+			return fmt.Errorf("refusing to write a tar archive to the terminal, redirect the output or use --output")
+		}
+
+		export, err := buildRepoExport(pm)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		if format == "tar" {
+			data, err = encodeRepoTar(export)
+		} else {
+			data, err = encodeRepoYAML(export)
+		}
+		if err != nil {
+			return err
+		}
+
+		if output == "" || output == "-" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := writeFileContent(output, string(data), 0600); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d file(s) to %s\n", len(export.Files), output)
+		return nil
+	},
+}
+
+// buildRepoExport collects the repository configuration of the system
+func buildRepoExport(pm *PackageManager) (RepoExport, error) {
+	export := RepoExport{Backend: pm.Name, Generated: time.Now().UTC().Truncate(time.Second)}
+	export.Distro, export.DistroVersion = distroInfo()
+
+	if pm.Type == "macos" {
+		entries, err := listReposHomebrew()
+		if err != nil {
+			return export, err
+		}
+		for _, e := range entries {
+			export.Taps = append(export.Taps, e.ID)
+		}
+		return export, nil
+	}
+
+	paths, err := repoConfigFiles(pm)
+	if err != nil {
+		return export, err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return export, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		file := RepoFile{Path: path}
+		if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+			file.Content = string(data)
+		} else {
+			file.Base64 = base64.StdEncoding.EncodeToString(data)
+		}
+		export.Files = append(export.Files, file)
+	}
+	return export, nil
+}
+
+// repoConfigFiles lists the files that define the repositories of the
+// package manager, followed by the keys they refer to
+func repoConfigFiles(pm *PackageManager) ([]string, error) {
+	var files, keys []string
+	switch pm.Type {
+	case "debian":
+		sources, err := aptSourceFiles()
+		if err != nil {
+			return nil, err
+		}
+		preferences, _ := filepath.Glob(filepath.Join(aptPreferencesDir, "*"))
+		files = append(sources, preferences...)
+		entries, err := listReposApt()
+		if err != nil && entries == nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			// deb822 files may embed the key instead of naming a file
+			if strings.HasPrefix(e.Key, "/") {
+				keys = append(keys, e.Key)
+			}
+		}
+	case "redhat":
+		entries, err := listReposDnfYum()
+		if err != nil && entries == nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			files = append(files, e.File)
+			if e.Key != "" {
+				keys = append(keys, e.Key)
+			}
+		}
+	case "alpine":
+		files = []string{"/etc/apk/repositories"}
+		keys, _ = filepath.Glob("/etc/apk/keys/*")
+	case "arch":
+		files = []string{pacmanConf}
+		content, err := readFileContent(pacmanConf)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(content, "\n") {
+			key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+			if found && strings.TrimSpace(key) == "Include" {
+				included, _ := filepath.Glob(strings.TrimSpace(value))
+				files = append(files, included...)
+			}
+		}
+	default:
+		return nil, unsupportedError("exporting repositories is not supported for package manager '%s'", pm.Name)
+	}
+	return append(uniqueSorted(files), uniqueSorted(keys)...), nil
+}
+
+// encodeRepoYAML renders an export as YAML
+func encodeRepoYAML(export RepoExport) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(export); err != nil {
+		return nil, fmt.Errorf("failed to encode repository export: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeRepoTar renders an export as a tar archive of the files at their
+// paths below /, with the remaining fields in a metadata entry
+func encodeRepoTar(export RepoExport) ([]byte, error) {
+	files := export.Files
+	export.Files = nil
+	meta, err := encodeRepoYAML(export)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	entries := append([]RepoFile{{Path: repoExportMeta, Content: string(meta)}}, files...)
+	for _, file := range entries {
+		data, err := file.data()
+		if err != nil {
+			return nil, err
+		}
+		header := &tar.Header{
+			Name:    strings.TrimPrefix(file.Path, "/"),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: export.Generated,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write archive: %v", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to write archive: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// data returns the content of the file
+func (f RepoFile) data() ([]byte, error) {
+	if f.Base64 == "" {
+		return []byte(f.Content), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(f.Base64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content of %s: %v", f.Path, err)
+	}
+	return data, nil
+}
+
+func init() {
+	rootCmd.AddCommand(repoExportCmd)
+
+	repoExportCmd.Flags().String("format", "yaml", "Export format: yaml or tar")
+	repoExportCmd.Flags().StringP("output", "o", "", "Write the export to a file instead of stdout")
+}
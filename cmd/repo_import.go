@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// repoImportCmd represents the repo-import command
+var repoImportCmd = &cobra.Command{
+	Use:   "repo-import file",
+	Short: "Restore a repository configuration written by repo-export",
+	Long: `Restore the repository definitions and keys captured by 'pkgs repo-export'.
+The export must come from a system of the same family, e.g. an apt export
+can only be imported on Debian or Ubuntu.
+
+The files that would be added or replaced are shown before anything changes;
+use --dry-run to only show them. Afterwards the package lists are refreshed.
+On macOS the exported taps are added with 'brew tap'.`,
+	Example: `  pkgs repo-import repos.yaml --dry-run
+  pkgs repo-import repos.tar
+  ssh old-server pkgs repo-export | pkgs -y repo-import -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		export, err := readRepoExport(args[0])
+		if err != nil {
+			return err
+		}
+		if backendTypes[export.Backend] != pm.Type {
+			return fmt.Errorf("the export was written for %s and cannot be imported with %s", export.Backend, pm.Name)
+		}
+
+		if pm.Type == "macos" {
+			return importTaps(export.Taps)
+		}
+
+		// Only files that are missing or differ are written
+		var rows [][]string
+		var changed []RepoFile
+		for _, file := range export.Files {
+			data, err := file.data()
+			if err != nil {
+				return err
+			}
+			current, err := os.ReadFile(file.Path)
+			switch {
+			case err == nil && bytes.Equal(current, data):
+				continue
+			case err == nil:
+				rows = append(rows, []string{"replace", file.Path})
+			default:
+				rows = append(rows, []string{"add", file.Path})
+			}
+			changed = append(changed, file)
+		}
+		if len(changed) == 0 {
+			fmt.Println("The repository configuration is already up to date.")
+			return nil
+		}
+
+		printTable([]string{"ACTION", "FILE"}, rows)
+		if IsDryRun() {
+			return nil
+		}
+		if !askForConfirmation(fmt.Sprintf("Write %d file(s)?", len(changed))) {
+			return errCancelled
+		}
+
+		fmt.Printf("Using package manager: %s\n", pm.Name)
+		for _, file := range changed {
+			data, _ := file.data()
+			if err := ensureDirExists(filepath.Dir(file.Path)); err != nil {
+				return err
+			}
+			if err := writeFileContent(file.Path, string(data), 0644); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %s\n", file.Path)
+		}
+
+		// New sources are only used once the package lists are refreshed
+		return ExecuteCommand(pm, "update", nil)
+	},
+}
+
+// readRepoExport loads an export written by 'pkgs repo-export' in either format
+func readRepoExport(path string) (RepoExport, error) {
+	var export RepoExport
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return export, fmt.Errorf("failed to read repository export: %v", err)
+	}
+
+	// tar archives carry "ustar" at offset 257 of the first header
+	if len(data) > 262 && string(data[257:262]) == "ustar" {
+		return readRepoTar(path, data)
+	}
+	if err := yaml.Unmarshal(data, &export); err != nil {
+		return export, fmt.Errorf("failed to parse repository export %s: %v", path, err)
+	}
+	return export, nil
+}
+
+// readRepoTar loads an export written with --format tar
+func readRepoTar(path string, data []byte) (RepoExport, error) {
+	var export RepoExport
+	var files []RepoFile
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return export, fmt.Errorf("failed to read archive %s: %v", path, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return export, fmt.Errorf("failed to read archive %s: %v", path, err)
+		}
+		if header.Name == repoExportMeta {
+			if err := yaml.Unmarshal(content, &export); err != nil {
+				return export, fmt.Errorf("failed to parse %s in %s: %v", repoExportMeta, path, err)
+			}
+			continue
+		}
+		files = append(files, RepoFile{Path: filepath.Join("/", filepath.Clean(header.Name)), Content: string(content)})
+	}
+	if export.Backend == "" {
+		return export, fmt.Errorf("%s is not a repository export (missing %s)", path, repoExportMeta)
+	}
+	export.Files = files
+	return export, nil
+}
+
+// importTaps adds the Homebrew taps that are missing
+func importTaps(taps []string) error {
+	entries, err := listReposHomebrew()
+	if err != nil {
+		return err
+	}
+	tapped := map[string]bool{}
+	for _, e := range entries {
+		tapped[e.ID] = true
+	}
+	for _, tap := range taps {
+		if tapped[tap] {
+			continue
+		}
+		if err := addRepoHomebrew(tap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(repoImportCmd)
+
+}
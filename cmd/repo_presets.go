@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// presetTarget describes the system a preset repository is added to
+type presetTarget struct {
+	Distro   string // distribution ID; Ubuntu for Ubuntu derivatives
+	Codename string // release codename on apt systems, e.g. "bookworm"
+	Arch     string // dpkg architecture on apt systems, e.g. "amd64"
+	Version  string // version of the software, for presets that have one
+}
+
+// repoPreset is a well-known third-party repository. Apt returns a one-line
+// entry, Rpm a .repo file URL or a base URL; both return the key URL.
+type repoPreset struct {
+	Description    string
+	DefaultVersion string
+	Apt            func(t presetTarget) (line, key string)
+	Rpm            func(t presetTarget) (url, key string)
+	Tap            string
+}
+
+// repoPresets is the catalog of repositories known to 'pkgs add-repo --preset'
+var repoPresets = map[string]repoPreset{
+	"docker": {
+		Description: "Docker Engine (docker-ce)",
+		Apt: func(t presetTarget) (string, string) {
+			base := "https://download.docker.com/linux/" + t.Distro
+			return fmt.Sprintf("deb [arch=%s] %s %s stable", t.Arch, base, t.Codename), base + "/gpg"
+		},
+		Rpm: func(t presetTarget) (string, string) {
+			// Docker publishes Fedora and RHEL builds, the CentOS ones fit the RHEL rebuilds
+			distro := "centos"
+			if t.Distro == "fedora" || t.Distro == "rhel" {
+				distro = t.Distro
+			}
+			base := "https://download.docker.com/linux/" + distro
+			return base + "/docker-ce.repo", base + "/gpg"
+		},
+	},
+	"nodesource": {
+		Description:    "Node.js from NodeSource, e.g. nodesource@22 for Node.js 22",
+		DefaultVersion: "24",
+		Apt: func(t presetTarget) (string, string) {
+			return fmt.Sprintf("deb [arch=%s] https://deb.nodesource.com/node_%s.x nodistro main", t.Arch, t.Version),
+				"https://deb.nodesource.com/gpgkey/nodesource-repo.gpg.key"
+		},
+		Rpm: func(t presetTarget) (string, string) {
+			return fmt.Sprintf("https://rpm.nodesource.com/pub_%s.x/nodistro/nodejs/$basearch", t.Version),
+				"https://rpm.nodesource.com/gpgkey/ns-operations-public.key"
+		},
+	},
+	"postgresql": {
+		Description:    "PostgreSQL from the PGDG repositories; postgresql@16 selects the version on dnf/yum",
+		DefaultVersion: "17",
+		Apt: func(t presetTarget) (string, string) {
+			return fmt.Sprintf("deb [arch=%s] https://apt.postgresql.org/pub/repos/apt %s-pgdg main", t.Arch, t.Codename),
+				"https://www.postgresql.org/media/keys/ACCC4CF8.asc"
+		},
+		Rpm: func(t presetTarget) (string, string) {
+			base := "https://download.postgresql.org/pub/repos/yum"
+			if t.Distro == "fedora" {
+				return fmt.Sprintf("%s/%s/fedora/fedora-$releasever-$basearch", base, t.Version), base + "/keys/PGDG-RPM-GPG-KEY-Fedora"
+			}
+			return fmt.Sprintf("%s/%s/redhat/rhel-$releasever-$basearch", base, t.Version), base + "/keys/PGDG-RPM-GPG-KEY-RHEL"
+		},
+	},
+	"hashicorp": {
+		Description: "HashiCorp tools such as Terraform, Vault and Consul",
+		Apt: func(t presetTarget) (string, string) {
+			return fmt.Sprintf("deb [arch=%s] https://apt.releases.hashicorp.com %s main", t.Arch, t.Codename),
+				"https://apt.releases.hashicorp.com/gpg"
+		},
+		Rpm: func(t presetTarget) (string, string) {
+			distro := "RHEL"
+			switch t.Distro {
+			case "fedora":
+				distro = "fedora"
+			case "amzn":
+				distro = "AmazonLinux"
+			}
+			return "https://rpm.releases.hashicorp.com/" + distro + "/hashicorp.repo", "https://rpm.releases.hashicorp.com/gpg"
+		},
+		Tap: "hashicorp/tap",
+	},
+	"grafana": {
+		Description: "Grafana, Loki, Alloy and the other Grafana Labs packages",
+		Apt: func(t presetTarget) (string, string) {
+			return "deb https://apt.grafana.com stable main", "https://apt.grafana.com/gpg.key"
+		},
+		Rpm: func(t presetTarget) (string, string) {
+			return "https://rpm.grafana.com", "https://rpm.grafana.com/gpg.key"
+		},
+	},
+}
+
+// presetNames returns the names of the preset repositories
+func presetNames() []string {
+	names := make([]string, 0, len(repoPresets))
+	for name := range repoPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// presetHelp lists the preset repositories for the help text
+func presetHelp() string {
+	var b strings.Builder
+	for _, name := range presetNames() {
+		fmt.Fprintf(&b, "  %-12s %s\n", name, repoPresets[name].Description)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// resolvePreset returns the name, repository and key URL of a preset for the
+// package manager. The preset may carry a version, as in "nodesource@22".
+func resolvePreset(pm *PackageManager, spec string) (name, url, keyURL string, err error) {
+	name, version, _ := strings.Cut(spec, "@")
+	preset, ok := repoPresets[name]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown preset '%s' (available: %s)", name, strings.Join(presetNames(), ", "))
+	}
+	if version != "" && preset.DefaultVersion == "" {
+		return "", "", "", fmt.Errorf("preset '%s' has no versions", name)
+	}
+	if version == "" {
+		version = preset.DefaultVersion
+	}
+
+	target := presetTarget{Version: version}
+	target.Distro, _ = distroInfo()
+	switch pm.Type {
+	case "debian":
+		if preset.Apt == nil {
+			break
+		}
+		// Derivatives such as Linux Mint use the repositories of their Ubuntu base
+		if codename := osReleaseValue("UBUNTU_CODENAME"); codename != "" {
+			target.Distro, target.Codename = "ubuntu", codename
+		} else {
+			target.Codename = osReleaseValue("VERSION_CODENAME")
+		}
+		if target.Codename == "" {
+			return "", "", "", fmt.Errorf("cannot determine the release codename from /etc/os-release")
+		}
+		arch, err := runCommandOutput("dpkg", "--print-architecture")
+		if err != nil {
+			return "", "", "", fmt.Errorf("failed to determine the architecture: %v", err)
+		}
+		target.Arch = strings.TrimSpace(arch)
+		url, keyURL = preset.Apt(target)
+		return name, url, keyURL, nil
+	case "redhat":
+		if preset.Rpm == nil {
+			break
+		}
+		url, keyURL = preset.Rpm(target)
+		return name, url, keyURL, nil
+	case "macos":
+		if preset.Tap == "" {
+			break
+		}
+		return name, preset.Tap, "", nil
+	}
+	return "", "", "", unsupportedError("preset '%s' has no repository for package manager '%s'", name, pm.Name)
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// aptPreferencesDir holds the pinning preferences of apt
+const aptPreferencesDir = "/etc/apt/preferences.d"
+
+// repoPriorityCmd represents the repo-priority command
+var repoPriorityCmd = &cobra.Command{
+	Use:   "repo-priority name [priority]",
+	Short: "Set the priority of a repository",
+	Long: `Set which repository wins when several of them provide the same package.
+'pkgs list-repos' shows the current priorities.
+
+For apt-based systems (Debian/Ubuntu):
+  pkgs repo-priority name priority
+  Pins the hosts of the repository in /etc/apt/preferences.d/name.pref.
+  Higher values win; the default is 500, and above 1000 apt even downgrades
+  packages to the version of the repository.
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  pkgs repo-priority name priority
+  Sets 'priority=' in the repository file in /etc/yum.repos.d/.
+  Lower values win, from 1 to 99; the default is 99.
+
+With --reset the priority returns to the default.`,
+	Example: `  # Prefer packages from a repository on apt-based systems
+  pkgs repo-priority nodesource 600
+
+  # Prefer packages from a repository on dnf/yum-based systems
+  pkgs repo-priority docker-ce 10
+
+  # Return to the default priority
+  pkgs repo-priority --reset nodesource`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+		name := args[0]
+
+		reset, _ := cmd.Flags().GetBool("reset")
+		priority := 0
+		switch {
+		case reset && len(args) == 2:
+			return fmt.Errorf("give either a priority or --reset")
+		case !reset && len(args) == 1:
+			return fmt.Errorf("priority is required (usage: pkgs repo-priority name priority)")
+		case !reset:
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid priority '%s': must be a number", args[1])
+			}
+			priority = n
+		}
+
+		switch pm.Type {
+		case "debian":
+			return setRepoPriorityApt(name, priority, reset)
+		case "redhat":
+			if !reset && (priority < 1 || priority > 99) {
+				return fmt.Errorf("invalid priority %d: dnf/yum priorities range from 1 to 99", priority)
+			}
+			return setRepoPriorityDnfYum(name, priority, reset)
+		default:
+			return unsupportedError("repository priorities are not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+// setRepoPriorityApt pins the hosts of an apt repository
+func setRepoPriorityApt(name string, priority int, reset bool) error {
+	prefPath := filepath.Join(aptPreferencesDir, name+".pref")
+	if reset {
+		if !fileExists(prefPath) {
+			fmt.Printf("Repository '%s' already has the default priority\n", name)
+			return nil
+		}
+		if err := confirmPlan(planStep{"remove", prefPath}); err != nil {
+			return err
+		}
+		if err := removeFile(prefPath); err != nil {
+			return fmt.Errorf("failed to remove %s: %v", prefPath, err)
+		}
+		fmt.Printf("Removed %s\n", prefPath)
+		return nil
+	}
+
+	repoPath, err := findAptSourceFile(name)
+	if err != nil {
+		return err
+	}
+	content, err := readFileContent(repoPath)
+	if err != nil {
+		return err
+	}
+
+	// apt pins by origin, which is the host name of the repository
+	var hosts []string
+	for _, src := range parseAptSources(repoPath, content) {
+		for _, uri := range src.URIs {
+			if host := uriHost(uri); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no repository host found in %s", repoPath)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Written by 'pkgs repo-priority' for %s\n", repoPath)
+	for i, host := range uniqueSorted(hosts) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Package: *\nPin: origin %s\nPin-Priority: %d\n", host, priority)
+	}
+
+	if err := confirmPlan(planStep{"write", prefPath}); err != nil {
+		return err
+	}
+	if err := ensureDirExists(aptPreferencesDir); err != nil {
+		return err
+	}
+	if err := writeFileContent(prefPath, b.String(), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Set the priority of repository '%s' to %d in %s\n", name, priority, prefPath)
+	return nil
+}
+
+// setRepoPriorityDnfYum sets the priority option of a dnf/yum repository
+func setRepoPriorityDnfYum(name string, priority int, reset bool) error {
+	config := getRepoConfig("redhat")
+
+	var scanErrs fileErrors
+	repoFile, found, err := findRepoFile(config.baseDir, config.fileExtension, name, &scanErrs)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if err := scanErrs.err(); err != nil {
+			return fmt.Errorf("no repository with ID '%s' found in the readable files of %s: %w", name, config.baseDir, err)
+		}
+		return fmt.Errorf("no repository with ID '%s' found in %s", name, config.baseDir)
+	}
+
+	content, err := readFileContent(repoFile)
+	if err != nil {
+		return err
+	}
+
+	value := strconv.Itoa(priority)
+	if reset {
+		value = ""
+	}
+	if err := confirmPlan(planStep{"write", repoFile}); err != nil {
+		return err
+	}
+	if err := writeFileContent(repoFile, setRepoOption(content, name, "priority", value), 0644); err != nil {
+		return err
+	}
+
+	if reset {
+		fmt.Printf("Reset the priority of repository '%s' in %s\n", name, repoFile)
+	} else {
+		fmt.Printf("Set the priority of repository '%s' to %d in %s\n", name, priority, repoFile)
+	}
+	return scanErrs.err()
+}
+
+// uriHost returns the host name of a repository URI
+func uriHost(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// aptPinPriorities reads the priorities pinned to whole origins, keyed by host
+func aptPinPriorities() map[string]int {
+	files := []string{systemPath("/etc/apt/preferences")}
+	if matches, err := filepath.Glob(systemPath(filepath.Join(aptPreferencesDir, "*"))); err == nil {
+		for _, file := range matches {
+			// apt ignores files with other extensions than .pref
+			if ext := filepath.Ext(file); ext == "" || ext == ".pref" {
+				files = append(files, file)
+			}
+		}
+	}
+
+	priorities := map[string]int{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, stanza := range deb822Stanzas(string(content)) {
+			var pkg, pin, priority string
+			for _, field := range stanza {
+				switch strings.ToLower(field.Name) {
+				case "package":
+					pkg = field.Value
+				case "pin":
+					pin = field.Value
+				case "pin-priority":
+					priority = field.Value
+				}
+			}
+			origin, found := strings.CutPrefix(pin, "origin ")
+			n, err := strconv.Atoi(priority)
+			if pkg != "*" || !found || err != nil {
+				continue
+			}
+			// The first matching pin wins, as in apt
+			host := strings.Trim(strings.TrimSpace(origin), `"`)
+			if _, seen := priorities[host]; !seen {
+				priorities[host] = n
+			}
+		}
+	}
+	return priorities
+}
+
+func init() {
+	rootCmd.AddCommand(repoPriorityCmd)
+
+	repoPriorityCmd.Flags().Bool("reset", false, "Return to the default priority")
+}
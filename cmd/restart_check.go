@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// StaleProcess is a running process that still uses files replaced by an upgrade
+type StaleProcess struct {
+	PID     int      `json:"pid"`
+	Command string   `json:"command"`
+	Unit    string   `json:"unit,omitempty"`
+	Files   []string `json:"files"`
+}
+
+// manualRestartUnits are services that would end sessions or take down the
+// system bus when restarted, so they are never restarted automatically
+var manualRestartUnits = []string{"dbus", "dbus-broker", "systemd-logind", "gdm", "sddm", "lightdm", "getty@", "serial-getty@", "user@"}
+
+// restartCheckCmd represents the restart-check command
+var restartCheckCmd = &cobra.Command{
+	Use:     "restart-check",
+	Aliases: []string{"needs-restarting", "needrestart"},
+	Short:   "Find services that still use libraries replaced by an upgrade",
+	Long: `Find running processes that still use deleted executables or libraries,
+typically because the package providing them was upgraded, and show the
+systemd services they belong to.
+
+With --restart the affected services are restarted with systemctl, except for
+services like dbus and display managers that would end running sessions.
+
+pkgs scans /proc/*/maps directly, so no extra tool such as needrestart or
+'dnf needs-restarting' is required. Only Linux is supported.`,
+	Example: `  pkgs restart-check
+  pkgs restart-check --restart`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if runtime.GOOS != "linux" {
+			return unsupportedError("checking for stale processes is only supported on Linux")
+		}
+
+		processes, err := findStaleProcesses()
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			return printJSON(processes)
+		}
+
+		if fileExists("/var/run/reboot-required") {
+			fmt.Println("A reboot is required to finish the upgrade (see /var/run/reboot-required).")
+		}
+		if len(processes) == 0 {
+			fmt.Println("No processes use outdated files.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(processes))
+		for _, p := range processes {
+			files := p.Files[0]
+			if len(p.Files) > 1 {
+				files = fmt.Sprintf("%s (+%d more)", files, len(p.Files)-1)
+			}
+			rows = append(rows, []string{strconv.Itoa(p.PID), p.Command, p.Unit, files})
+		}
+		printTable([]string{"PID", "COMMAND", "SERVICE", "OUTDATED FILES"}, rows)
+
+		units, manual := staleUnits(processes)
+		if len(manual) > 0 {
+			fmt.Printf("\nThese services must be restarted manually or by rebooting: %s\n", strings.Join(manual, " "))
+		}
+		if len(units) == 0 {
+			return nil
+		}
+
+		if restart, _ := cmd.Flags().GetBool("restart"); !restart {
+			fmt.Printf("\nRestart the affected services with 'pkgs restart-check --restart' or:\n  systemctl restart %s\n", strings.Join(units, " "))
+			return nil
+		}
+		if !askForConfirmation(fmt.Sprintf("\nRestart %s?", strings.Join(units, " "))) {
+			return errCancelled
+		}
+		return executeNative("systemctl", append([]string{"restart"}, units...)...)
+	},
+}
+
+// findStaleProcesses scans /proc for processes that map deleted files from
+// system directories
+func findStaleProcesses() ([]StaleProcess, error) {
+	dirs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc: %v", err)
+	}
+
+	processes := []StaleProcess{}
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		// Processes may exit or be unreadable, they are skipped
+		maps, err := os.ReadFile(filepath.Join("/proc", dir.Name(), "maps"))
+		if err != nil {
+			continue
+		}
+		files := parseDeletedMappings(string(maps))
+		if len(files) == 0 {
+			continue
+		}
+
+		comm, _ := os.ReadFile(filepath.Join("/proc", dir.Name(), "comm"))
+		cgroup, _ := os.ReadFile(filepath.Join("/proc", dir.Name(), "cgroup"))
+		processes = append(processes, StaleProcess{
+			PID:     pid,
+			Command: strings.TrimSpace(string(comm)),
+			Unit:    systemdUnit(string(cgroup)),
+			Files:   files,
+		})
+	}
+	return processes, nil
+}
+
+// parseDeletedMappings returns the deleted files of system directories in a
+// /proc/<pid>/maps listing, e.g.
+//
+//	7f1c2a000000-7f1c2a1b0000 r-xp 00000000 08:01 1234 /usr/lib/x86_64-linux-gnu/libssl.so.3 (deleted)
+func parseDeletedMappings(maps string) []string {
+	var files []string
+	for _, line := range strings.Split(maps, "\n") {
+		path, found := strings.CutSuffix(line, " (deleted)")
+		if !found {
+			continue
+		}
+		fields := strings.Fields(path)
+		if len(fields) < 6 {
+			continue
+		}
+		path = strings.Join(fields[5:], " ")
+		// Shared memory and temporary files are deleted on purpose
+		for _, prefix := range []string{"/usr/", "/lib", "/bin/", "/sbin/", "/opt/"} {
+			if strings.HasPrefix(path, prefix) {
+				files = append(files, path)
+				break
+			}
+		}
+	}
+	return uniqueSorted(files)
+}
+
+// systemdUnit returns the systemd service of a process from its /proc/<pid>/cgroup,
+// e.g. "0::/system.slice/nginx.service" is "nginx.service"
+func systemdUnit(cgroup string) string {
+	for _, line := range strings.Split(cgroup, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, segment := range strings.Split(parts[2], "/") {
+			if strings.HasSuffix(segment, ".service") {
+				return segment
+			}
+		}
+	}
+	return ""
+}
+
+// staleUnits splits the services of stale processes into those that can be
+// restarted safely and those that need a manual restart
+func staleUnits(processes []StaleProcess) (restart, manual []string) {
+	seen := map[string]bool{}
+	for _, p := range processes {
+		if p.Unit == "" || seen[p.Unit] {
+			continue
+		}
+		seen[p.Unit] = true
+
+		isManual := false
+		for _, name := range manualRestartUnits {
+			if strings.HasPrefix(p.Unit, name+".") || (strings.HasSuffix(name, "@") && strings.HasPrefix(p.Unit, name)) {
+				isManual = true
+			}
+		}
+		if isManual {
+			manual = append(manual, p.Unit)
+		} else {
+			restart = append(restart, p.Unit)
+		}
+	}
+	sort.Strings(restart)
+	sort.Strings(manual)
+	return restart, manual
+}
+
+// printRestartHint points to restart-check after an upgrade left processes
+// running with outdated files
+func printRestartHint() {
+	if runtime.GOOS != "linux" {
+		return
+	}
+	if processes, err := findStaleProcesses(); err == nil && len(processes) > 0 {
+		fmt.Printf("\n%d process(es) still use outdated files, run 'pkgs restart-check' to see which services to restart.\n", len(processes))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(restartCheckCmd)
+
+	restartCheckCmd.Flags().Bool("restart", false, "Restart the affected services with systemctl")
+	restartCheckCmd.Flags().Bool("json", false, "Output results as JSON")
+}
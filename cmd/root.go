@@ -16,6 +16,15 @@ var version = "dev"
 var (
 	// yesFlag is used for non-interactive mode, automatically answering "yes" to prompts
 	yesFlag bool
+
+	// noPagerFlag disables piping long output through a pager
+	noPagerFlag bool
+
+	// escalateFlag names the tool to gain root privileges with, noSudoFlag
+	// forbids gaining them. main reads both before the command line is
+	// parsed, they are only declared here.
+	escalateFlag string
+	noSudoFlag   bool
 )
 
 // IsYesMode checks if we're in non-interactive mode (yes flag or environment variable)
@@ -24,6 +33,10 @@ func IsYesMode() bool {
 	if yesFlag {
 		return true
 	}
+	// --assume-no overrides the defaults
+	if assumeNoFlag {
+		return false
+	}
 
 	// Check for environment variable
 	envVar := os.Getenv("PKGS_YES")
@@ -33,6 +46,10 @@ func IsYesMode() bool {
 		return envVar == "true" || envVar == "yes" || envVar == "1" || envVar == "y"
 	}
 
+	// Fall back to the default of the configuration
+	if config, err := loadConfig(); err == nil {
+		return config.neverInteractive() || (config.Yes != nil && *config.Yes)
+	}
 	return false
 }
 
@@ -46,9 +63,57 @@ and macOS.
 
 It wraps around native package managers like yum, dnf, apt, apk, pacman and brew,
 allowing you to use the same commands regardless of the underlying system.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			cmd.Help()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+	// Errors are printed by main, which also picks the exit code
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Arguments are valid at this point, so later failures should not print usage
+		cmd.SilenceUsage = true
+		if err := checkAssumeNo(); err != nil {
+			return err
+		}
+		config, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if IsYesMode() {
+			setNonInteractiveEnv()
+		}
+		startTimeout(cmd, config)
+
+		// The command runs on the hosts instead
+		hosts, err := remoteHosts()
+		if err != nil {
+			return err
+		}
+		if len(hosts) > 0 && cmd.HasParent() {
+			remoteRun = true
+			cmd.RunE = func(cmd *cobra.Command, args []string) error {
+				return runOnHosts(cmd, hosts)
+			}
+			return nil
+		}
+		if err := startContainer(cmd.Name()); err != nil {
+			return err
+		}
+		if err := startRoot(cmd.Name()); err != nil {
+			return err
+		}
+
+		// Commands that change the system wait for each other. watch runs
+		// until it is stopped, it only takes the lock for each check.
+		changingCommand = !IsDryRun() && commandNeedsRoot(cmd, os.Args[1:])
+		if changingCommand && !noLockFlag && cmd.Name() != "watch" {
+			return acquireLock()
+		}
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		// Messages of the command may read as if changes were made
+		if IsDryRun() && !remoteRun {
+			fmt.Fprintln(os.Stderr, "Dry run: nothing was changed.")
 		}
 	},
 }
@@ -56,7 +121,9 @@ allowing you to use the same commands regardless of the underlying system.`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
-	return rootCmd.Execute()
+	defer cancelRun()
+	defer forwardSignals()()
+	return interruptedError(timeoutError(rootCmd.Execute()))
 }
 
 func init() {
@@ -67,6 +134,44 @@ func init() {
 	// Add global yes flag for non-interactive mode
 	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "Automatic yes to prompts; assume 'yes' as answer to all prompts and run non-interactively")
 
+	// Add global flag to disable the pager for long output
+	rootCmd.PersistentFlags().BoolVar(&noPagerFlag, "no-pager", false, "Do not pipe long output into a pager")
+
+	// Add global flag for user mode
+	rootCmd.PersistentFlags().BoolVar(&userFlag, "user", false, "Only use user-level package managers (brew, nix, flatpak --user) and never escalate (or set PKGS_USER=1)")
+
+	// Add global flag to choose how root privileges are gained
+	rootCmd.PersistentFlags().StringVar(&escalateFlag, "escalate", "auto", "Tool to gain root privileges with: auto, sudo, run0 or pkexec")
+	rootCmd.PersistentFlags().BoolVar(&noSudoFlag, "no-sudo", false, "Never escalate privileges, fail if root privileges are needed (or set PKGS_NO_SUDO=1)")
+
+	// Add global flag to only show what a command would do
+	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "Only show what would change: simulate package operations and print file changes without writing them")
+
+	// Add global flag to answer no to all prompts
+	rootCmd.PersistentFlags().BoolVar(&assumeNoFlag, "assume-no", false, "Answer 'no' to all prompts: show what a command would do and stop at its confirmation")
+
+	// Add global flags to run the command on other machines over SSH
+	rootCmd.PersistentFlags().StringArrayVar(&hostFlags, "host", nil, "Run the command with pkgs on this SSH host instead (can be repeated)")
+	rootCmd.PersistentFlags().StringVar(&hostsFileFlag, "hosts", "", "Run the command on the SSH hosts listed in this file, one per line")
+	rootCmd.PersistentFlags().IntVar(&parallelFlag, "parallel", 8, "Number of hosts to run the command on at a time")
+
+	// Add global flag to manage the packages of a container
+	rootCmd.PersistentFlags().StringVar(&containerFlag, "container", "", "Manage the packages of this running Docker or Podman container instead")
+
+	// Add global flag to manage the packages of another root directory
+	rootCmd.PersistentFlags().StringVar(&rootFlag, "root", "", "Manage the packages and repositories of the system mounted at this directory instead, e.g. /mnt")
+
+	// Add global flag to bound the time a command may take
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 0, "Stop the command after this long, e.g. 30m, and exit with 118 (0 for no timeout)")
+
+	// Add global flag to run without waiting for other pkgs processes
+	rootCmd.PersistentFlags().BoolVar(&noLockFlag, "no-lock", false, "Do not wait for other pkgs processes changing the system")
+
+	// Add global flags for pkgs' own downloads
+	rootCmd.PersistentFlags().StringVar(&proxyFlag, "proxy", "", "Proxy URL for pkgs' own downloads of keys and repository files")
+	rootCmd.PersistentFlags().StringVar(&caCertFlag, "ca-cert", "", "PEM bundle of additional certificate authorities for pkgs' own downloads")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerifyFlag, "insecure-skip-verify", false, "Do not verify server certificates for pkgs' own downloads")
+
 	// Override the version flag function
 	rootCmd.SetVersionTemplate(fmt.Sprintf("pkgs %s (%s/%s)\n", version, runtime.GOOS, runtime.GOARCH))
 
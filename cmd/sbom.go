@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// PackageRecord is an installed package with the metadata recorded in an SBOM
+type PackageRecord struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch,omitempty"`
+	License string `json:"license,omitempty"`
+	Repo    string `json:"repo,omitempty"`
+}
+
+// purlTypes maps package manager types to their package URL type
+var purlTypes = map[string]string{
+	"debian": "deb",
+	"redhat": "rpm",
+	"alpine": "apk",
+	"arch":   "alpm",
+	"macos":  "brew",
+}
+
+// sbomCmd represents the sbom command
+var sbomCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Generate a software bill of materials of installed packages",
+	Long: `Generate a software bill of materials (SBOM) listing every installed package
+with its version, architecture, license and the repository it was installed
+from, read from the native package database. The SBOM is written as SPDX 2.3
+or CycloneDX 1.5 JSON and identifies packages by their package URL (purl), so
+it can be consumed by compliance pipelines and vulnerability scanners.
+
+Licenses are read from:
+  apt:    the machine-readable /usr/share/doc/<package>/copyright files
+  dnf:    the rpm License tag
+  apk:    the installed database
+  pacman: pacman -Qi
+  brew:   brew info --json=v2 (formulae only)
+
+Packages without a known license or repository are recorded as NOASSERTION.`,
+	Example: `  pkgs sbom > sbom.spdx.json
+  pkgs sbom --format cyclonedx -o sbom.cdx.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "spdx" && format != "cyclonedx" {
+			return fmt.Errorf("unsupported format '%s' (use spdx or cyclonedx)", format)
+		}
+
+		records, err := listPackageRecords(pm)
+		if err != nil {
+			return err
+		}
+
+		var document any
+		if format == "spdx" {
+			document = buildSPDX(pm, records, time.Now())
+		} else {
+			document = buildCycloneDX(pm, records, time.Now())
+		}
+		// purls contain "&", which must not be escaped for other tools
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("failed to encode the SBOM: %v", err)
+		}
+		data := buf.Bytes()
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" || output == "-" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := writeFileContent(output, string(data), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", output, err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d package(s) to %s\n", len(records), output)
+		return nil
+	},
+}
+
+// listPackageRecords lists the installed packages with their license and repository.
+// Missing license or repository information is left empty.
+func listPackageRecords(pm *PackageManager) ([]PackageRecord, error) {
+	installed, err := listInstalled(pm)
+	if err != nil {
+		return nil, err
+	}
+	licenses, err := packageLicenses(pm, installed)
+	if err != nil {
+		return nil, err
+	}
+	repos := packageRepos(pm, installed)
+
+	records := make([]PackageRecord, 0, len(installed))
+	for _, p := range installed {
+		records = append(records, PackageRecord{
+			Name:    p.Name,
+			Version: p.Version,
+			Arch:    p.Arch,
+			License: licenses[p.Name],
+			Repo:    repos[p.Name],
+		})
+	}
+	return records, nil
+}
+
+// packageLicenses returns the declared license of installed packages by name
+func packageLicenses(pm *PackageManager, installed []InstalledPackage) (map[string]string, error) {
+	licenses := map[string]string{}
+	switch pm.Type {
+	case "debian":
+		for _, p := range installed {
+			content, err := readFileContent(filepath.Join("/usr/share/doc", p.Name, "copyright"))
+			if err != nil {
+				continue
+			}
+			if license := parseDebianCopyright(content); license != "" {
+				licenses[p.Name] = license
+			}
+		}
+	case "redhat":
+		output, err := runCommandOutput("rpm", "-qa", "--qf", "%{NAME}\t%{LICENSE}\n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package licenses: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			if name, license, found := strings.Cut(line, "\t"); found && license != "(none)" {
+				licenses[name] = license
+			}
+		}
+	case "alpine":
+		content, err := readFileContent(apkInstalledDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", apkInstalledDB, err)
+		}
+		var name string
+		for _, line := range strings.Split(content, "\n") {
+			key, value, _ := strings.Cut(line, ":")
+			switch key {
+			case "P":
+				name = value
+			case "L":
+				licenses[name] = value
+			}
+		}
+	case "arch":
+		output, err := runCommandOutput("pacman", "-Qi")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package licenses: %w", err)
+		}
+		var name string
+		for _, line := range strings.Split(output, "\n") {
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			switch strings.TrimSpace(key) {
+			case "Name":
+				name = value
+			case "Licenses":
+				// Output: "Licenses        : GPL-3.0-or-later  LGPL-2.1-or-later"
+				if value != "None" {
+					licenses[name] = strings.Join(strings.Fields(value), " AND ")
+				}
+			}
+		}
+	case "macos":
+		formulae, err := brewInstalledInfo()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range formulae {
+			if f.License != "" {
+				licenses[f.Name] = f.License
+			}
+		}
+	}
+	return licenses, nil
+}
+
+// parseDebianCopyright returns the licenses of a machine-readable debian/copyright
+// file joined with AND, or an empty string for free-form files
+func parseDebianCopyright(content string) string {
+	if !strings.HasPrefix(content, "Format:") {
+		return ""
+	}
+	var licenses []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(content, "\n") {
+		// Only the first line of a License field names the license, the
+		// indented lines that follow hold its text
+		value, found := strings.CutPrefix(line, "License:")
+		if !found {
+			continue
+		}
+		// Fields may combine licenses, e.g. "GPL-3+ and BSD-4-clause-UC"
+		for _, part := range strings.Split(strings.ReplaceAll(value, " AND ", " and "), " and ") {
+			part = strings.TrimSpace(part)
+			if part != "" && !seen[part] {
+				seen[part] = true
+				licenses = append(licenses, part)
+			}
+		}
+	}
+	return strings.Join(licenses, " AND ")
+}
+
+// packageRepos returns the repository installed packages were installed from
+// by name, as far as the package manager records it
+func packageRepos(pm *PackageManager, installed []InstalledPackage) map[string]string {
+	repos := map[string]string{}
+	switch pm.Name {
+	case "apt", "apt-get":
+		if len(installed) == 0 {
+			break
+		}
+		names := make([]string, 0, len(installed))
+		for _, p := range installed {
+			names = append(names, p.Name)
+		}
+		output, err := runCommandOutput("apt-cache", append([]string{"policy"}, names...)...)
+		if err == nil {
+			repos = parseAptPolicyRepos(output)
+		}
+	case "dnf":
+		output, err := runCommandOutput("dnf", "-q", "repoquery", "--installed", "--qf", "%{name} %{from_repo}\n")
+		if err == nil {
+			for _, line := range strings.Split(output, "\n") {
+				if fields := strings.Fields(line); len(fields) == 2 && fields[1] != "<unknown>" {
+					repos[fields[0]] = fields[1]
+				}
+			}
+		}
+	case "pacman":
+		// Output: "core bash 5.2.026-2 [installed]"
+		output, err := runCommandOutput("pacman", "-Sl")
+		if err == nil {
+			for _, line := range strings.Split(output, "\n") {
+				if fields := strings.Fields(line); len(fields) >= 4 && strings.HasPrefix(fields[3], "[installed") {
+					repos[fields[1]] = fields[0]
+				}
+			}
+		}
+	case "brew":
+		formulae, err := brewInstalledInfo()
+		if err == nil {
+			for _, f := range formulae {
+				repos[f.Name] = f.Tap
+			}
+		}
+	}
+	return repos
+}
+
+// parseAptPolicyRepos returns the source of the installed version of each
+// package in 'apt-cache policy' output, e.g.
+//
+//	curl:
+//	  Installed: 7.88.1-10+deb12u5
+//	  ...
+//	 *** 7.88.1-10+deb12u5 500
+//	        500 http://deb.debian.org/debian bookworm/main amd64 Packages
+//	        100 /var/lib/dpkg/status
+func parseAptPolicyRepos(output string) map[string]string {
+	repos := map[string]string{}
+	var name string
+	installed := false
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" && !strings.HasPrefix(line, " ") && strings.HasSuffix(line, ":") {
+			name = strings.TrimSuffix(line, ":")
+			installed = false
+			continue
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) > 0 && fields[0] == "***":
+			installed = true
+		case installed && len(fields) >= 3 && strings.HasPrefix(line, "        "):
+			// The dpkg status file is listed for every installed version
+			if fields[1] != "/var/lib/dpkg/status" {
+				repos[name] = fields[1] + " " + fields[2]
+				installed = false
+			}
+		default:
+			installed = false
+		}
+	}
+	return repos
+}
+
+// brewFormulaInfo is the part of 'brew info --json=v2' used by pkgs
+type brewFormulaInfo struct {
+	Name    string `json:"name"`
+	Tap     string `json:"tap"`
+	License string `json:"license"`
+}
+
+// brewInstalledInfo returns the metadata of installed Homebrew formulae
+func brewInstalledInfo() ([]brewFormulaInfo, error) {
+	output, err := runCommandOutput("brew", "info", "--json=v2", "--installed")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read formula metadata: %w", err)
+	}
+	var info struct {
+		Formulae []brewFormulaInfo `json:"formulae"`
+	}
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info output: %v", err)
+	}
+	return info.Formulae, nil
+}
+
+// packageURL returns the package URL (https://github.com/package-url/purl-spec)
+// of an installed package
+func packageURL(pm *PackageManager, distro, distroVersion string, r PackageRecord) string {
+	purl := "pkg:" + purlTypes[pm.Type] + "/"
+	if pm.Type != "macos" && distro != "" {
+		purl += url.PathEscape(distro) + "/"
+	}
+	purl += url.PathEscape(r.Name) + "@" + url.QueryEscape(r.Version)
+
+	var qualifiers []string
+	if r.Arch != "" {
+		qualifiers = append(qualifiers, "arch="+url.QueryEscape(r.Arch))
+	}
+	if pm.Type != "macos" && distro != "" && distroVersion != "" {
+		qualifiers = append(qualifiers, "distro="+url.QueryEscape(distro+"-"+distroVersion))
+	}
+	if len(qualifiers) > 0 {
+		purl += "?" + strings.Join(qualifiers, "&")
+	}
+	return purl
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// orNoAssertion returns the SPDX NOASSERTION value for unknown fields
+func orNoAssertion(value string) string {
+	if value == "" {
+		return "NOASSERTION"
+	}
+	return value
+}
+
+// buildSPDX renders the package records as an SPDX 2.3 document
+func buildSPDX(pm *PackageManager, records []PackageRecord, now time.Time) map[string]any {
+	host, _ := os.Hostname()
+	distro, distroVersion := distroInfo()
+
+	packages := make([]map[string]any, 0, len(records))
+	for i, r := range records {
+		pkg := map[string]any{
+			"name":             r.Name,
+			"SPDXID":           fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			"versionInfo":      r.Version,
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed":    false,
+			"licenseDeclared":  orNoAssertion(r.License),
+			"licenseConcluded": "NOASSERTION",
+			"copyrightText":    "NOASSERTION",
+			"externalRefs": []map[string]string{{
+				"referenceCategory": "PACKAGE-MANAGER",
+				"referenceType":     "purl",
+				"referenceLocator":  packageURL(pm, distro, distroVersion, r),
+			}},
+		}
+		if r.Repo != "" {
+			pkg["sourceInfo"] = "installed from " + r.Repo
+		}
+		packages = append(packages, pkg)
+	}
+
+	return map[string]any{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              host,
+		"documentNamespace": fmt.Sprintf("https://spdx.org/spdxdocs/pkgs-%s-%s", url.PathEscape(host), newUUID()),
+		"creationInfo": map[string]any{
+			"created":  now.UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: pkgs-" + version},
+		},
+		"packages": packages,
+	}
+}
+
+// buildCycloneDX renders the package records as a CycloneDX 1.5 document
+func buildCycloneDX(pm *PackageManager, records []PackageRecord, now time.Time) map[string]any {
+	host, _ := os.Hostname()
+	distro, distroVersion := distroInfo()
+
+	components := make([]map[string]any, 0, len(records))
+	for _, r := range records {
+		purl := packageURL(pm, distro, distroVersion, r)
+		component := map[string]any{
+			"type":     "library",
+			"bom-ref":  purl,
+			"name":     r.Name,
+			"version":  r.Version,
+			"purl":     purl,
+			"licenses": []map[string]string{},
+		}
+		if r.License != "" {
+			component["licenses"] = []map[string]string{{"expression": r.License}}
+		}
+		var properties []map[string]string
+		if r.Arch != "" {
+			properties = append(properties, map[string]string{"name": "pkgs:arch", "value": r.Arch})
+		}
+		if r.Repo != "" {
+			properties = append(properties, map[string]string{"name": "pkgs:repo", "value": r.Repo})
+		}
+		if properties != nil {
+			component["properties"] = properties
+		}
+		components = append(components, component)
+	}
+
+	return map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + newUUID(),
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": now.UTC().Format(time.RFC3339),
+			"tools": map[string]any{
+				"components": []map[string]string{{"type": "application", "name": "pkgs", "version": version}},
+			},
+			"component": map[string]string{
+				"type":    "operating-system",
+				"name":    distro,
+				"version": distroVersion,
+				"bom-ref": host,
+			},
+		},
+		"components": components,
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(sbomCmd)
+
+	sbomCmd.Flags().String("format", "spdx", "SBOM format (spdx or cyclonedx)")
+	sbomCmd.Flags().StringP("output", "o", "", "Write the SBOM to a file instead of stdout")
+}
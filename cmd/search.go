@@ -1,34 +1,352 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os/exec"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+// SearchResult is a single package match in a normalized form
+type SearchResult struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	Repo      string `json:"repo,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+	Installed bool   `json:"installed"`
+}
+
 // searchCmd represents the search command
 var searchCmd = &cobra.Command{
 	Use:     "search [query]",
 	Aliases: []string{"s", "find"},
 	Short:   "Search for packages",
-	Long:    `Search for packages in the repositories using the native package manager.`,
+	Long: `Search for packages in the repositories using the native package manager.
+
+Results are parsed from the native output and shown as a table with the same
+columns on every system. Use --json for machine-readable output or --raw to
+see the package manager's own output unchanged.
+
+The INSTALLED column is filled in from the installed package database, so
+--installed and --not-installed can restrict the results to packages that are
+or are not installed.`,
 	Example: `  pkgs search nginx
-  pkgs search python`,
+  pkgs search python
+  pkgs search --installed python3
+  pkgs search --not-installed nginx
+  pkgs search --json nginx
+  pkgs search --raw nginx`,
 	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
-		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "search", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		// Raw mode streams the native output as-is
+		if raw, _ := cmd.Flags().GetBool("raw"); raw {
+			fmt.Printf("Using package manager: %s\n", pm.Name)
+			return ExecuteCommand(pm, "search", args)
 		}
+
+		results, err := searchPackages(pm, args)
+		if err != nil {
+			return err
+		}
+
+		onlyInstalled, _ := cmd.Flags().GetBool("installed")
+		notInstalled, _ := cmd.Flags().GetBool("not-installed")
+		if onlyInstalled || notInstalled {
+			results = filterSearchResults(results, onlyInstalled)
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			return printJSON(results)
+		}
+
+		defer startPager()()
+
+		if len(results) == 0 {
+			fmt.Println("No packages found.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(results))
+		for _, r := range results {
+			rows = append(rows, []string{r.Name, r.Version, r.Repo, yesNo(r.Installed), r.Summary})
+		}
+		printTable([]string{"NAME", "VERSION", "REPO", "INSTALLED", "SUMMARY"}, rows)
+		return nil
 	},
 }
 
+// noSearchMatches reports whether a failed native search only found nothing.
+// pacman, dnf, yum and Homebrew exit with 1 when nothing matches, any other
+// failure is reported.
+func noSearchMatches(pm *PackageManager, err error, stderr string) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		return false
+	}
+	switch pm.Name {
+	case "pacman":
+		return strings.TrimSpace(stderr) == ""
+	case "dnf", "yum":
+		return strings.Contains(stderr, "No match")
+	case "brew":
+		return strings.Contains(stderr, "No formulae or casks found")
+	}
+	return false
+}
+
+// searchPackages runs the native search and parses its output
+func searchPackages(pm *PackageManager, terms []string) ([]SearchResult, error) {
+	var bin string
+	var args []string
+	switch pm.Name {
+	case "apt":
+		bin, args = "apt", []string{"search"}
+	case "apt-get":
+		// apt-get has no search command, apt-cache provides it
+		bin, args = "apt-cache", []string{"search"}
+	case "apk":
+		bin, args = "apk", []string{"search", "-v"}
+	default:
+		bin, args = pm.Bin, append([]string{}, pm.Commands["search"]...)
+	}
+	args = append(args, terms...)
+
+	output, stderr, err := runCommandOutputs(bin, args...)
+	if err != nil && strings.TrimSpace(output) == "" {
+		if noSearchMatches(pm, err, stderr) {
+			return []SearchResult{}, nil
+		}
+		if message := strings.TrimSpace(stderr); message != "" {
+			return nil, fmt.Errorf("%s search failed: %v: %s", bin, err, message)
+		}
+		return nil, fmt.Errorf("%s search failed: %v", bin, err)
+	}
+
+	var results []SearchResult
+	switch pm.Name {
+	case "apt":
+		results = parseAptSearch(output)
+	case "apt-get":
+		results = parseAptCacheSearch(output)
+	case "dnf", "yum":
+		results = parseDnfSearch(output)
+	case "apk":
+		results = parseApkSearch(output)
+	case "pacman":
+		results = parsePacmanSearch(output)
+	case "brew":
+		results = parseBrewSearch(output)
+	default:
+		return nil, unsupportedError("search parsing is not supported for package manager '%s'", pm.Name)
+	}
+
+	// Not every native search marks installed packages, the package database does
+	installed, err := listInstalled(pm)
+	if err != nil {
+		return nil, err
+	}
+	isInstalled := map[string]bool{}
+	for _, p := range installed {
+		isInstalled[p.Name] = true
+	}
+	for i := range results {
+		results[i].Installed = results[i].Installed || isInstalled[results[i].Name]
+	}
+	return results, nil
+}
+
+// filterSearchResults keeps only the installed or only the not installed results
+func filterSearchResults(results []SearchResult, installed bool) []SearchResult {
+	filtered := []SearchResult{}
+	for _, r := range results {
+		if r.Installed == installed {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// parseAptSearch parses "apt search" output:
+//
+//	nginx/jammy-updates,now 1.18.0-6ubuntu14.4 amd64 [installed]
+//	  small, powerful, scalable web/proxy server
+func parseAptSearch(output string) []SearchResult {
+	results := []SearchResult{}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" || line == "Sorting..." || line == "Full Text Search..." {
+			continue
+		}
+
+		// Indented lines hold the summary of the previous package
+		if strings.HasPrefix(line, " ") {
+			if len(results) > 0 {
+				results[len(results)-1].Summary = strings.TrimSpace(line)
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(fields[0], "/") {
+			continue
+		}
+		nameRepo := strings.SplitN(fields[0], "/", 2)
+		result := SearchResult{
+			Name:      nameRepo[0],
+			Version:   fields[1],
+			Installed: strings.Contains(line, "[installed"),
+		}
+		for _, repo := range strings.Split(nameRepo[1], ",") {
+			if repo != "now" {
+				result.Repo = repo
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// parseAptCacheSearch parses "apt-cache search" output ("name - summary")
+func parseAptCacheSearch(output string) []SearchResult {
+	results := []SearchResult{}
+	for _, line := range strings.Split(output, "\n") {
+		name, summary, found := strings.Cut(line, " - ")
+		if !found {
+			continue
+		}
+		results = append(results, SearchResult{Name: strings.TrimSpace(name), Summary: strings.TrimSpace(summary)})
+	}
+	return results
+}
+
+// parseDnfSearch parses dnf/yum search output ("name.arch : summary"),
+// including the tab-separated layout used by dnf5
+func parseDnfSearch(output string) []SearchResult {
+	results := []SearchResult{}
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "=") || strings.HasPrefix(trimmed, "Last metadata") ||
+			strings.HasPrefix(trimmed, "Matched fields") || strings.HasPrefix(trimmed, "Updating and loading") {
+			continue
+		}
+
+		var nameArch, summary string
+		if before, after, found := strings.Cut(trimmed, " : "); found {
+			nameArch, summary = before, after
+		} else if before, after, found := strings.Cut(trimmed, "\t"); found {
+			nameArch, summary = before, after
+		} else {
+			continue
+		}
+
+		nameArch = strings.TrimSpace(nameArch)
+		if i := strings.LastIndex(nameArch, "."); i > 0 {
+			nameArch = nameArch[:i]
+		}
+		results = append(results, SearchResult{Name: nameArch, Summary: strings.TrimSpace(summary)})
+	}
+	return results
+}
+
+// parseApkSearch parses "apk search -v" output ("name-version - summary")
+func parseApkSearch(output string) []SearchResult {
+	results := []SearchResult{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		nameVersion, summary, _ := strings.Cut(line, " - ")
+		name, version := splitApkNameVersion(nameVersion)
+		results = append(results, SearchResult{Name: name, Version: version, Summary: strings.TrimSpace(summary)})
+	}
+	return results
+}
+
+// splitApkNameVersion splits an apk "name-version-rN" string into name and version
+func splitApkNameVersion(s string) (string, string) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 {
+		return s, ""
+	}
+	return strings.Join(parts[:len(parts)-2], "-"), strings.Join(parts[len(parts)-2:], "-")
+}
+
+// parsePacmanSearch parses "pacman -Ss" output:
+//
+//	extra/nginx 1.24.0-2 [installed]
+//	    Lightweight HTTP server and IMAP/POP3 proxy server
+func parsePacmanSearch(output string) []SearchResult {
+	results := []SearchResult{}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, " ") {
+			if len(results) > 0 {
+				results[len(results)-1].Summary = strings.TrimSpace(line)
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		repo, name, found := strings.Cut(fields[0], "/")
+		if !found {
+			continue
+		}
+		results = append(results, SearchResult{
+			Name:      name,
+			Version:   fields[1],
+			Repo:      repo,
+			Installed: strings.Contains(line, "[installed"),
+		})
+	}
+	return results
+}
+
+// parseBrewSearch parses "brew search" output, which lists names under
+// "==> Formulae" and "==> Casks" headings
+func parseBrewSearch(output string) []SearchResult {
+	results := []SearchResult{}
+	section := "formulae"
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "==>") {
+			section = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "==>")))
+			continue
+		}
+		for _, name := range strings.Fields(line) {
+			// brew marks installed formulae with a check mark
+			installed := strings.HasSuffix(name, "✔")
+			name = strings.TrimSuffix(name, "✔")
+			results = append(results, SearchResult{Name: name, Repo: section, Installed: installed})
+		}
+	}
+	return results
+}
+
 func init() {
 	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().Bool("json", false, "Output results as JSON")
+	searchCmd.Flags().Bool("raw", false, "Show the native package manager output unchanged")
+	searchCmd.Flags().Bool("installed", false, "Only show installed packages")
+	searchCmd.Flags().Bool("not-installed", false, "Only show packages that are not installed")
+	searchCmd.MarkFlagsMutuallyExclusive("installed", "not-installed")
+	searchCmd.MarkFlagsMutuallyExclusive("raw", "installed")
+	searchCmd.MarkFlagsMutuallyExclusive("raw", "not-installed")
 }
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// forwardedSignals are passed on to the running package manager instead of
+// ending pkgs, which would leave dpkg or rpm half done
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// children tracks the native commands started with runChild
+var children = struct {
+	sync.Mutex
+	running     map[*exec.Cmd]bool
+	interrupted os.Signal
+}{running: map[*exec.Cmd]bool{}}
+
+// runChild runs a native command that changes the system. While it runs,
+// SIGINT and SIGTERM are forwarded to it and pkgs waits for it to exit;
+// after such a signal no further command is started.
+func runChild(cmd *exec.Cmd) error {
+	children.Lock()
+	if sig := children.interrupted; sig != nil {
+		children.Unlock()
+		return fmt.Errorf("not running %s: interrupted by %v", cmd.Path, sig)
+	}
+	if err := cmd.Start(); err != nil {
+		children.Unlock()
+		logCommand(cmd, err)
+		return err
+	}
+	children.running[cmd] = true
+	children.Unlock()
+
+	err := cmd.Wait()
+
+	children.Lock()
+	delete(children.running, cmd)
+	children.Unlock()
+	logCommand(cmd, err)
+	return err
+}
+
+// forwardSignals traps SIGINT and SIGTERM until stop is called. Without a
+// running command pkgs exits right away, as it would without the trap.
+func forwardSignals() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	go func() {
+		for sig := range signals {
+			children.Lock()
+			children.interrupted = sig
+			if len(children.running) == 0 {
+				children.Unlock()
+				os.Exit(signalExitCode(sig))
+			}
+			for cmd := range children.running {
+				// A terminal sends Ctrl-C to its whole foreground group,
+				// commands in that group already received it
+				if sig == os.Interrupt && !ownProcessGroup(cmd) && isTerminal(os.Stdin.Fd()) {
+					continue
+				}
+				signalCommand(cmd, sig)
+			}
+			children.Unlock()
+		}
+	}()
+	return func() { signal.Stop(signals) }
+}
+
+// interruptedError reports that pkgs stopped because of a signal, so that it
+// exits with 128 plus the signal number like a shell does
+func interruptedError(err error) error {
+	children.Lock()
+	sig := children.interrupted
+	children.Unlock()
+	if sig == nil {
+		return err
+	}
+	if err == nil {
+		return withExitCode(signalExitCode(sig), fmt.Errorf("interrupted by %v", sig))
+	}
+	return withExitCode(signalExitCode(sig), fmt.Errorf("interrupted by %v: %w", sig, err))
+}
+
+// signalExitCode returns the exit code of a process ended by a signal
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return ExitError
+}
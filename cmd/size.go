@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// apkInstalledDB is the database of installed packages on Alpine Linux
+const apkInstalledDB = "/lib/apk/db/installed"
+
+// PackageSize is the installed size of a package
+type PackageSize struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// sizeCmd represents the size command
+var sizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "List installed packages by size",
+	Long: `List installed packages sorted by their installed size, largest first, to
+find what to remove when disk space is tight.
+
+For apt-based systems (Debian/Ubuntu):
+  dpkg-query -W -f '${Installed-Size}'
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  rpm -qa --queryformat '%{SIZE}'
+
+For Alpine Linux:
+  the installed size recorded in /lib/apk/db/installed
+
+For Arch Linux:
+  expac '%m' if installed, otherwise pacman -Qi
+
+For Homebrew (macOS):
+  the disk usage of each formula in the Cellar`,
+	Example: `  pkgs size
+  pkgs size --limit 50
+  pkgs size --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		sizes, err := listPackageSizes(pm)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(sizes, func(i, j int) bool { return sizes[i].Bytes > sizes[j].Bytes })
+
+		var total int64
+		for _, s := range sizes {
+			total += s.Bytes
+		}
+		count := len(sizes)
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(sizes) > limit {
+			sizes = sizes[:limit]
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			return printJSON(sizes)
+		}
+
+		defer startPager()()
+
+		rows := make([][]string, 0, len(sizes))
+		for _, s := range sizes {
+			rows = append(rows, []string{formatSize(s.Bytes), s.Name, s.Version})
+		}
+		printTable([]string{"SIZE", "NAME", "VERSION"}, rows)
+		fmt.Printf("\nShowing %d of %d packages, %s installed in total.\n", len(sizes), count, formatSize(total))
+		return nil
+	},
+}
+
+// listPackageSizes returns the installed size of every installed package
+func listPackageSizes(pm *PackageManager) ([]PackageSize, error) {
+	sizes := []PackageSize{}
+	switch pm.Type {
+	case "debian":
+		output, err := runCommandOutput("dpkg-query", "-W", "-f", "${db:Status-Abbrev} ${Package} ${Version} ${Installed-Size}\n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to query package sizes: %w", err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 4 || len(fields[0]) < 2 || fields[0][1] != 'i' {
+				continue
+			}
+			// Installed-Size is in KiB
+			kib, _ := strconv.ParseInt(fields[3], 10, 64)
+			sizes = append(sizes, PackageSize{Name: fields[1], Version: fields[2], Bytes: kib * 1024})
+		}
+	case "redhat":
+		output, err := runCommandOutput("rpm", "-qa", "--qf", "%{NAME} %{VERSION}-%{RELEASE} %{SIZE}\n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to query package sizes: %w", err)
+		}
+		sizes = append(sizes, parseSizeLines(output)...)
+	case "alpine":
+		content, err := readFileContent(apkInstalledDB)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, parseApkInstalledSizes(content)...)
+	case "arch":
+		// expac prints the size in bytes, pacman -Qi only in rounded units
+		if output, err := runCommandOutput("expac", "-Q", "%n %v %m"); err == nil {
+			sizes = append(sizes, parseSizeLines(output)...)
+			break
+		}
+		output, err := runCommandOutput("pacman", "-Qi")
+		if err != nil {
+			return nil, fmt.Errorf("failed to query package sizes: %w", err)
+		}
+		sizes = append(sizes, parsePacmanInfoSizes(output)...)
+	case "macos":
+		cellar, err := runCommandOutput("brew", "--cellar")
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate the Homebrew Cellar: %v", err)
+		}
+		installed, err := listInstalled(pm)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range installed {
+			dir := filepath.Join(strings.TrimSpace(cellar), p.Name, p.Version)
+			// Casks are not kept in the Cellar
+			if _, err := os.Stat(dir); err == nil {
+				sizes = append(sizes, PackageSize{Name: p.Name, Version: p.Version, Bytes: dirSize(dir)})
+			}
+		}
+	default:
+		return nil, unsupportedError("listing package sizes is not supported for package manager '%s'", pm.Name)
+	}
+	return sizes, nil
+}
+
+// parseSizeLines parses "name version bytes" lines
+func parseSizeLines(output string) []PackageSize {
+	var sizes []PackageSize
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		bytes, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes = append(sizes, PackageSize{Name: fields[0], Version: fields[1], Bytes: bytes})
+	}
+	return sizes
+}
+
+// parseApkInstalledSizes reads the name (P:), version (V:) and installed size
+// in bytes (I:) of each package block of the apk database
+func parseApkInstalledSizes(content string) []PackageSize {
+	var sizes []PackageSize
+	var current PackageSize
+	for _, line := range strings.Split(content+"\n", "\n") {
+		key, value, _ := strings.Cut(line, ":")
+		switch key {
+		case "P":
+			current.Name = value
+		case "V":
+			current.Version = value
+		case "I":
+			current.Bytes, _ = strconv.ParseInt(value, 10, 64)
+		case "":
+			// Packages are separated by blank lines
+			if current.Name != "" {
+				sizes = append(sizes, current)
+			}
+			current = PackageSize{}
+		}
+	}
+	return sizes
+}
+
+// parsePacmanInfoSizes parses the Name, Version and Installed Size fields of "pacman -Qi"
+func parsePacmanInfoSizes(output string) []PackageSize {
+	units := map[string]float64{"B": 1, "KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30}
+
+	var sizes []PackageSize
+	var current PackageSize
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Name":
+			current = PackageSize{Name: value}
+		case "Version":
+			current.Version = value
+		case "Installed Size":
+			// Output: "Installed Size  : 1.23 MiB"
+			fields := strings.Fields(value)
+			if len(fields) == 2 {
+				n, _ := strconv.ParseFloat(fields[0], 64)
+				current.Bytes = int64(n * units[fields[1]])
+			}
+			sizes = append(sizes, current)
+		}
+	}
+	return sizes
+}
+
+func init() {
+	rootCmd.AddCommand(sizeCmd)
+
+	sizeCmd.Flags().Int("limit", 20, "Number of packages to show (0 shows all)")
+	sizeCmd.Flags().Bool("json", false, "Output results as JSON")
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// PackageStats summarizes the package state of the system
+type PackageStats struct {
+	Backend      string     `json:"backend"`
+	Installed    int        `json:"installed"`
+	Explicit     *int       `json:"explicit,omitempty"`
+	Orphans      *int       `json:"orphans,omitempty"`
+	CacheDir     string     `json:"cache_dir,omitempty"`
+	CacheBytes   int64      `json:"cache_bytes"`
+	Repos        int        `json:"repos"`
+	EnabledRepos int        `json:"enabled_repos"`
+	LastUpdate   *time.Time `json:"last_update,omitempty"`
+}
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show package counts, cache size and repository statistics",
+	Long: `Show a short summary of the package state, useful to compare machines of a
+fleet at a glance:
+
+  - installed packages, and how many were installed explicitly
+  - orphaned packages (see 'pkgs orphans')
+  - size of the package cache on disk
+  - configured and enabled repositories
+  - time since the package lists were last refreshed
+
+Values that cannot be determined are shown as "unknown" and left out of the
+JSON output.`,
+	Example: `  pkgs stats
+  pkgs stats --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		stats, err := collectStats(pm)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			return printJSON(stats)
+		}
+
+		countOrUnknown := func(n *int) string {
+			if n == nil {
+				return "unknown"
+			}
+			return strconv.Itoa(*n)
+		}
+		lastUpdate := "unknown"
+		if stats.LastUpdate != nil {
+			lastUpdate = fmt.Sprintf("%s (%s ago)", stats.LastUpdate.Format("2006-01-02 15:04"), formatAge(time.Since(*stats.LastUpdate)))
+		}
+		cache := formatSize(stats.CacheBytes)
+		if stats.CacheDir != "" {
+			cache = fmt.Sprintf("%s (%s)", cache, stats.CacheDir)
+		}
+
+		printTable([]string{"BACKEND", stats.Backend}, [][]string{
+			{"Installed packages", strconv.Itoa(stats.Installed)},
+			{"Explicitly installed", countOrUnknown(stats.Explicit)},
+			{"Orphaned packages", countOrUnknown(stats.Orphans)},
+			{"Package cache", cache},
+			{"Repositories", fmt.Sprintf("%d (%d enabled)", stats.Repos, stats.EnabledRepos)},
+			{"Last metadata update", lastUpdate},
+		})
+		return nil
+	},
+}
+
+// collectStats gathers the statistics; only a failure to list the installed
+// packages is fatal, other values are left unknown
+func collectStats(pm *PackageManager) (PackageStats, error) {
+	stats := PackageStats{Backend: pm.Name}
+
+	installed, err := listInstalled(pm)
+	if err != nil {
+		return stats, err
+	}
+	stats.Installed = len(installed)
+
+	if explicit, err := explicitPackages(pm); err == nil {
+		count := len(explicit)
+		stats.Explicit = &count
+	}
+	if orphans, err := listOrphans(pm); err == nil {
+		count := len(orphans)
+		stats.Orphans = &count
+	}
+
+	stats.CacheDir = packageCacheDir(pm)
+	if stats.CacheDir != "" {
+		stats.CacheBytes = dirSize(stats.CacheDir)
+	}
+
+	repos, _ := listRepos(pm)
+	stats.Repos = len(repos)
+	for _, r := range repos {
+		if r.Enabled {
+			stats.EnabledRepos++
+		}
+	}
+
+	if last := lastMetadataUpdate(pm); !last.IsZero() {
+		stats.LastUpdate = &last
+	}
+	return stats, nil
+}
+
+// packageCacheDir returns the directory where downloaded packages are kept
+func packageCacheDir(pm *PackageManager) string {
+	switch pm.Name {
+	case "apt", "apt-get":
+		return "/var/cache/apt/archives"
+	case "dnf":
+		if isDir("/var/cache/libdnf5") {
+			return "/var/cache/libdnf5"
+		}
+		return "/var/cache/dnf"
+	case "yum":
+		return "/var/cache/yum"
+	case "apk":
+		return "/var/cache/apk"
+	case "pacman":
+		return pacmanCacheDir
+	case "brew":
+		output, err := runCommandOutput("brew", "--cache")
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(output)
+	default:
+		return ""
+	}
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// dirSize returns the total size of the regular files below a directory,
+// skipping entries that cannot be read
+func dirSize(root string) int64 {
+	var total int64
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().Bool("json", false, "Output results as JSON")
+}
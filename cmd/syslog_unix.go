@@ -0,0 +1,18 @@
+//go:build !windows
+
+package cmd
+
+import "log/syslog"
+
+// sendSyslog sends a message to the local syslog daemon
+func sendSyslog(priority int, message string) error {
+	writer, err := syslog.New(syslog.Priority(priority)|syslog.LOG_USER, "pkgs")
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	if priority == priorityWarning {
+		return writer.Warning(message)
+	}
+	return writer.Info(message)
+}
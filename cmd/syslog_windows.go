@@ -0,0 +1,6 @@
+//go:build windows
+
+package cmd
+
+// sendSyslog does nothing on Windows, which has no syslog daemon
+func sendSyslog(priority int, message string) error { return nil }
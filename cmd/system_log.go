@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// journaldSocket receives entries in the native journal protocol
+const journaldSocket = "/run/systemd/journal/socket"
+
+// maxLoggedDiff bounds the diff sent to the system log, which takes entries
+// in single datagrams
+const maxLoggedDiff = 32 << 10
+
+// Syslog priorities of the entries
+const (
+	priorityWarning = 4
+	priorityInfo    = 6
+)
+
+// detectedBackend is the package manager pkgs drives, for the system log and
+// the plans shown before changes
+var detectedBackend string
+
+// sendSystemLog sends an entry of the transaction log to journald or syslog,
+// as configured with system-log
+func sendSystemLog(target string, entry TransactionLogEntry) {
+	// The first line of history.log, without the time the system log adds
+	summary := entry
+	summary.Diff = nil
+	_, message, _ := strings.Cut(strings.TrimSuffix(formatLogEntry(summary), "\n"), " ")
+	priority := priorityInfo
+	if entry.ExitCode != nil && *entry.ExitCode != 0 {
+		priority = priorityWarning
+	}
+
+	switch target {
+	case "journald":
+		fields := [][2]string{
+			{"MESSAGE", message},
+			{"PRIORITY", strconv.Itoa(priority)},
+			{"SYSLOG_IDENTIFIER", "pkgs"},
+			{"PKGS_COMMAND", strings.Join(entry.Args, " ")},
+			{"PKGS_BACKEND", detectedBackend},
+			{"PKGS_USER", entry.User},
+			{"PKGS_ACTION", entry.Action},
+		}
+		if entry.Command != nil {
+			fields = append(fields, [2]string{"PKGS_NATIVE_COMMAND", strings.Join(entry.Command, " ")})
+			fields = append(fields, [2]string{"PKGS_EXIT", strconv.Itoa(*entry.ExitCode)})
+		}
+		if entry.File != "" {
+			fields = append(fields, [2]string{"PKGS_FILE", entry.File})
+		}
+		if entry.NewFile != "" {
+			fields = append(fields, [2]string{"PKGS_NEW_FILE", entry.NewFile})
+		}
+		if diff := strings.Join(entry.Diff, "\n"); diff != "" {
+			if len(diff) > maxLoggedDiff {
+				diff = diff[:maxLoggedDiff] + "\n(truncated)"
+			}
+			fields = append(fields, [2]string{"PKGS_DIFF", diff})
+		}
+		sendJournal(fields)
+	case "syslog":
+		sendSyslog(priority, message)
+	}
+}
+
+// sendJournal sends fields to journald in its native protocol. Values with
+// newlines are sent with their length, the others as NAME=value.
+func sendJournal(fields [][2]string) error {
+	var data bytes.Buffer
+	for _, field := range fields {
+		name, value := field[0], field[1]
+		if !strings.Contains(value, "\n") {
+			fmt.Fprintf(&data, "%s=%s\n", name, value)
+			continue
+		}
+		data.WriteString(name + "\n")
+		binary.Write(&data, binary.LittleEndian, uint64(len(value)))
+		data.WriteString(value + "\n")
+	}
+
+	conn, err := net.Dial("unixgram", journaldSocket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write(data.Bytes())
+	return err
+}
@@ -8,10 +8,13 @@ import (
 
 // ANSI color codes
 const (
-	colorReset  = "\033[0m"
-	colorGreen  = "\033[32m"
-	colorYellow = "\033[33m"
-	colorGrey   = "\033[37m"
+	colorReset   = "\033[0m"
+	colorGreen   = "\033[32m"
+	colorYellow  = "\033[33m"
+	colorBlue    = "\033[34m"
+	colorMagenta = "\033[35m"
+	colorCyan    = "\033[36m"
+	colorGrey    = "\033[37m"
 )
 
 // isTerminal checks if file descriptor is a terminal
@@ -19,9 +22,9 @@ func isTerminal(fd uintptr) bool {
 	return term.IsTerminal(int(fd))
 }
 
-// colorize returns text with color if output is to a terminal, otherwise plain text
+// colorize returns text with color if output is to a terminal or pager, otherwise plain text
 func colorize(text string, color string) string {
-	if isTerminal(os.Stdout.Fd()) {
+	if pagerActive || isTerminal(os.Stdout.Fd()) {
 		return color + text + colorReset
 	}
 	return text
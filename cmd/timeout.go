@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// timeoutFlag bounds the time a pkgs command may take, see startTimeout
+var timeoutFlag time.Duration
+
+// runContext is cancelled when the timeout of the command expires. Native
+// commands are started with it, so that a hung mirror or a dpkg prompt
+// nobody answers cannot block a CI job forever.
+var (
+	runContext = context.Background()
+	runTimeout time.Duration
+	cancelRun  context.CancelFunc = func() {}
+)
+
+// startTimeout starts the timeout given with --timeout, or the default of
+// the configuration. --timeout 0 disables the default.
+func startTimeout(cmd *cobra.Command, config *Config) {
+	runTimeout = config.Timeout
+	if cmd.Flags().Changed("timeout") {
+		runTimeout = timeoutFlag
+	}
+	if runTimeout > 0 {
+		runContext, cancelRun = context.WithTimeout(context.Background(), runTimeout)
+	}
+}
+
+// commandWithTimeout creates a command that is killed when the timeout
+// expires. Without a terminal it runs in its own process group, which is
+// killed as a whole, including the maintainer scripts it started; on a
+// terminal it must stay in the foreground group to prompt.
+func commandWithTimeout(name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(runContext, name, args...)
+	if runTimeout > 0 {
+		cmd.WaitDelay = 5 * time.Second
+		if !isTerminal(os.Stdin.Fd()) {
+			killProcessGroup(cmd)
+		}
+	}
+	return cmd
+}
+
+// timeoutError reports that the command was stopped by the timeout
+func timeoutError(err error) error {
+	if err == nil || !errors.Is(runContext.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return withExitCode(ExitTimeout, fmt.Errorf("timed out after %s: %v", runTimeout, err))
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup starts a command in its own process group and kills the
+// whole group when its context is done
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// ownProcessGroup reports whether a command was started in its own process
+// group
+func ownProcessGroup(cmd *exec.Cmd) bool {
+	return cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid
+}
+
+// signalCommand sends a signal to a command, to its whole process group if
+// it has its own
+func signalCommand(cmd *exec.Cmd, sig os.Signal) {
+	if s, ok := sig.(syscall.Signal); ok && ownProcessGroup(cmd) {
+		syscall.Kill(-cmd.Process.Pid, s)
+		return
+	}
+	cmd.Process.Signal(sig)
+}
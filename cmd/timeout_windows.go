@@ -0,0 +1,20 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// killProcessGroup leaves the default of killing the process on Windows,
+// which has no process groups to kill
+func killProcessGroup(cmd *exec.Cmd) {}
+
+// ownProcessGroup is always false on Windows
+func ownProcessGroup(cmd *exec.Cmd) bool { return false }
+
+// signalCommand sends a signal to a command
+func signalCommand(cmd *exec.Cmd, sig os.Signal) {
+	cmd.Process.Signal(sig)
+}
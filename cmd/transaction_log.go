@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// transactionLogDir holds the log of everything pkgs changed on the system
+const transactionLogDir = "/var/log/pkgs"
+
+// maxDiffLines bounds the size of the files diffed in the log
+const maxDiffLines = 2000
+
+// TransactionLogEntry is one change recorded in history.jsonl
+type TransactionLogEntry struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Args     []string  `json:"args"`
+	Action   string    `json:"action"`
+	Command  []string  `json:"command,omitempty"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+	File     string    `json:"file,omitempty"`
+	NewFile  string    `json:"new_file,omitempty"`
+	Diff     []string  `json:"diff,omitempty"`
+}
+
+var transactionLogMu sync.Mutex
+
+// changingCommand is set for the commands that change the system, whose
+// operations are logged
+var changingCommand bool
+
+// logTransaction appends an entry to history.log and history.jsonl, for the
+// commands that change the system, and sends it to the system log if one is
+// configured. Only root can write the log files, so changes made as a user
+// (Homebrew, --user) only reach the system log. Failures to log never fail
+// the operation.
+func logTransaction(entry TransactionLogEntry) {
+	if !changingCommand {
+		return
+	}
+	entry.Time = time.Now().UTC()
+	entry.User = invokingUserName()
+	entry.Args = os.Args[1:]
+
+	if config, err := loadConfig(); err == nil && config.SystemLog != "" {
+		sendSystemLog(config.SystemLog, entry)
+	}
+	if os.Geteuid() != 0 {
+		return
+	}
+
+	transactionLogMu.Lock()
+	defer transactionLogMu.Unlock()
+	if err := os.MkdirAll(transactionLogDir, 0750); err != nil {
+		return
+	}
+	appendLog(filepath.Join(transactionLogDir, "history.log"), formatLogEntry(entry))
+	if data, err := json.Marshal(entry); err == nil {
+		appendLog(filepath.Join(transactionLogDir, "history.jsonl"), string(data)+"\n")
+	}
+}
+
+// appendLog appends text to a log file
+func appendLog(path, text string) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	file.WriteString(text)
+}
+
+// formatLogEntry formats an entry for history.log, with the diff indented
+// below the line describing the change
+func formatLogEntry(entry TransactionLogEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s user=%s pkgs=%q %s", entry.Time.Format(time.RFC3339), entry.User, strings.Join(entry.Args, " "), entry.Action)
+	switch {
+	case entry.Command != nil:
+		fmt.Fprintf(&b, " %q exit=%d", strings.Join(entry.Command, " "), *entry.ExitCode)
+	case entry.NewFile != "":
+		fmt.Fprintf(&b, " %s -> %s", entry.File, entry.NewFile)
+	default:
+		fmt.Fprintf(&b, " %s", entry.File)
+	}
+	b.WriteString("\n")
+	for _, line := range entry.Diff {
+		fmt.Fprintf(&b, "    %s\n", line)
+	}
+	return b.String()
+}
+
+// logCommand records a native command that changes the system
+func logCommand(cmd *exec.Cmd, err error) {
+	code := 0
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		code = exitErr.ExitCode()
+	case err != nil:
+		code = -1
+	}
+	logTransaction(TransactionLogEntry{Action: "run", Command: cmd.Args, ExitCode: &code})
+}
+
+// logFileChange records a file written or removed, with the diff of its
+// content
+func logFileChange(action, path, oldContent, newContent string) {
+	logTransaction(TransactionLogEntry{Action: action, File: path, Diff: contentDiff(oldContent, newContent)})
+}
+
+// invokingUserName returns the name of the user who ran pkgs, before
+// sudo, run0 or pkexec
+func invokingUserName() string {
+	if name := os.Getenv("SUDO_USER"); name != "" {
+		return name
+	}
+	if uid := os.Getenv("PKEXEC_UID"); uid != "" {
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return fmt.Sprint(os.Getuid())
+}
+
+// contentDiff returns the lines removed ("-") and added ("+") between two
+// versions of a file, in order
+func contentDiff(oldContent, newContent string) []string {
+	for _, content := range []string{oldContent, newContent} {
+		if !utf8.ValidString(content) || strings.ContainsRune(content, 0) {
+			return []string{fmt.Sprintf("binary content, %d -> %d bytes", len(oldContent), len(newContent))}
+		}
+	}
+	oldLines, newLines := splitLines(oldContent), splitLines(newContent)
+	if len(oldLines) > maxDiffLines || len(newLines) > maxDiffLines {
+		return []string{fmt.Sprintf("%d -> %d lines, too large to diff", len(oldLines), len(newLines))}
+	}
+
+	// Longest common subsequence of the lines, from the end
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			i++
+			j++
+		case j < len(newLines) && (i == len(oldLines) || lcs[i][j+1] >= lcs[i+1][j]):
+			diff = append(diff, "+"+newLines[j])
+			j++
+		default:
+			diff = append(diff, "-"+oldLines[i])
+			i++
+		}
+	}
+	return diff
+}
+
+// splitLines splits content into lines, without a last empty line
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
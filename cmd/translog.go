@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// packageChange is a single package operation recorded in a native log
+type packageChange struct {
+	Action     string `json:"action"`
+	Package    string `json:"package"`
+	Version    string `json:"version,omitempty"`
+	OldVersion string `json:"old_version,omitempty"`
+}
+
+// transaction groups the package changes made by one native command
+type transaction struct {
+	ID      int             `json:"id"`
+	Time    time.Time       `json:"time"`
+	Command string          `json:"command,omitempty"`
+	Changes []packageChange `json:"changes"`
+}
+
+// readTransactions reads the native transaction log of a package manager and
+// returns its transactions in chronological order with sequential IDs
+func readTransactions(pm *PackageManager) ([]transaction, error) {
+	var txs []transaction
+	var err error
+
+	switch pm.Type {
+	case "debian":
+		txs, err = readAptHistory()
+	case "redhat":
+		if pm.Name == "yum" {
+			txs, err = readYumLog()
+		} else {
+			txs, err = readDnfRpmLog()
+		}
+	case "arch":
+		txs, err = readPacmanLog()
+	default:
+		return nil, fmt.Errorf("%s does not keep a transaction log that pkgs can read", pm.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Runs that did not change any package (e.g. refreshing the package lists) are dropped
+	changed := txs[:0]
+	for _, tx := range txs {
+		if len(tx.Changes) > 0 {
+			changed = append(changed, tx)
+		}
+	}
+	txs = changed
+
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].Time.Before(txs[j].Time)
+	})
+	for i := range txs {
+		txs[i].ID = i + 1
+	}
+	return txs, nil
+}
+
+// readLogFile reads a log file, transparently decompressing rotated .gz files
+func readLogFile(path string) (string, error) {
+	f, err := os.Open(systemPath(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to open log %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress log %s: %v", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log %s: %v", path, err)
+	}
+	return string(data), nil
+}
+
+// aptPackagePattern matches entries like "nginx:amd64 (1.18.0-6, automatic)"
+var aptPackagePattern = regexp.MustCompile(`([^\s,]+) \(([^)]*)\)`)
+
+// readAptHistory parses /var/log/apt/history.log and its rotated copies
+func readAptHistory() ([]transaction, error) {
+	files, _ := filepath.Glob(systemPath("/var/log/apt/history.log*"))
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no apt history found in /var/log/apt")
+	}
+
+	var txs []transaction
+	for _, file := range files {
+		content, err := readLogFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var current *transaction
+		for _, line := range strings.Split(content, "\n") {
+			key, value, found := strings.Cut(line, ": ")
+			if !found {
+				continue
+			}
+
+			switch key {
+			case "Start-Date":
+				t, err := time.ParseInLocation("2006-01-02 15:04:05", strings.Join(strings.Fields(value), " "), time.Local)
+				if err != nil {
+					current = nil
+					continue
+				}
+				txs = append(txs, transaction{Time: t})
+				current = &txs[len(txs)-1]
+			case "Commandline":
+				if current != nil {
+					current.Command = value
+				}
+			case "Install", "Upgrade", "Downgrade", "Reinstall", "Remove", "Purge":
+				if current == nil {
+					continue
+				}
+				for _, match := range aptPackagePattern.FindAllStringSubmatch(value, -1) {
+					name, _, _ := strings.Cut(match[1], ":")
+					versions := strings.Split(match[2], ", ")
+					change := packageChange{Action: strings.ToLower(key), Package: name, Version: versions[0]}
+					if key == "Purge" {
+						change.Action = "remove"
+					}
+					if (key == "Upgrade" || key == "Downgrade") && len(versions) > 1 {
+						change.OldVersion, change.Version = versions[0], versions[1]
+					}
+					current.Changes = append(current.Changes, change)
+				}
+			}
+		}
+	}
+	return txs, nil
+}
+
+// splitRpmNEVRA splits "name-[epoch:]version-release.arch" into name and version-release
+func splitRpmNEVRA(s string) (string, string) {
+	if i := strings.LastIndex(s, "."); i > 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 {
+		return s, ""
+	}
+	return strings.Join(parts[:len(parts)-2], "-"), strings.Join(parts[len(parts)-2:], "-")
+}
+
+// readDnfRpmLog parses /var/log/dnf.rpm.log, where every dnf run starts with
+// a "logging initialized" marker
+func readDnfRpmLog() ([]transaction, error) {
+	files, _ := filepath.Glob(systemPath("/var/log/dnf.rpm.log*"))
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no dnf history found in /var/log/dnf.rpm.log")
+	}
+
+	actions := map[string]string{
+		"Installed":   "install",
+		"Upgrade":     "upgrade",
+		"Downgrade":   "downgrade",
+		"Reinstall":   "reinstall",
+		"Erase":       "remove",
+		"Upgraded":    "",
+		"Downgraded":  "",
+		"Reinstalled": "",
+	}
+
+	var txs []transaction
+	for _, file := range files {
+		content, err := readLogFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var current *transaction
+		for _, line := range strings.Split(content, "\n") {
+			fields := strings.SplitN(line, " ", 3)
+			if len(fields) < 3 {
+				continue
+			}
+			t, err := parseLogTime(fields[0])
+			if err != nil {
+				continue
+			}
+
+			if strings.Contains(fields[2], "logging initialized") {
+				current = nil
+				continue
+			}
+
+			key, value, found := strings.Cut(fields[2], ": ")
+			action, known := actions[key]
+			if !found || !known {
+				continue
+			}
+
+			if current == nil {
+				txs = append(txs, transaction{Time: t})
+				current = &txs[len(txs)-1]
+			}
+
+			name, version := splitRpmNEVRA(strings.TrimSpace(value))
+			if action == "" {
+				// The outgoing side of an upgrade/downgrade carries the old version
+				for i := range current.Changes {
+					if current.Changes[i].Package == name && current.Changes[i].OldVersion == "" &&
+						(current.Changes[i].Action == "upgrade" || current.Changes[i].Action == "downgrade") {
+						current.Changes[i].OldVersion = version
+						break
+					}
+				}
+				continue
+			}
+			current.Changes = append(current.Changes, packageChange{Action: action, Package: name, Version: version})
+		}
+	}
+	return txs, nil
+}
+
+// readYumLog parses /var/log/yum.log, which has no year or transaction markers,
+// so entries less than a minute apart are grouped together
+func readYumLog() ([]transaction, error) {
+	files, _ := filepath.Glob(systemPath("/var/log/yum.log*"))
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no yum history found in /var/log/yum.log")
+	}
+
+	actions := map[string]string{
+		"Installed": "install",
+		"Updated":   "upgrade",
+		"Erased":    "remove",
+	}
+
+	var txs []transaction
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		year := info.ModTime().Year()
+
+		content, err := readLogFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var current *transaction
+		for _, line := range strings.Split(content, "\n") {
+			if len(line) < 16 {
+				continue
+			}
+			t, err := time.ParseInLocation("2006 Jan 02 15:04:05", fmt.Sprintf("%d %s", year, line[:15]), time.Local)
+			if err != nil {
+				continue
+			}
+
+			key, value, found := strings.Cut(strings.TrimSpace(line[15:]), ": ")
+			action, known := actions[key]
+			if !found || !known {
+				continue
+			}
+
+			if current == nil || t.Sub(current.Time) > time.Minute {
+				txs = append(txs, transaction{Time: t})
+				current = &txs[len(txs)-1]
+			}
+
+			name, version := splitRpmNEVRA(strings.TrimSpace(value))
+			if action == "remove" && version == "" {
+				name = strings.TrimSpace(value)
+			}
+			current.Changes = append(current.Changes, packageChange{Action: action, Package: name, Version: version})
+		}
+	}
+	return txs, nil
+}
+
+// pacmanLogPattern matches "[timestamp] [SOURCE] message" lines of pacman.log
+var pacmanLogPattern = regexp.MustCompile(`^\[([^\]]+)\] \[([A-Z-]+)\] (.*)$`)
+
+// pacmanChangePattern matches ALPM package operations like "upgraded foo (1.0-1 -> 1.1-1)"
+var pacmanChangePattern = regexp.MustCompile(`^(installed|upgraded|downgraded|reinstalled|removed) (\S+) \((.*)\)$`)
+
+// readPacmanLog parses /var/log/pacman.log
+func readPacmanLog() ([]transaction, error) {
+	content, err := readLogFile("/var/log/pacman.log")
+	if err != nil {
+		return nil, err
+	}
+
+	actions := map[string]string{
+		"installed":   "install",
+		"upgraded":    "upgrade",
+		"downgraded":  "downgrade",
+		"reinstalled": "reinstall",
+		"removed":     "remove",
+	}
+
+	var txs []transaction
+	var current *transaction
+	for _, line := range strings.Split(content, "\n") {
+		match := pacmanLogPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		t, err := parseLogTime(match[1])
+		if err != nil {
+			continue
+		}
+
+		// Each pacman invocation starts a new transaction
+		if match[2] == "PACMAN" && strings.HasPrefix(match[3], "Running '") {
+			txs = append(txs, transaction{Time: t, Command: strings.TrimSuffix(strings.TrimPrefix(match[3], "Running '"), "'")})
+			current = &txs[len(txs)-1]
+			continue
+		}
+
+		if match[2] != "ALPM" {
+			continue
+		}
+		change := pacmanChangePattern.FindStringSubmatch(match[3])
+		if change == nil {
+			continue
+		}
+
+		if current == nil {
+			txs = append(txs, transaction{Time: t})
+			current = &txs[len(txs)-1]
+		}
+
+		pc := packageChange{Action: actions[change[1]], Package: change[2], Version: change[3]}
+		if oldVersion, newVersion, found := strings.Cut(change[3], " -> "); found {
+			pc.OldVersion, pc.Version = oldVersion, newVersion
+		}
+		current.Changes = append(current.Changes, pc)
+	}
+	return txs, nil
+}
+
+// parseLogTime parses the timestamp formats used by dnf and pacman logs
+func parseLogTime(s string) (time.Time, error) {
+	layouts := []string{
+		"2006-01-02T15:04:05-0700",
+		"2006-01-02T15:04:05Z07:00",
+		"2006-01-02T15:04:05Z",
+		"2006-01-02 15:04",
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo id",
+	Short: "Roll back a transaction from the history",
+	Long: `Roll back a transaction shown by 'pkgs history': installed packages are
+removed, removed packages are installed again and upgrades or downgrades are
+reverted to the previous version. The plan is shown before anything changes;
+use --dry-run to only show it.
+
+For apt-based systems (Debian/Ubuntu):
+  apt install --allow-downgrades with the reconstructed package versions
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf history undo with the matching dnf transaction
+
+For Arch Linux:
+  pacman -U with the previous versions from /var/cache/pacman/pkg, and
+  pacman -R for packages that were installed
+
+Older versions must still be available in the repositories (apt) or in the
+package cache (pacman) for the rollback to succeed.`,
+	Example: `  pkgs undo 42 --dry-run
+  pkgs undo 42`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		txs, err := readTransactions(pm)
+		if err != nil {
+			return err
+		}
+		tx, err := findTransaction(txs, args[0])
+		if err != nil {
+			return err
+		}
+
+		plan := undoPlan(tx)
+		if len(plan) == 0 {
+			fmt.Printf("Transaction %d has nothing to undo.\n", tx.ID)
+			return nil
+		}
+
+		fmt.Printf("Undoing transaction %d from %s will make these changes:\n\n", tx.ID, tx.Time.Format("2006-01-02 15:04"))
+		rows := make([][]string, 0, len(plan))
+		for _, c := range plan {
+			rows = append(rows, []string{c.Action, c.Package, c.Version})
+		}
+		printTable([]string{"ACTION", "PACKAGE", "VERSION"}, rows)
+		fmt.Println()
+
+		if IsDryRun() {
+			return nil
+		}
+		if !askForConfirmation("Do you want to continue?") {
+			return errCancelled
+		}
+
+		switch pm.Type {
+		case "debian":
+			return undoApt(pm, plan)
+		case "redhat":
+			return undoDnfYum(pm, tx)
+		case "arch":
+			return undoPacman(pm, plan)
+		default:
+			return unsupportedError("undoing transactions is not supported for package manager '%s'", pm.Name)
+		}
+	},
+}
+
+// undoPlan returns the changes that revert a transaction. Each change states
+// the action to take and the version to end up with.
+func undoPlan(tx transaction) []packageChange {
+	var plan []packageChange
+	for _, c := range tx.Changes {
+		switch c.Action {
+		case "install":
+			plan = append(plan, packageChange{Action: "remove", Package: c.Package, Version: c.Version})
+		case "remove":
+			plan = append(plan, packageChange{Action: "install", Package: c.Package, Version: c.Version})
+		case "upgrade":
+			plan = append(plan, packageChange{Action: "downgrade", Package: c.Package, Version: c.OldVersion})
+		case "downgrade":
+			plan = append(plan, packageChange{Action: "upgrade", Package: c.Package, Version: c.OldVersion})
+		}
+	}
+	return plan
+}
+
+// undoApt applies the plan with a single apt install, which accepts
+// "pkg-" for removals and "pkg=version" for specific versions
+func undoApt(pm *PackageManager, plan []packageChange) error {
+	fullCmd := []string{"install", "--allow-downgrades"}
+	addYesFlagIfNeeded(pm, &fullCmd)
+	for _, c := range plan {
+		if c.Action == "remove" {
+			fullCmd = append(fullCmd, c.Package+"-")
+		} else {
+			fullCmd = append(fullCmd, c.Package+"="+c.Version)
+		}
+	}
+	return executeNative(pm.Bin, fullCmd...)
+}
+
+// undoDnfYum runs "history undo" for the native transaction recorded at the same time
+func undoDnfYum(pm *PackageManager, tx transaction) error {
+	id, err := nativeHistoryID(pm, tx.Time)
+	if err != nil {
+		return err
+	}
+	fullCmd := []string{"history", "undo"}
+	addYesFlagIfNeeded(pm, &fullCmd)
+	return executeNative(pm.Bin, append(fullCmd, id)...)
+}
+
+// nativeHistoryID finds the dnf/yum history ID of the transaction closest to t.
+// The history list only has minute precision, so up to two minutes of
+// difference are accepted.
+func nativeHistoryID(pm *PackageManager, t time.Time) (string, error) {
+	// Output: "    42 | install nginx  | 2024-06-01 14:03 | Install  |    3"
+	output, err := runCommandOutput(pm.Bin, "-q", "history", "list")
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s history: %v", pm.Name, err)
+	}
+
+	bestID, bestDiff := "", math.MaxFloat64
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) < 3 {
+			continue
+		}
+		when, err := time.ParseInLocation("2006-01-02 15:04", strings.TrimSpace(fields[2]), time.Local)
+		if err != nil {
+			continue
+		}
+		if diff := math.Abs(t.Sub(when).Minutes()); diff < 2 && diff < bestDiff {
+			bestID, bestDiff = strings.TrimSpace(fields[0]), diff
+		}
+	}
+	if bestID == "" {
+		return "", fmt.Errorf("no %s history entry matches the transaction from %s", pm.Name, t.Format("2006-01-02 15:04:05"))
+	}
+	return bestID, nil
+}
+
+// undoPacman removes installed packages and installs previous versions from
+// the package cache
+func undoPacman(pm *PackageManager, plan []packageChange) error {
+	var removals, files, missing []string
+	for _, c := range plan {
+		if c.Action == "remove" {
+			removals = append(removals, c.Package)
+			continue
+		}
+		file := ""
+		for _, cached := range pacmanCachedPackages(c.Package) {
+			if pacmanFileVersion(c.Package, cached) == c.Version {
+				file = cached
+				break
+			}
+		}
+		if file == "" {
+			missing = append(missing, c.Package+" "+c.Version)
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("these versions are no longer in %s: %s", pacmanCacheDir, strings.Join(missing, ", "))
+	}
+
+	var err error
+	if len(files) > 0 {
+		fullCmd := []string{"-U"}
+		addYesFlagIfNeeded(pm, &fullCmd)
+		err = executeNative("pacman", append(fullCmd, files...)...)
+	}
+	if err == nil && len(removals) > 0 {
+		fullCmd := []string{"-R"}
+		addYesFlagIfNeeded(pm, &fullCmd)
+		err = executeNative("pacman", append(fullCmd, removals...)...)
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// unholdCmd represents the unhold command
+var unholdCmd = &cobra.Command{
+	Use:     "unhold [packages...]",
+	Aliases: []string{"unpin", "unlock"},
+	Short:   "Allow held packages to be upgraded again",
+	Long: `Release packages that were held with 'pkgs hold', so that upgrades include
+them again. Use 'pkgs hold --list' to see which packages are held.
+
+For apt-based systems (Debian/Ubuntu):
+  apt-mark unhold package
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf versionlock delete package
+
+For Alpine Linux:
+  apk add package, which drops the version constraint from /etc/apk/world
+
+For Arch Linux:
+  Removes the package from IgnorePkg in /etc/pacman.conf
+
+For Homebrew (macOS):
+  brew unpin package`,
+	Example: `  pkgs unhold nginx
+  pkgs unhold linux linux-headers`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		step, err := holdStep(pm, args, false)
+		if err != nil {
+			return err
+		}
+		if err := confirmPlan(step); err != nil {
+			return err
+		}
+		return unholdPackages(pm, args)
+	},
+}
+
+// unholdPackages releases held packages with the native mechanism
+func unholdPackages(pm *PackageManager, names []string) error {
+	switch pm.Type {
+	case "debian":
+		return executeNative("apt-mark", append([]string{"unhold"}, names...)...)
+	case "redhat":
+		return versionlock(pm, "delete", names)
+	case "alpine":
+		return executeNative("apk", append([]string{"add"}, names...)...)
+	case "arch":
+		return setPacmanIgnored(names, false)
+	case "macos":
+		return executeNative("brew", append([]string{"unpin"}, names...)...)
+	default:
+		return unsupportedError("holding packages is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(unholdCmd)
+}
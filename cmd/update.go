@@ -13,17 +13,14 @@ var updateCmd = &cobra.Command{
 	Short:   "Update package lists",
 	Long:    `Update the package lists from repositories using the native package manager.`,
 	Example: `  pkgs update`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
 		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "update", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
-		}
+		return ExecuteCommand(pm, "update", args)
 	},
 }
 
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -11,22 +13,206 @@ var upgradeCmd = &cobra.Command{
 	Use:     "upgrade",
 	Aliases: []string{"ug", "u"},
 	Short:   "Upgrade installed packages",
-	Long:    `Upgrade all installed packages to their latest versions using the native package manager.`,
-	Example: `  pkgs upgrade`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Upgrade all installed packages to their latest versions using the native package manager.
+
+With --security only security fixes are applied, which suits scheduled runs
+on servers:
+
+For apt-based systems (Debian/Ubuntu):
+  unattended-upgrade if it is installed, otherwise the upgradable packages
+  whose candidate comes from a "-security" suite
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf upgrade --security
+
+apk, pacman and Homebrew do not publish security metadata for packages.
+
+--exclude skips packages matching a shell pattern (repeatable) in this run:
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf upgrade --exclude=pattern
+
+For Arch Linux:
+  pacman -Syu --ignore, the command-line form of IgnorePkg
+
+For apt, apk and Homebrew the matching packages are held for the duration of
+the upgrade and released afterwards; packages that were already held stay held.
+
+Ubuntu phases updates out to a random share of machines at first. For apt,
+--include-phased takes phased updates right away and --exclude-phased holds
+them back until they are fully rolled out, so that a fleet is upgraded
+uniformly (APT::Get::Always-Include-Phased-Updates and
+APT::Get::Never-Include-Phased-Updates).`,
+	Example: `  pkgs upgrade
+  pkgs upgrade --security -y
+  pkgs upgrade --exclude 'kernel*' --exclude docker-ce
+  pkgs upgrade --exclude-phased -y`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("Error: No supported package manager detected on this system.")
-			return
+			return errNoPackageManager
 		}
 
 		fmt.Printf("Using package manager: %s\n", pm.Name)
-		if err := ExecuteCommand(pm, "upgrade", args); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		security, _ := cmd.Flags().GetBool("security")
+		excludes, _ := cmd.Flags().GetStringSlice("exclude")
+		phased, err := phasedArgs(cmd, pm)
+		if err != nil {
+			return err
 		}
+		if len(phased) > 0 && security {
+			return fmt.Errorf("security updates are not phased, --include-phased and --exclude-phased cannot be combined with --security")
+		}
+		args = append(phased, args...)
+
+		err = upgradeExcluding(pm, excludes, args, func(args []string) error {
+			if security {
+				return upgradeSecurity(pm, args)
+			}
+			return ExecuteCommand(pm, "upgrade", args)
+		})
+		if err == nil {
+			printRestartHint()
+		}
+		return err
 	},
 }
 
+// phasedArgs returns the apt options for --include-phased or --exclude-phased
+func phasedArgs(cmd *cobra.Command, pm *PackageManager) ([]string, error) {
+	include, _ := cmd.Flags().GetBool("include-phased")
+	exclude, _ := cmd.Flags().GetBool("exclude-phased")
+	if !include && !exclude {
+		return nil, nil
+	}
+	if pm.Type != "debian" {
+		return nil, unsupportedError("phased updates are only used by apt, not by package manager '%s'", pm.Name)
+	}
+	if include {
+		return []string{"-o", "APT::Get::Always-Include-Phased-Updates=true"}, nil
+	}
+	return []string{"-o", "APT::Get::Never-Include-Phased-Updates=true"}, nil
+}
+
+// upgradeSecurity applies only security updates
+func upgradeSecurity(pm *PackageManager, args []string) error {
+	switch pm.Type {
+	case "debian":
+		if err := lookPath("unattended-upgrade"); err == nil {
+			return executeNative("unattended-upgrade", "-v")
+		}
+
+		outdated, err := listOutdated(pm)
+		if err != nil {
+			return err
+		}
+		var names []string
+		for _, p := range outdated {
+			if strings.Contains(p.Repo, "-security") {
+				names = append(names, p.Name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Println("No security updates available.")
+			return nil
+		}
+
+		fullCmd := []string{"install", "--only-upgrade"}
+		addYesFlagIfNeeded(pm, &fullCmd)
+		return executeNative(pm.Bin, append(fullCmd, names...)...)
+	case "redhat":
+		return ExecuteCommand(pm, "upgrade", append([]string{"--security"}, args...))
+	default:
+		return unsupportedError("security-only upgrades are not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// upgradeExcluding runs upgrade with the packages matching the exclude patterns
+// left out, using the native exclude option or a temporary hold
+func upgradeExcluding(pm *PackageManager, excludes, args []string, upgrade func(args []string) error) error {
+	if len(excludes) == 0 {
+		return upgrade(args)
+	}
+	if pm.Type == "redhat" {
+		for _, pattern := range excludes {
+			args = append(args, "--exclude="+pattern)
+		}
+		return upgrade(args)
+	}
+
+	names, err := matchInstalled(pm, excludes)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return upgrade(args)
+	}
+	if pm.Type == "arch" {
+		return upgrade(append(args, "--ignore", strings.Join(names, ",")))
+	}
+
+	// Packages that are already held must stay held afterwards
+	held, err := heldPackages(pm)
+	if err != nil {
+		return err
+	}
+	alreadyHeld := map[string]bool{}
+	for _, name := range held {
+		alreadyHeld[name] = true
+	}
+	var hold []string
+	for _, name := range names {
+		if !alreadyHeld[name] {
+			hold = append(hold, name)
+		}
+	}
+	if len(hold) == 0 {
+		return upgrade(args)
+	}
+
+	fmt.Printf("Holding %s during the upgrade\n", strings.Join(hold, " "))
+	if err := holdPackages(pm, hold); err != nil {
+		return err
+	}
+	upgradeErr := upgrade(args)
+	if err := unholdPackages(pm, hold); err != nil {
+		return fmt.Errorf("failed to release %s after the upgrade, run 'pkgs unhold %s': %w",
+			strings.Join(hold, " "), strings.Join(hold, " "), err)
+	}
+	return upgradeErr
+}
+
+// matchInstalled returns the installed packages matching any of the shell patterns
+func matchInstalled(pm *PackageManager, patterns []string) ([]string, error) {
+	installed, err := listInstalled(pm)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %v", pattern, err)
+		}
+		matched := false
+		for _, p := range installed {
+			if ok, _ := path.Match(pattern, p.Name); ok {
+				names = append(names, p.Name)
+				matched = true
+			}
+		}
+		if !matched {
+			fmt.Printf("No installed package matches '%s'\n", pattern)
+		}
+	}
+	return uniqueSorted(names), nil
+}
+
 func init() {
 	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().Bool("security", false, "Only apply security updates")
+	upgradeCmd.Flags().StringSlice("exclude", nil, "Skip packages matching this shell pattern (repeatable)")
+	upgradeCmd.Flags().Bool("include-phased", false, "Take phased updates right away (apt)")
+	upgradeCmd.Flags().Bool("exclude-phased", false, "Hold phased updates back until they are fully rolled out (apt)")
+	upgradeCmd.MarkFlagsMutuallyExclusive("include-phased", "exclude-phased")
 }
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// userFlag selects user mode, see IsUserMode
+var userFlag bool
+
+// IsUserMode checks if pkgs drives user-level package managers only, with
+// --user or PKGS_USER. System package managers are refused then and pkgs
+// never escalates privileges.
+func IsUserMode() bool {
+	if userFlag {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("PKGS_USER")) {
+	case "true", "yes", "1", "y":
+		return true
+	}
+	return false
+}
+
+// detectUserPackageManager identifies the first package manager that
+// installs for the current user: Homebrew, a Nix profile or Flatpak
+func detectUserPackageManager() *PackageManager {
+	if _, err := exec.LookPath("brew"); err == nil {
+		return brewPackageManager()
+	}
+
+	// Check for Nix, installing into the user's profile
+	if _, err := exec.LookPath("nix"); err == nil {
+		return &PackageManager{
+			Name: "nix",
+			Bin:  "nix",
+			Type: "nix",
+			Commands: map[string][]string{
+				"install": {"profile", "install"},
+				"remove":  {"profile", "remove"},
+				"upgrade": {"profile", "upgrade", "--all"},
+				"search":  {"search", "nixpkgs"},
+				"clean":   {"store", "gc"},
+			},
+		}
+	}
+
+	// Check for Flatpak, using the per-user installation
+	if _, err := exec.LookPath("flatpak"); err == nil {
+		return &PackageManager{
+			Name: "flatpak",
+			Bin:  "flatpak",
+			Type: "flatpak",
+			Commands: map[string][]string{
+				"install":    {"install", "--user"},
+				"reinstall":  {"install", "--user", "--reinstall"},
+				"remove":     {"uninstall", "--user"},
+				"update":     {"update", "--user", "--appstream"},
+				"upgrade":    {"update", "--user"},
+				"search":     {"search"},
+				"info":       {"info", "--user"},
+				"autoremove": {"uninstall", "--user", "--unused"},
+			},
+		}
+	}
+
+	return nil
+}
+
+// nixInstallables refers to plain package names in nixpkgs, leaving flake
+// references and store paths as they are
+func nixInstallables(args []string) []string {
+	installables := make([]string, len(args))
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") || strings.ContainsAny(arg, "#:/") {
+			installables[i] = arg
+			continue
+		}
+		installables[i] = "nixpkgs#" + arg
+	}
+	return installables
+}
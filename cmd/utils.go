@@ -1,11 +1,11 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -80,20 +80,39 @@ func extractAllRepoSections(content string) []repoSection {
 
 // runCommand executes a shell command
 func runCommand(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
+	cmd := newCommand(name, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return runChild(cmd)
+}
+
+// runCommandOutput executes a command and returns its standard output
+func runCommandOutput(name string, args ...string) (string, error) {
+	var outBuf bytes.Buffer
+	cmd := newCommand(name, args...)
+	cmd.Stdout = &outBuf
+	err := cmd.Run()
+	return outBuf.String(), err
+}
+
+// runCommandOutputs runs a command and returns its output and error output
+func runCommandOutputs(name string, args ...string) (string, string, error) {
+	var outBuf, errBuf bytes.Buffer
+	cmd := newCommand(name, args...)
+	cmd.Stdout, cmd.Stderr = &outBuf, &errBuf
+	err := cmd.Run()
+	return outBuf.String(), errBuf.String(), err
 }
 
 // fileExists checks if a file exists
 func fileExists(path string) bool {
-	_, err := os.Stat(path)
+	_, err := os.Stat(systemPath(path))
 	return err == nil
 }
 
 // readFileContent reads file content with error handling
 func readFileContent(path string) (string, error) {
+	path = systemPath(path)
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file %s: %v", path, err)
@@ -103,14 +122,33 @@ func readFileContent(path string) (string, error) {
 
 // writeFileContent writes file content with error handling
 func writeFileContent(path, content string, perm os.FileMode) error {
+	path = systemPath(path)
+	if IsDryRun() {
+		printDryRunWrite(path, content)
+		return nil
+	}
+	old, _ := os.ReadFile(path)
 	if err := os.WriteFile(path, []byte(content), perm); err != nil {
 		return fmt.Errorf("failed to write file %s: %v", path, err)
 	}
+	logFileChange("write", path, string(old), content)
 	return nil
 }
 
-// askForConfirmation prompts user for yes/no confirmation
+// askForConfirmation prompts user for yes/no confirmation. Yes mode confirms
+// without asking.
 func askForConfirmation(prompt string) bool {
+	if IsDryRun() {
+		fmt.Printf("%s (y/N): y (dry run)\n", prompt)
+		return true
+	}
+	if IsAssumeNo() {
+		fmt.Printf("%s (y/N): n (assume no)\n", prompt)
+		return false
+	}
+	if IsYesMode() {
+		return true
+	}
 	fmt.Printf("%s (y/N): ", prompt)
 	var response string
 	fmt.Scanln(&response)
@@ -119,23 +157,33 @@ func askForConfirmation(prompt string) bool {
 
 // ensureDirExists ensures a directory exists
 func ensureDirExists(path string) error {
+	path = systemPath(path)
+	if IsDryRun() {
+		return nil
+	}
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %v", path, err)
 	}
 	return nil
 }
 
-// downloadFile downloads a file from a URL to a local path
-func downloadFile(url, filepath string) error {
+// downloadFile downloads a file from a URL to a local path. Nothing is left
+// behind if the download fails.
+func downloadFile(url, filepath string) (err error) {
 	// Create the file
 	out, err := os.Create(filepath)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
+	defer func() {
+		out.Close()
+		if err != nil {
+			os.Remove(filepath)
+		}
+	}()
 
 	// Get the data
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		return err
 	}
@@ -216,27 +264,79 @@ func setRepoEnabled(content, repoID string, enable bool) string {
 }
 
 // findRepoFile searches for repository files containing a specific repo ID
-// Returns the file path of the matching repo and whether an exact match was found
-func findRepoFile(baseDir, fileExt, repoID string) (string, bool, error) {
-	repoFiles, err := filepath.Glob(filepath.Join(baseDir, "*"+fileExt))
+// Returns the file path of the matching repo and whether an exact match was found.
+// Every file is scanned; files that cannot be read are recorded in errs.
+func findRepoFile(baseDir, fileExt, repoID string, errs *fileErrors) (string, bool, error) {
+	repoFiles, err := filepath.Glob(systemPath(filepath.Join(baseDir, "*"+fileExt)))
 	if err != nil {
 		return "", false, fmt.Errorf("failed to list repository files: %v", err)
 	}
 
-	// Try exact match first (repository ID)
+	// Check for exact repository ID match
+	repoIDPattern := regexp.MustCompile(`(?m)^\[` + regexp.QuoteMeta(repoID) + `\]`)
+	match := ""
 	for _, repoFile := range repoFiles {
-		content, err := readFileContent(repoFile)
+		content, err := os.ReadFile(repoFile)
 		if err != nil {
+			errs.add(err)
 			continue
 		}
 
-		// Check for exact repository ID match
-		repoIDPattern := regexp.MustCompile(`(?m)^\[` + regexp.QuoteMeta(repoID) + `\]`)
-		if repoIDPattern.MatchString(content) {
-			return repoFile, true, nil
+		if match == "" && repoIDPattern.Match(content) {
+			match = repoFile
+		}
+	}
+
+	return match, match != "", nil
+}
+
+// setRepoOption sets key=value in a repository's section, replacing an
+// existing value. An empty value removes the option.
+func setRepoOption(content, repoID, key, value string) string {
+	lines := strings.Split(content, "\n")
+	result := make([]string, 0, len(lines)+1)
+	inRepo := false
+	done := value == ""
+
+	// appendOption adds the option after the last line of the section, before
+	// the blank lines separating it from the next one
+	appendOption := func() {
+		end := len(result)
+		for end > 0 && strings.TrimSpace(result[end-1]) == "" {
+			end--
 		}
+		result = append(result[:end], append([]string{key + "=" + value}, result[end:]...)...)
+		done = true
 	}
 
-	// No exact match found
-	return "", false, nil
+	for _, line := range lines {
+		trimmedLine := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmedLine, "[") {
+			if inRepo && !done {
+				appendOption()
+			}
+			inRepo = trimmedLine == "["+repoID+"]"
+			result = append(result, line)
+			continue
+		}
+
+		if inRepo {
+			name, _, found := strings.Cut(trimmedLine, "=")
+			if found && strings.TrimSpace(name) == key {
+				if !done {
+					result = append(result, key+"="+value)
+					done = true
+				}
+				continue
+			}
+		}
+		result = append(result, line)
+	}
+
+	if inRepo && !done {
+		appendOption()
+	}
+
+	return strings.Join(result, "\n")
 }
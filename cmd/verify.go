@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// VerifyIssue is a file of an installed package that differs from the package
+type VerifyIssue struct {
+	Package string `json:"package,omitempty"`
+	Path    string `json:"path"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+	Config  bool   `json:"config,omitempty"`
+}
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [packages...]",
+	Short: "Check installed packages for modified or missing files",
+	Long: `Verify the integrity of installed packages and report files that are
+missing, modified or have changed metadata. Without arguments all installed
+packages are checked.
+
+Every problem is shown with its status (missing, modified, metadata), the
+owning package and the native details. pkgs exits with an error if any
+problem was found.
+
+For apt-based systems (Debian/Ubuntu):
+  dpkg --verify
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  rpm -V
+
+For Alpine Linux:
+  apk audit --full
+
+For Arch Linux:
+  pacman -Qkk
+
+Homebrew has no file integrity database.`,
+	Example: `  pkgs verify
+  pkgs verify openssh-server
+  pkgs verify --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		issues, err := verifyPackages(pm, args)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := printJSON(issues); err != nil {
+				return err
+			}
+		} else if len(issues) == 0 {
+			fmt.Println("No problems found.")
+		} else {
+			defer startPager()()
+			rows := make([][]string, 0, len(issues))
+			for _, issue := range issues {
+				detail := issue.Detail
+				if issue.Config {
+					detail = strings.TrimSpace(detail + " (config file)")
+				}
+				rows = append(rows, []string{issue.Status, issue.Package, issue.Path, detail})
+			}
+			printTable([]string{"STATUS", "PACKAGE", "PATH", "DETAIL"}, rows)
+		}
+
+		if len(issues) > 0 {
+			return fmt.Errorf("%d file(s) failed verification", len(issues))
+		}
+		return nil
+	},
+}
+
+// verifyPackages runs the native integrity check for the given packages, or all if none are given
+func verifyPackages(pm *PackageManager, names []string) ([]VerifyIssue, error) {
+	issues := []VerifyIssue{}
+	switch pm.Type {
+	case "debian":
+		// dpkg and rpm exit non-zero when files differ, so only empty output is a failure
+		output, err := runCommandOutput("dpkg", append([]string{"--verify"}, names...)...)
+		if err != nil && strings.TrimSpace(output) == "" {
+			return nil, fmt.Errorf("dpkg --verify failed: %w", err)
+		}
+		issues = append(issues, parseRpmStyleVerify(output)...)
+	case "redhat":
+		args := append([]string{"-V"}, names...)
+		if len(names) == 0 {
+			args = []string{"-Va"}
+		}
+		output, err := runCommandOutput("rpm", args...)
+		if err != nil && strings.TrimSpace(output) == "" {
+			return nil, fmt.Errorf("rpm -V failed: %w", err)
+		}
+		issues = append(issues, parseRpmStyleVerify(output)...)
+	case "alpine":
+		output, err := runCommandOutput("apk", "audit", "--full")
+		if err != nil {
+			return nil, fmt.Errorf("apk audit failed: %w", err)
+		}
+		issues = append(issues, parseApkAudit(output)...)
+	case "arch":
+		output, err := runCommandOutput("pacman", append([]string{"-Qkk"}, names...)...)
+		if err != nil && strings.TrimSpace(output) == "" {
+			return nil, fmt.Errorf("pacman -Qkk failed: %w", err)
+		}
+		issues = append(issues, parsePacmanCheck(output)...)
+		return issues, nil
+	default:
+		return nil, unsupportedError("verifying packages is not supported for package manager '%s'", pm.Name)
+	}
+
+	// dpkg, rpm and apk do not name the owning package, look it up for the report
+	owners := map[string]string{}
+	for _, issue := range issues {
+		owners[issue.Path] = ""
+	}
+	for path := range owners {
+		if found, err := queryFileOwners(pm, path); err == nil && len(found) > 0 {
+			owners[path] = found[0].Package
+		}
+	}
+	for i := range issues {
+		issues[i].Package = owners[issues[i].Path]
+	}
+
+	// apk audit always checks the whole system
+	if pm.Type == "alpine" && len(names) > 0 {
+		wanted := map[string]bool{}
+		for _, name := range names {
+			wanted[name] = true
+		}
+		filtered := []VerifyIssue{}
+		for _, issue := range issues {
+			if wanted[issue.Package] {
+				filtered = append(filtered, issue)
+			}
+		}
+		issues = filtered
+	}
+	return issues, nil
+}
+
+// rpmVerifyPattern matches dpkg --verify and rpm -V lines like
+// "S.5....T.  c /etc/ssh/sshd_config" or "missing   c /etc/foo"
+var rpmVerifyPattern = regexp.MustCompile(`^(missing|[.?SM5DLUGTP]{8,9})\s+(?:([cdglr])\s+)?(/.*)$`)
+
+// parseRpmStyleVerify parses the verification output of dpkg and rpm
+func parseRpmStyleVerify(output string) []VerifyIssue {
+	var issues []VerifyIssue
+	for _, line := range strings.Split(output, "\n") {
+		match := rpmVerifyPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		issue := VerifyIssue{Path: match[3], Detail: match[1], Config: match[2] == "c"}
+		switch {
+		case match[1] == "missing":
+			issue.Status, issue.Detail = "missing", ""
+		case strings.ContainsAny(match[1], "S5L"):
+			// Size, digest or link target differ
+			issue.Status = "modified"
+		default:
+			issue.Status = "metadata"
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// parseApkAudit parses "apk audit" output ("U etc/motd"), skipping files that
+// no package owns
+func parseApkAudit(output string) []VerifyIssue {
+	statuses := map[string]string{
+		"U": "modified",
+		"A": "added",
+		"D": "missing",
+		"M": "metadata",
+		"e": "modified",
+	}
+
+	var issues []VerifyIssue
+	for _, line := range strings.Split(output, "\n") {
+		flag, path, found := strings.Cut(strings.TrimSpace(line), " ")
+		status, known := statuses[flag]
+		if !found || !known {
+			continue
+		}
+		issues = append(issues, VerifyIssue{Path: "/" + strings.TrimPrefix(path, "/"), Status: status, Detail: flag})
+	}
+	return issues
+}
+
+// pacmanCheckPattern matches "pacman -Qkk" warnings like
+// "warning: openssh: /etc/ssh/sshd_config (Modification time mismatch)"
+var pacmanCheckPattern = regexp.MustCompile(`^(?:warning: )?([^\s:]+): (/\S.*) \((.+)\)$`)
+
+// parsePacmanCheck parses "pacman -Qkk" output
+func parsePacmanCheck(output string) []VerifyIssue {
+	var issues []VerifyIssue
+	for _, line := range strings.Split(output, "\n") {
+		match := pacmanCheckPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		issue := VerifyIssue{Package: match[1], Path: match[2], Detail: match[3]}
+		switch reason := strings.ToLower(match[3]); {
+		case strings.Contains(reason, "no such file"):
+			issue.Status, issue.Detail = "missing", ""
+		case strings.Contains(reason, "size") || strings.Contains(reason, "checksum") || strings.Contains(reason, "symlink"):
+			issue.Status = "modified"
+		default:
+			issue.Status = "metadata"
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().Bool("json", false, "Output the report as JSON")
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitVersionPin splits a "name@version" or "name=version" argument. Group
+// arguments ("@group") and package files are not version pins.
+func splitVersionPin(pm *PackageManager, arg string) (name, version string, ok bool) {
+	if isLocalPackageFile(pm, arg) {
+		return arg, "", false
+	}
+	if i := strings.IndexAny(arg, "=@"); i > 0 && i < len(arg)-1 {
+		return arg[:i], arg[i+1:], true
+	}
+	return arg, "", false
+}
+
+// translateVersionPins rewrites version pins into the native syntax:
+//
+//	apt:    name=version, or name=version* for a version prefix
+//	dnf:    name-version, or name-version* for a version prefix
+//	apk:    name=version, or name~version for a version prefix
+//	pacman: the matching package file from the package cache
+//	brew:   the versioned formula name@version
+//
+// A version prefix such as "1.24" selects the newest available 1.24 release.
+func translateVersionPins(pm *PackageManager, args []string) ([]string, error) {
+	result := make([]string, 0, len(args))
+	for _, arg := range args {
+		name, version, ok := splitVersionPin(pm, arg)
+		if !ok {
+			result = append(result, arg)
+			continue
+		}
+
+		versions, err := availableVersions(pm, name)
+		if err != nil {
+			return nil, err
+		}
+		exact, prefix := matchVersion(versions, version)
+
+		switch {
+		case pm.Type == "macos":
+			// Versioned formulae exist for major releases only, so no prefix matching
+			if exact == nil {
+				return nil, fmt.Errorf("Homebrew has no versioned formula %s@%s (see 'pkgs downgrade --list %s')", name, version, name)
+			}
+			result = append(result, exact.Source)
+			continue
+		case exact == nil && !prefix:
+			return nil, fmt.Errorf("version %s of %s is not available (see 'pkgs downgrade --list %s')", version, name, name)
+		}
+
+		switch pm.Type {
+		case "debian":
+			if exact != nil {
+				result = append(result, name+"="+exact.Version)
+			} else {
+				result = append(result, name+"="+version+"*")
+			}
+		case "redhat":
+			if exact != nil {
+				result = append(result, name+"-"+stripEpoch(exact.Version))
+			} else {
+				result = append(result, name+"-"+version+"*")
+			}
+		case "alpine":
+			if exact != nil {
+				result = append(result, name+"="+exact.Version)
+			} else {
+				result = append(result, name+"~"+version)
+			}
+		case "arch":
+			// pacman only installs the repository version, older ones come from the cache
+			if exact == nil {
+				return nil, fmt.Errorf("give the full version of %s, pacman can only install exact versions from %s (see 'pkgs downgrade --list %s')", name, pacmanCacheDir, name)
+			}
+			result = append(result, exact.Source)
+		default:
+			return nil, unsupportedError("installing a specific version is not supported for package manager '%s'", pm.Name)
+		}
+	}
+	return result, nil
+}
+
+// matchVersion finds the available version equal to want, or reports whether a
+// version starting with want as a prefix is available. Epochs may be left out.
+func matchVersion(versions []packageVersion, want string) (exact *packageVersion, prefix bool) {
+	for i, v := range versions {
+		candidate := stripEpoch(v.Version)
+		if v.Version == want || candidate == want {
+			return &versions[i], true
+		}
+		if strings.HasPrefix(candidate, want) && strings.ContainsRune(".-+~_", rune(candidate[len(want)])) {
+			prefix = true
+		}
+	}
+	return nil, prefix
+}
+
+// stripEpoch removes the "epoch:" prefix of a Debian or RPM version
+func stripEpoch(version string) string {
+	if _, rest, found := strings.Cut(version, ":"); found {
+		return rest
+	}
+	return version
+}
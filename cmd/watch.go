@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// updateStateFile is where 'pkgs watch' records the result of its last check
+const updateStateFile = "/var/lib/pkgs/updates.json"
+
+// UpdateState is the result of the last update check by 'pkgs watch'
+type UpdateState struct {
+	Checked  time.Time         `json:"checked"`
+	Backend  string            `json:"backend"`
+	Pending  int               `json:"pending"`
+	Security *int              `json:"security,omitempty"`
+	Packages []OutdatedPackage `json:"packages"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Periodically check for updates and record the result",
+	Long: `Refresh the package lists at a fixed interval and record the number of
+pending updates, and of security updates where the package manager can tell,
+in ` + updateStateFile + `. Other tools can read this file instead of querying
+the package manager themselves; 'pkgs watch --status' prints it.
+
+When the number of pending updates grows, a line is printed and, with
+--notify, a desktop notification is sent (notify-send on Linux, osascript on
+macOS).
+
+Refreshing the package lists requires root privileges. Run pkgs watch as a
+service, or use --once from a cron job or systemd timer.`,
+	Example: `  pkgs watch
+  pkgs watch --interval 1h --notify
+  pkgs watch --once
+  pkgs watch --status`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if status, _ := cmd.Flags().GetBool("status"); status {
+			state, err := readUpdateState()
+			if err != nil {
+				return err
+			}
+			return printJSON(state)
+		}
+
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+
+		intervalFlag, _ := cmd.Flags().GetString("interval")
+		interval, err := parsePeriod(intervalFlag)
+		if err != nil {
+			return err
+		}
+		if interval < time.Minute {
+			return fmt.Errorf("the interval must be at least one minute")
+		}
+		once, _ := cmd.Flags().GetBool("once")
+		notify, _ := cmd.Flags().GetBool("notify")
+
+		previous := -1
+		if state, err := readUpdateState(); err == nil && state.Error == "" {
+			previous = state.Pending
+		}
+		for {
+			state, err := checkForUpdatesLocked(pm)
+			if err != nil {
+				return err
+			}
+
+			if state.Error != "" {
+				fmt.Printf("%s: update check failed: %s\n", state.Checked.Format(time.RFC3339), state.Error)
+			} else {
+				summary := fmt.Sprintf("%d update(s) pending", state.Pending)
+				if state.Security != nil {
+					summary += fmt.Sprintf(", %d security", *state.Security)
+				}
+				fmt.Printf("%s: %s\n", state.Checked.Format(time.RFC3339), summary)
+				if notify && previous >= 0 && state.Pending > previous {
+					sendNotification("Package updates available", summary)
+				}
+				previous = state.Pending
+			}
+
+			if once {
+				if state.Error != "" {
+					return fmt.Errorf("update check failed: %s", state.Error)
+				}
+				return nil
+			}
+			time.Sleep(interval)
+		}
+	},
+}
+
+// checkForUpdatesLocked checks for updates and records the result while
+// holding the instance lock, so that other pkgs commands can run between checks
+func checkForUpdatesLocked(pm *PackageManager) (UpdateState, error) {
+	if !noLockFlag {
+		if err := acquireLock(); err != nil {
+			return UpdateState{}, err
+		}
+		defer releaseLock()
+	}
+	state := checkForUpdates(pm)
+	return state, writeUpdateState(state)
+}
+
+// checkForUpdates refreshes the package lists and collects the pending updates
+func checkForUpdates(pm *PackageManager) UpdateState {
+	state := UpdateState{Checked: time.Now(), Backend: pm.Name, Packages: []OutdatedPackage{}}
+	if err := ExecuteCommand(pm, "update", nil); err != nil {
+		state.Error = fmt.Sprintf("failed to refresh the package lists: %v", err)
+		return state
+	}
+
+	outdated, err := listOutdated(pm)
+	if err != nil {
+		state.Error = err.Error()
+		return state
+	}
+	state.Pending = len(outdated)
+	state.Packages = outdated
+	if n, ok := countSecurityUpdates(pm, outdated); ok {
+		state.Security = &n
+	}
+	return state
+}
+
+// countSecurityUpdates counts the pending updates that fix security issues,
+// for package managers that publish this information
+func countSecurityUpdates(pm *PackageManager, outdated []OutdatedPackage) (int, bool) {
+	switch pm.Type {
+	case "debian":
+		n := 0
+		for _, p := range outdated {
+			if strings.Contains(p.Repo, "-security") {
+				n++
+			}
+		}
+		return n, true
+	case "redhat":
+		// Output: "FEDORA-2024-1a2b3c4d5e Moderate/Sec. curl-8.2.1-4.fc39.x86_64"
+		output, err := runCommandOutput(pm.Bin, "-q", "updateinfo", "list", "--security")
+		if err != nil {
+			return 0, false
+		}
+		packages := map[string]bool{}
+		for _, line := range strings.Split(output, "\n") {
+			if fields := strings.Fields(line); len(fields) == 3 {
+				packages[fields[2]] = true
+			}
+		}
+		return len(packages), true
+	default:
+		return 0, false
+	}
+}
+
+// writeUpdateState stores the result of an update check
+func writeUpdateState(state UpdateState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The state is pkgs' own, it is written directly rather than through
+	// writeFileContent, which skips dry runs and logs the change
+	if err := os.MkdirAll(filepath.Dir(updateStateFile), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(updateStateFile), err)
+	}
+	// Write to a temporary file first so readers never see a partial state
+	tmp := updateStateFile + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", updateStateFile, err)
+	}
+	return os.Rename(tmp, updateStateFile)
+}
+
+// readUpdateState reads the result of the last check by 'pkgs watch'
+func readUpdateState() (UpdateState, error) {
+	var state UpdateState
+	content, err := readFileContent(updateStateFile)
+	if err != nil {
+		return state, fmt.Errorf("no update state found, run 'pkgs watch' first: %v", err)
+	}
+	if err := json.Unmarshal([]byte(content), &state); err != nil {
+		return state, fmt.Errorf("failed to parse %s: %v", updateStateFile, err)
+	}
+	return state, nil
+}
+
+// sendNotification shows a desktop notification if a notifier is available
+func sendNotification(title, message string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return
+		}
+		cmd = exec.Command("notify-send", "--app-name=pkgs", title, message)
+	}
+	_ = cmd.Run()
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().String("interval", "6h", "Time between checks (e.g. 30m, 6h, 1d)")
+	watchCmd.Flags().Bool("once", false, "Check once and exit")
+	watchCmd.Flags().Bool("notify", false, "Send a desktop notification when new updates appear")
+	watchCmd.Flags().Bool("status", false, "Print the result of the last check as JSON")
+}
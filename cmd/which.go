@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -18,13 +17,18 @@ under the hood and how it maps the unified commands to the native ones.
 For example, on macOS it will show that 'brew' is being used, while on Ubuntu
 it will show 'apt', and on Fedora it will show 'dnf'.`,
 	Example: `  pkgs which
-  pkgs which -s`,
-	Run: func(cmd *cobra.Command, args []string) {
+  pkgs which -s
+  pkgs which --type`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		pm := DetectPackageManager()
 		if pm == nil {
-			fmt.Println("No supported package manager detected on this system.")
-			os.Exit(1)
-			return
+			return errNoPackageManager
+		}
+
+		// Print only the backend family, for scripts branching on the distribution
+		if showType, _ := cmd.Flags().GetBool("type"); showType {
+			fmt.Println(pm.Type)
+			return nil
 		}
 
 		// Check if simple flag is set
@@ -32,7 +36,7 @@ it will show 'apt', and on Fedora it will show 'dnf'.`,
 		if simple {
 			// Just print the package manager name and exit
 			fmt.Println(pm.Name)
-			return
+			return nil
 		}
 
 		// Otherwise, print detailed information
@@ -43,6 +47,7 @@ it will show 'apt', and on Fedora it will show 'dnf'.`,
 		for command, args := range pm.Commands {
 			fmt.Printf("  %s: %s %s\n", command, pm.Bin, args)
 		}
+		return nil
 	},
 }
 
@@ -51,4 +56,6 @@ func init() {
 
 	// Add simple flag
 	whichCmd.Flags().BoolP("simple", "s", false, "Output only the package manager name")
+	whichCmd.Flags().BoolP("type", "t", false, "Output only the package manager family (debian, redhat, alpine, arch, macos)")
+	whichCmd.MarkFlagsMutuallyExclusive("simple", "type")
 }
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// whyCmd represents the why command
+var whyCmd = &cobra.Command{
+	Use:     "why package",
+	Aliases: []string{"rdepends"},
+	Short:   "Show why a package is installed and what requires it",
+	Long: `Show whether a package was installed explicitly or as a dependency, and
+which installed packages require it. This helps to decide whether removing
+the package is safe.
+
+Use --tree to follow the reverse dependencies recursively up to the packages
+that nothing else requires.
+
+For apt-based systems (Debian/Ubuntu):
+  apt-mark showmanual, apt-cache rdepends --installed package
+
+For dnf/yum-based systems (Fedora/RHEL/CentOS):
+  dnf repoquery --installed --whatrequires package
+
+For Alpine Linux:
+  /etc/apk/world, apk info -r package
+
+For Arch Linux:
+  pacman -Qi, pactree -r package (from pacman-contrib)
+
+For Homebrew (macOS):
+  brew info --json=v2, brew uses --installed package`,
+	Example: `  pkgs why libssl3
+  pkgs why --tree zlib1g`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm := DetectPackageManager()
+		if pm == nil {
+			return errNoPackageManager
+		}
+		name := args[0]
+
+		tree, _ := cmd.Flags().GetBool("tree")
+		depth, _ := cmd.Flags().GetInt("depth")
+
+		lister, err := reverseDependencyLister(pm, tree)
+		if err != nil {
+			return err
+		}
+
+		defer startPager()()
+
+		reason, installed := installReason(pm, name)
+		if !installed {
+			return fmt.Errorf("package '%s' is not installed", name)
+		}
+		if reason == "" {
+			reason = "install reason unknown"
+		}
+		fmt.Printf("%s: %s\n", name, reason)
+
+		users, err := lister(name)
+		if err != nil {
+			return fmt.Errorf("failed to query package '%s': %v", name, err)
+		}
+		if len(users) == 0 {
+			fmt.Println("No installed package requires it.")
+			return nil
+		}
+
+		fmt.Println("Required by:")
+		if !tree {
+			for _, user := range users {
+				fmt.Printf("  %s\n", user)
+			}
+			return nil
+		}
+		return printDependencies(name, lister, tree, depth)
+	},
+}
+
+// reverseDependencyLister returns a function listing the installed packages
+// that directly require a package
+func reverseDependencyLister(pm *PackageManager, recursive bool) (func(string) ([]string, error), error) {
+	switch pm.Type {
+	case "debian":
+		graph := map[string][]string{}
+		return func(name string) ([]string, error) {
+			if users, ok := graph[name]; ok {
+				return users, nil
+			}
+			args := []string{"rdepends", "--installed", "--no-suggests", "--no-conflicts",
+				"--no-breaks", "--no-replaces", "--no-enhances"}
+			if recursive {
+				args = append(args, "--recurse")
+			}
+			output, err := runCommandOutput("apt-cache", append(args, name)...)
+			if err != nil {
+				return nil, err
+			}
+			for pkg, block := range splitAptDependsBlocks(output) {
+				graph[pkg] = parseAptRdepends(block)
+			}
+			return graph[name], nil
+		}, nil
+	case "redhat":
+		return func(name string) ([]string, error) {
+			return dnfRepoquery(pm, "--installed", "--whatrequires", name)
+		}, nil
+	case "alpine":
+		return func(name string) ([]string, error) {
+			output, err := runCommandOutput("apk", "info", "-qr", name)
+			if err != nil {
+				return nil, err
+			}
+			var users []string
+			for _, nameVersion := range strings.Fields(output) {
+				user, _ := splitApkNameVersion(nameVersion)
+				users = append(users, user)
+			}
+			return uniqueSorted(users), nil
+		}, nil
+	case "arch":
+		return func(name string) ([]string, error) {
+			return pactreeDirect(name, "-r")
+		}, nil
+	case "macos":
+		return func(name string) ([]string, error) {
+			output, err := runCommandOutput("brew", "uses", "--installed", name)
+			if err != nil {
+				return nil, err
+			}
+			return strings.Fields(output), nil
+		}, nil
+	default:
+		return nil, unsupportedError("listing reverse dependencies is not supported for package manager '%s'", pm.Name)
+	}
+}
+
+// parseAptRdepends parses the indented package lines of "apt-cache rdepends",
+// which lists a package once per relation
+func parseAptRdepends(block string) []string {
+	var names []string
+	for _, line := range strings.Split(block, "\n") {
+		names = append(names, strings.Trim(strings.TrimLeft(strings.TrimSpace(line), "|"), "<>"))
+	}
+	return uniqueSorted(names)
+}
+
+// installReason reports whether a package was installed explicitly or as a
+// dependency, and whether it is installed at all. The reason is "" if the
+// package manager does not record it.
+func installReason(pm *PackageManager, name string) (string, bool) {
+	const explicit, dependency = "explicitly installed", "installed as a dependency"
+
+	switch pm.Type {
+	case "debian":
+		if _, err := runCommandOutput("dpkg-query", "-W", name); err != nil {
+			return "", false
+		}
+		output, err := runCommandOutput("apt-mark", "showmanual", name)
+		if err != nil {
+			return "", true
+		}
+		if strings.TrimSpace(output) == name {
+			return explicit, true
+		}
+		return dependency, true
+	case "redhat":
+		if pm.Name == "yum" {
+			return "", true
+		}
+		output, err := runCommandOutput(pm.Bin, "repoquery", "-q", "--installed", "--qf", "%{reason}\n", name)
+		if err != nil || strings.TrimSpace(output) == "" {
+			return "", false
+		}
+		switch strings.TrimSpace(output) {
+		case "user":
+			return explicit, true
+		case "dependency", "weak-dependency", "weak dependency":
+			return dependency, true
+		}
+		return "", true
+	case "alpine":
+		// Explicitly installed packages are listed in the world file
+		world, err := readSystemFile(apkWorldFile)
+		if err != nil {
+			return "", true
+		}
+		for _, entry := range strings.Fields(world) {
+			if i := strings.IndexAny(entry, "=<>~@"); i > 0 {
+				entry = entry[:i]
+			}
+			if entry == name {
+				return explicit, true
+			}
+		}
+		return dependency, true
+	case "arch":
+		output, err := runCommandOutput("pacman", "-Qi", name)
+		if err != nil {
+			return "", false
+		}
+		blocks := parseFieldBlocks(output)
+		if len(blocks) == 0 {
+			return "", true
+		}
+		if strings.HasPrefix(blocks[0]["Install Reason"], "Explicitly") {
+			return explicit, true
+		}
+		return dependency, true
+	case "macos":
+		output, err := runCommandOutput("brew", "info", "--json=v2", name)
+		if err != nil {
+			return "", false
+		}
+		var info struct {
+			Formulae []struct {
+				Installed []struct {
+					InstalledOnRequest bool `json:"installed_on_request"`
+				} `json:"installed"`
+			} `json:"formulae"`
+		}
+		if err := json.Unmarshal([]byte(output), &info); err != nil || len(info.Formulae) == 0 {
+			return "", true
+		}
+		if len(info.Formulae[0].Installed) == 0 {
+			return "", false
+		}
+		if info.Formulae[0].Installed[0].InstalledOnRequest {
+			return explicit, true
+		}
+		return dependency, true
+	}
+	return "", true
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+
+	whyCmd.Flags().Bool("tree", false, "Show reverse dependencies recursively as a tree")
+	whyCmd.Flags().Int("depth", 0, "Maximum tree depth (0 for unlimited)")
+}
@@ -3,12 +3,16 @@ module pkgs
 go 1.24.1
 
 require (
+	github.com/ProtonMail/go-crypto v1.4.1
 	github.com/spf13/cobra v1.9.1
-	golang.org/x/term v0.30.0
+	golang.org/x/term v0.34.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/cloudflare/circl v1.6.2 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
-	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/crypto v0.41.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
 )
@@ -2,10 +2,17 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"pkgs/cmd"
 	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
 )
 
 // isLinux checks if the current OS is Linux
@@ -17,12 +24,199 @@ func isRoot() bool {
 	return os.Geteuid() == 0
 }
 
-// rerunWithSudo re-executes the current command with sudo
-func rerunWithSudo() error {
-	// Check if sudo is available
-	_, err := exec.LookPath("sudo")
+// escalationTools are the tools pkgs can re-run itself as root with, in
+// order of preference. run0 comes with systemd 256 and later, where some
+// systems no longer install sudo.
+var escalationTools = []string{"sudo", "run0"}
+
+// escalationMethods are the tools that can be chosen with --escalate or
+// PKGS_ESCALATE. pkexec asks through the polkit agent of the desktop and is
+// never picked automatically.
+var escalationMethods = []string{"sudo", "run0", "pkexec"}
+
+// flagValue finds a global flag in the command line, which is read here
+// before cobra parses it. Boolean flags are returned as "true".
+func flagValue(name string, boolean bool) (string, bool) {
+	for i, arg := range os.Args[1:] {
+		if arg == "--" {
+			break
+		}
+		if value, found := strings.CutPrefix(arg, "--"+name+"="); found {
+			return value, true
+		}
+		if arg == "--"+name {
+			if boolean {
+				return "true", true
+			}
+			if i+2 < len(os.Args) {
+				return os.Args[i+2], true
+			}
+		}
+	}
+	return "", false
+}
+
+// isTruthy reports whether a flag or environment value means yes
+func isTruthy(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "1", "y":
+		return true
+	}
+	return false
+}
+
+// escalationDisabled reports whether --no-sudo or PKGS_NO_SUDO forbid
+// escalating privileges, e.g. in CI runners where sudo raises alerts
+func escalationDisabled() bool {
+	if value, found := flagValue("no-sudo", true); found {
+		return isTruthy(value)
+	}
+	return isTruthy(os.Getenv("PKGS_NO_SUDO"))
+}
+
+// escalationMethod returns the method given with --escalate or with
+// PKGS_ESCALATE
+func escalationMethod() (method, source string) {
+	if value, found := flagValue("escalate", false); found {
+		return strings.ToLower(value), "--escalate"
+	}
+	return strings.ToLower(os.Getenv("PKGS_ESCALATE")), "PKGS_ESCALATE"
+}
+
+// escalationTool picks the tool to gain root privileges with: the one chosen
+// with --escalate or PKGS_ESCALATE, or the first available one
+func escalationTool() (string, error) {
+	method, source := escalationMethod()
+	if method != "" && method != "auto" {
+		if !slices.Contains(escalationMethods, method) {
+			return "", fmt.Errorf("unknown escalation method '%s' in %s (expected auto, %s)", method, source, strings.Join(escalationMethods, ", "))
+		}
+		if _, err := exec.LookPath(method); err != nil {
+			return "", fmt.Errorf("this command requires root privileges, but %s is not available: %v", method, err)
+		}
+		return method, nil
+	}
+
+	for _, tool := range escalationTools {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool, nil
+		}
+	}
+	return "", fmt.Errorf("this command requires root privileges, but neither %s is available", strings.Join(escalationTools, " nor "))
+}
+
+// nonInteractive reports whether pkgs runs without anyone to type a
+// password: with -y or PKGS_YES, or without a terminal as under cron or CI
+func nonInteractive() bool {
+	if value, found := flagValue("yes", true); found && isTruthy(value) {
+		return true
+	}
+	for _, arg := range os.Args[1:] {
+		if arg == "--" {
+			break
+		}
+		if arg == "-y" {
+			return true
+		}
+	}
+	return cmd.IsYesMode() || !term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// preservedEnvVars are kept when escalating, as sudo, run0 and pkexec reset
+// the environment: downloads behind a proxy would otherwise only fail once
+// pkgs escalates
+var preservedEnvVars = []string{
+	"http_proxy", "https_proxy", "ftp_proxy", "all_proxy", "no_proxy",
+	"HTTP_PROXY", "HTTPS_PROXY", "FTP_PROXY", "ALL_PROXY", "NO_PROXY",
+	"LANG", "LANGUAGE", "LC_ALL", "LC_MESSAGES",
+}
+
+// preservedEnv returns the names of the set variables to keep: the proxy and
+// locale ones, those listed in PKGS_PRESERVE_ENV and pkgs' own PKGS_ ones
+func preservedEnv() []string {
+	names := append([]string{}, preservedEnvVars...)
+	names = append(names, strings.FieldsFunc(os.Getenv("PKGS_PRESERVE_ENV"), func(r rune) bool { return r == ',' || r == ' ' })...)
+	for _, entry := range os.Environ() {
+		if name, _, _ := strings.Cut(entry, "="); strings.HasPrefix(name, "PKGS_") {
+			names = append(names, name)
+		}
+	}
+
+	var set []string
+	for _, name := range names {
+		if _, found := os.LookupEnv(name); found && !slices.Contains(set, name) {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
+// escalationArgs returns the arguments running exe with args through tool.
+// When nobody can answer, sudo and run0 fail instead of asking for a
+// password.
+func escalationArgs(tool, exe string, args []string, nonInteractive bool) []string {
+	var toolArgs []string
+	switch {
+	case nonInteractive && tool == "sudo":
+		toolArgs = append(toolArgs, "-n")
+	case nonInteractive && tool == "run0":
+		toolArgs = append(toolArgs, "--no-ask-password")
+	}
+
+	env := preservedEnv()
+	switch {
+	case tool == "sudo" && len(env) > 0:
+		toolArgs = append(toolArgs, "--preserve-env="+strings.Join(env, ","))
+	case tool == "run0":
+		for _, name := range env {
+			toolArgs = append(toolArgs, "--setenv="+name)
+		}
+	}
+
+	wd, wdErr := os.Getwd()
+	switch {
+	case tool == "pkexec":
+		// pkexec starts in the home directory of root and passes no
+		// variables, env sets them up
+		toolArgs = append(toolArgs, "env")
+		if wdErr == nil {
+			toolArgs = append(toolArgs, "--chdir="+wd)
+		}
+		for _, name := range env {
+			toolArgs = append(toolArgs, name+"="+os.Getenv(name))
+		}
+	case tool == "run0" && wdErr == nil:
+		// run0 starts a service, keep the working directory for relative paths
+		toolArgs = append(toolArgs, "--chdir="+wd)
+	}
+	toolArgs = append(toolArgs, exe)
+	return append(toolArgs, args...)
+}
+
+// passwordRequiredMessages are printed by sudo -n and run0
+// --no-ask-password when they would have to ask for a password
+var passwordRequiredMessages = []string{"a password is required", "Interactive authentication required"}
+
+// headBuffer keeps the first bytes written to it, enough to find the error
+// message of the escalation tool without holding all output
+type headBuffer struct {
+	data  []byte
+	limit int
+}
+
+func (h *headBuffer) Write(p []byte) (int, error) {
+	if room := h.limit - len(h.data); room > 0 {
+		h.data = append(h.data, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
+
+// rerunElevated re-executes the current command as root with sudo, run0 or
+// pkexec
+func rerunElevated() error {
+	tool, err := escalationTool()
 	if err != nil {
-		return fmt.Errorf("this command requires root privileges, but sudo is not available: %v", err)
+		return err
 	}
 
 	// Get the current executable path
@@ -31,19 +225,41 @@ func rerunWithSudo() error {
 		return fmt.Errorf("failed to get executable path: %v", err)
 	}
 
-	// Prepare the command to run with sudo
-	args := []string{exe}
-	args = append(args, os.Args[1:]...)
-
-	// Create the sudo command
-	sudo := exec.Command("sudo", args...)
-	sudo.Stdout = os.Stdout
-	sudo.Stderr = os.Stderr
-	sudo.Stdin = os.Stdin
+	// Create the elevated command
+	batch := nonInteractive()
+	elevated := exec.Command(tool, escalationArgs(tool, exe, os.Args[1:], batch)...)
+	elevated.Stdout = os.Stdout
+	elevated.Stderr = os.Stderr
+	elevated.Stdin = os.Stdin
+	head := &headBuffer{limit: 4096}
+	if batch {
+		elevated.Stderr = io.MultiWriter(os.Stderr, head)
+	}
 
-	// Run the command and exit with its exit code
-	if err := sudo.Run(); err != nil {
+	// Run the command and exit with its exit code. Signals are passed on to
+	// the elevated pkgs, which forwards them to the package manager, and
+	// this process waits for it instead of ending first.
+	if err := elevated.Start(); err != nil {
+		return err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range signals {
+			// Ctrl-C already reached the whole foreground group
+			if sig != os.Interrupt || !term.IsTerminal(int(os.Stdin.Fd())) {
+				elevated.Process.Signal(sig)
+			}
+		}
+	}()
+	if err := elevated.Wait(); err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
+			for _, message := range passwordRequiredMessages {
+				if batch && exitErr.ExitCode() == 1 && strings.Contains(string(head.data), message) {
+					fmt.Fprintf(os.Stderr, "Error: this command requires root privileges, but %s needs a password and pkgs runs non-interactively; allow %s to run %s without a password or run pkgs as root\n", tool, tool, exe)
+					os.Exit(cmd.ExitPasswordRequired)
+				}
+			}
 			os.Exit(exitErr.ExitCode())
 		}
 		return err
@@ -55,18 +271,23 @@ func rerunWithSudo() error {
 }
 
 func main() {
-	// Check if we need sudo on Linux
-	if isLinux() && !isRoot() {
-		if err := rerunWithSudo(); err != nil {
+	// Escalate on Linux for commands that change the system, read-only
+	// commands run as the invoking user
+	if isLinux() && !isRoot() && cmd.NeedsRoot(os.Args[1:]) {
+		if escalationDisabled() {
+			fmt.Fprintln(os.Stderr, "Error: this command requires root privileges, and privilege escalation is disabled by --no-sudo or PKGS_NO_SUDO; run pkgs as root")
+			os.Exit(cmd.ExitPrivilege)
+		}
+		if err := rerunElevated(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			os.Exit(cmd.ExitPrivilege)
 		}
 		return
 	}
 
-	// Execute the command normally
+	// Execute the command normally, exiting with the wrapped command's exit code on failure
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }